@@ -0,0 +1,42 @@
+package api
+
+// An overview of all generators in a source directory, see Api.CatalogGenerators
+type GeneratorCatalog struct {
+	// true if the source directory could be read and every generator spec could be obtained
+	Success bool
+
+	// one entry per generator, in generator name order, including those whose spec could not be obtained
+	Generators []CatalogEntry
+
+	// errors that prevented listing the generators at all, such as a source directory that does not exist
+	Errors []error
+}
+
+// Describes one generator of a GeneratorCatalog
+type CatalogEntry struct {
+	Name string
+
+	// the generator spec, including its variables, or nil if it could not be obtained
+	Spec *GeneratorSpec
+
+	// one entry per template, in the order of the generator spec
+	Outputs []CatalogOutput
+
+	// the errors obtaining the generator spec, empty if it was read successfully
+	Errors []error
+}
+
+// Describes what a template of a generator produces, without rendering it
+type CatalogOutput struct {
+	// the template source path, or a description for inline or bundled templates
+	Source string
+
+	// the target path as given in the generator spec, that is, not yet evaluated as a template
+	Target string
+
+	// true if the template produces several files, because it iterates over with_items or with_entries
+	Iterates bool
+
+	// true if the template has a condition, so it may produce no file at all
+	Conditional bool
+}
@@ -0,0 +1,25 @@
+package api
+
+// Information about the results of a check run, see Api.CheckUpToDate
+type CheckResponse struct {
+	// true only if rendering succeeded and all rendered files are identical to the files on disk
+	Success bool
+
+	// the files that rendering would create or change, in the same order Render would write them
+	StaleFiles []StaleFile
+
+	// errors that prevented checking, including errors rendering individual files, prefixed with the file name
+	Errors []error
+}
+
+// A file that is out of date
+type StaleFile struct {
+	RelativeFilePath string
+
+	// true if the file does not exist yet
+	Missing bool
+
+	// a line by line diff from the file on disk to the rendered file, with lines prefixed by "-", "+" or " ".
+	// Empty if the file is missing.
+	Diff string
+}
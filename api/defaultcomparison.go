@@ -0,0 +1,43 @@
+package api
+
+// How the value of a parameter in a render spec relates to the current default, see ComparedParameter
+const (
+	// the value is the same as the current default of the variable
+	ParameterStatusMatchesDefault = "matches-default"
+
+	// the value differs from the current default, or the variable has no default, so it was set intentionally
+	ParameterStatusCustomized = "customized"
+
+	// the value does not pass validation anymore, or the generator no longer has the variable
+	ParameterStatusNoLongerValid = "no-longer-valid"
+)
+
+// Compares the parameters of a render spec to the current defaults of its generator, see Api.CompareRenderSpecToDefaults
+type DefaultComparisonResponse struct {
+	// true if the comparison could be made. Invalid parameters do not affect Success.
+	Success bool
+
+	// one entry per parameter given in the render spec, keyed by parameter name (after resolving aliases)
+	Parameters map[string]ComparedParameter
+
+	// errors that prevented the comparison, such as a missing render spec or generator spec
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+}
+
+// The value of a single parameter in a render spec, compared to the current default of its variable
+type ComparedParameter struct {
+	// the value given in the render spec
+	Value interface{}
+
+	// the default the variable would get now, given the other values in the render spec. Nil if it has none.
+	Default interface{}
+
+	// one of the ParameterStatus* constants
+	Status string
+
+	// why the value is no longer valid, empty for other statuses
+	Reason string
+}
@@ -0,0 +1,43 @@
+package api
+
+// Where the value of a parameter came from, see ResolvedParameter
+const (
+	// the value was given explicitly, e.g. in the parameters passed in, or under a deprecated alias
+	ParameterSourceExplicit = "explicit"
+
+	// the default of the variable, used as is because it is not a string, or a string that contains no template actions
+	ParameterSourceDefaultLiteral = "spec-default-literal"
+
+	// the result of evaluating the default of the variable as a template
+	ParameterSourceDefaultTemplate = "spec-default-template-result"
+
+	// the value of the first matching entry in the default_when list of the variable
+	ParameterSourceComputed = "computed"
+
+	// neither given nor defaulted, so the variable is required and the value is missing
+	ParameterSourceNil = "nil"
+)
+
+// Explains how the value of each variable was obtained, see Api.ResolveDefaults
+type DefaultResolutionResponse struct {
+	// true if all defaults could be resolved
+	Success bool
+
+	// the resolved parameters, keyed by variable name. Contains an entry for every variable of the generator.
+	Parameters map[string]ResolvedParameter
+
+	// errors that prevented resolution, such as a missing generator spec or an invalid default
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+}
+
+// The value of a single variable, and where it came from
+type ResolvedParameter struct {
+	// the value, as it would be written to a render spec. Nil if no value is available.
+	Value interface{}
+
+	// one of the ParameterSource* constants
+	Source string
+}
@@ -0,0 +1,19 @@
+package api
+
+// DiscoveryOptions control which files in a generator directory are considered generator specs.
+//
+// A generator spec file is named <Prefix><generatorName><Extension>. Empty fields use the defaults,
+// which are DefaultGeneratorSpecPrefix and DefaultGeneratorSpecExtension.
+type DiscoveryOptions struct {
+	Prefix string
+
+	// Set NoPrefix if generator spec files have no prefix, that is, they are named <generatorName><Extension>.
+	// An empty Prefix alone means the default prefix.
+	NoPrefix bool
+
+	Extension string
+}
+
+const DefaultGeneratorSpecPrefix = "generator-"
+
+const DefaultGeneratorSpecExtension = ".yaml"
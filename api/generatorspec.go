@@ -1,41 +1,309 @@
 package api
 
+import "fmt"
+
+// The highest generator spec version this version of the library supports, see GeneratorSpec.SpecVersion.
+//
+// It is increased whenever generator specs gain features that older versions of the library would not handle correctly.
+const CurrentGeneratorSpecVersion = 1
+
 // Specifies what templates belong to a generator and what variables it needs to run.
 //
 // Will be read from a generator-*.yaml file in the root directory of the generator.
 //
 // The values of the variables as well as what generator to use come from a RenderSpec instead.
 type GeneratorSpec struct {
+	// Optional minimum generator spec version this generator requires. If it is newer than CurrentGeneratorSpecVersion,
+	// obtaining the spec fails with a request to upgrade, instead of older versions of this library failing on,
+	// or worse, silently ignoring features they do not know. Specs that do not set it work with any version.
+	SpecVersion int `yaml:"spec_version,omitempty"`
+
 	// The list of templates to render (if their condition evaluates to true)
 	Templates []TemplateSpec `yaml:"templates"`
 
 	// The list of available variables
 	Variables map[string]VariableSpec `yaml:"variables"`
+
+	// Optional list of shared variables files, relative to the generator directory, e.g. "variables-common.yaml",
+	// so several generators can share variable definitions such as the organization name or license.
+	// Each file has a top level key "variables" just like a generator spec. When the generator spec is obtained,
+	// their variables are merged into Variables. On conflicts, the generator's own variables take precedence,
+	// then files listed later take precedence over files listed earlier.
+	IncludeVariables []string `yaml:"include_variables"`
+
+	// Optional list of gitignore-like glob patterns for template source paths that must never be rendered or copied,
+	// e.g. "node_modules/", "*.bak" or "/build/". Patterns without a slash match any path segment, patterns
+	// containing a slash are matched against the path relative to the generator directory, and a trailing
+	// slash restricts the pattern to directories. A template with several sources is excluded if any of them matches.
+	Excludes []string `yaml:"excludes"`
+
+	// Optional conditions for whole variable groups, keyed by group name, e.g. "TLS": "{{ .enableTls }}".
+	// Conditions are evaluated against the variables that are not in a conditional group. If a condition
+	// evaluates to one of 'false', '0', 'no', 'skip', the variables of that group are neither required nor validated,
+	// and are set to nil.
+	GroupConditions map[string]string `yaml:"group_conditions"`
+
+	// Optional named variants of the generator, keyed by profile name, e.g. "minimal" and "full".
+	// A render request selects one by setting Request.Profile.
+	Profiles map[string]ProfileSpec `yaml:"profiles"`
+
+	// Optional paths relative to the target directory that must exist before rendering, for generators that
+	// augment an existing project, e.g. "go.mod". Each path is evaluated as a template. If any are missing,
+	// rendering fails before anything is written, listing all missing paths.
+	RequiresTargetFiles []string `yaml:"requires_target_files"`
+
+	// Optional named validation patterns, e.g. "dnsName": "^[a-z0-9-]+$", so variables that share a pattern can
+	// reference it by name in VariableSpec.ValidationPatternRef instead of repeating it.
+	PatternDefs map[string]string `yaml:"pattern_defs"`
+
+	// Optional marker paths relative to the target directory, keyed by name, e.g. "hasGoMod": "go.mod", so one
+	// generator can adapt to the project it renders into. Before rendering, each path is checked, and templates,
+	// conditions and default values see whether something exists there as {{ .detected.hasGoMod }}.
+	// The variable name "detected" is then reserved. The paths must not point outside the target directory.
+	Detect map[string]string `yaml:"detect"`
+}
+
+// Specifies a variant of a generator, see GeneratorSpec.Profiles
+type ProfileSpec struct {
+	Description string `yaml:"description"`
+
+	// Optional default values that replace the defaults of the named variables while this profile is selected.
+	// Values given in the render spec still take precedence.
+	Defaults map[string]interface{} `yaml:"defaults"`
 }
 
 // Specifies a template to process, or a list to iterate over, if WithItems is nonempty (setting {{ item }} each run)
 //
+// Alternatively, WithEntries specifies a map to iterate over in sorted key order, setting {{ itemKey }} and
+// {{ itemValue }} each run.
+//
 // Every field is evaluated as a template itself, so you can use variables in all fields.
 //
 // If Condition is set and evaluates to one of 'false', '0', 'no', the render run is skipped
+//
+// If JustCopy is set, the template file is copied byte for byte and never parsed, while the target path and
+// condition are still evaluated, e.g. to copy a binary asset such as an image to a name computed from variables.
+//
+// The template is read from RelativeSourcePath, unless it is given inline as InlineContent, or concatenated from
+// the fragments in RelativeSourcePaths. Exactly one of these must be set.
 type TemplateSpec struct {
-	RelativeSourcePath string        `yaml:"source"`
-	RelativeTargetPath string        `yaml:"target"`
-	Condition          string        `yaml:"condition"`
-	WithItems          []interface{} `yaml:"with_items"`
-	JustCopy           bool          `yaml:"just_copy"`
+	RelativeSourcePath string                 `yaml:"source"`
+	RelativeTargetPath string                 `yaml:"target"`
+	Condition          string                 `yaml:"condition"`
+	WithItems          []interface{}          `yaml:"with_items"`
+	WithEntries        map[string]interface{} `yaml:"with_entries"`
+	JustCopy           bool                   `yaml:"just_copy"`
+
+	// If set, the template file may start with a yaml front matter block between two lines of "---",
+	// which can set target, condition and just_copy, overriding the values given here. The front matter
+	// is removed before rendering.
+	FrontMatter bool `yaml:"front_matter"`
+
+	// If set, rendering output that is empty or only whitespace is reported as an error instead of writing an empty file.
+	FailOnEmpty bool `yaml:"fail_on_empty"`
+
+	// If set, names a path relative to the target directory (evaluated as a template). If something exists at that path,
+	// e.g. a marker file like ".initialized", the template is skipped, resulting in a FileResult with Skipped set.
+	SkipIfTargetExists string `yaml:"skip_if_target_exists"`
+
+	// If set, the output file is written with a leading UTF-8 byte order mark, for consumers that require one.
+	// A byte order mark at the start of a template file is always ignored when parsing.
+	WriteBOM bool `yaml:"write_bom"`
+
+	// If set to true, the output file ends in exactly one newline, trailing newlines are added or removed as needed.
+	// If set to false, the output is written as rendered. If not set, Request.EnsureFinalNewline decides.
+	// Never applies to files with just_copy set, or to empty output.
+	EnsureFinalNewline *bool `yaml:"ensure_final_newline"`
+
+	// Optional tags such as "ci" or "docs". A render request can set RenderTags to only render templates
+	// that have at least one of the requested tags.
+	Tags []string `yaml:"tags"`
+
+	// The template itself, for tiny outputs such as marker files or one-line configs that do not warrant
+	// a separate file. Exactly one of this, RelativeSourcePath and RelativeSourcePaths must be set.
+	InlineContent string `yaml:"content"`
+
+	// Alternatively, a list of template fragments that are concatenated in the given order into a single template,
+	// e.g. to bundle several migrations into one file. The fragments are parsed and rendered together, with the same
+	// parameters, so a template defined in one fragment can be used in the others.
+	RelativeSourcePaths []string `yaml:"sources"`
+
+	// Optional text inserted between the fragments listed in RelativeSourcePaths, e.g. "\n---\n". It is part of
+	// the template, so it is evaluated as a template too.
+	Separator string `yaml:"separator"`
+
+	// Optional format the rendered output must parse as, one of OutputFormatJSON, OutputFormatYAML or OutputFormatGo.
+	// If the output does not parse, the file is not written, and its FileResult reports the error.
+	// This catches template bugs that produce malformed output. No validation if left empty.
+	ValidateAs string `yaml:"validate_as"`
+
+	// Optional encoding of the output file, for legacy consumers that require one other than UTF-8, e.g. "ISO-8859-1".
+	// Rendering always happens in UTF-8, and the output is transcoded before writing. Characters that cannot be
+	// represented in the encoding are reported as an error. Any encoding registered with IANA is supported by its
+	// name or one of its aliases, in any case, e.g. "UTF-8" (the default), "ISO-8859-1" (alias "latin1"),
+	// "US-ASCII", "windows-1252" or "Shift_JIS".
+	Encoding string `yaml:"encoding"`
+
+	// Optional file permissions of the output file in octal notation, e.g. "0755" for a script. The mode is also
+	// applied to a file that already exists, unless Request.PreserveExistingMode is set. If not set, new files
+	// are created with mode 0644 (before umask), and existing files keep their mode.
+	Mode string `yaml:"mode"`
+
+	// Optional names of post processors the rendered output is passed through, in this order, before it is
+	// validated and written, e.g. ["gofmt"]. See PostProcessor for the built-in ones and how to add your own.
+	PostProcess []string `yaml:"post_process"`
+
+	// Optional message that marks the template as deprecated, e.g. "use config.yaml instead, this file will be
+	// removed in the next major version". The template is still rendered, but every file it writes adds a warning
+	// with the message and the target path to Response.Warnings.
+	Deprecated string `yaml:"deprecated"`
+
+	// Optional action, TemplateActionRender (the default) or TemplateActionDelete. A template with the delete action
+	// has no source, sources or content. If its condition is true, the file at its target path is deleted instead
+	// of written, e.g. to remove a legacy config file when migrating. The target path must be inside the target directory.
+	Action string `yaml:"action"`
 }
 
+// Actions for TemplateSpec.Action
+const (
+	TemplateActionRender = "render"
+	TemplateActionDelete = "delete"
+)
+
+// Modes for VariableSpec.ValidationMode
+const (
+	ValidationModeAll = "all"
+	ValidationModeAny = "any"
+)
+
+// Output formats for TemplateSpec.ValidateAs
+const (
+	OutputFormatJSON = "json"
+	OutputFormatYAML = "yaml"
+	OutputFormatGo   = "go"
+)
+
 // Specifies a variable that this generator uses, so it is made available in the templates.
 //
 // Actual values for an invocation of the generator are set in a RenderSpec, not the GeneratorSpec.
 type VariableSpec struct {
-	// Human readable description for the variable.
+	// Short human readable label for the variable, e.g. to be used as a field title or prompt.
+	// Optional, UIs should fall back to the variable name if left empty.
+	Label string `yaml:"label"`
+
+	// Human readable description for the variable. UIs can show this as help text.
 	Description string `yaml:"description"`
 
+	// Optional name of a group (section) this variable belongs to, e.g. "Database" or "Networking".
+	// Used to organize the variables in user interfaces and in written render specs.
+	Group string `yaml:"group"`
+
 	// Regex validation pattern that the string representation (%v) of the value must match. No validation if left empty.
 	ValidationPattern string `yaml:"pattern"`
 
-	// Default value. If missing, the variable is considered required. Note that variables can have structured content.
+	// Optional name of a pattern in GeneratorSpec.PatternDefs to use as ValidationPattern. When the generator spec
+	// is obtained, ValidationPattern is set to the referenced pattern. Referencing a name that is not defined,
+	// or setting both fields, is an error in the generator spec.
+	ValidationPatternRef string `yaml:"pattern_ref"`
+
+	// Optional further regex validation patterns, for rules a single pattern expresses badly, e.g. that a value must
+	// contain a digit and a letter and be at least 8 characters long. ValidationPattern, if set, counts as one of them.
+	ValidationPatterns []string `yaml:"patterns"`
+
+	// How the validation patterns combine, ValidationModeAll (the default) if the value must match all of them,
+	// or ValidationModeAny if it must match at least one.
+	ValidationMode string `yaml:"pattern_mode"`
+
+	// Optional type of the value. Supported are VariableTypeBytes, for binary content, VariableTypeList and
+	// VariableTypeMap, for structured values, and VariableTypeDuration. Without a type, any value is accepted.
+	Type string `yaml:"type"`
+
+	// For duration values, optional inclusive bounds given as durations, e.g. "1s" and "10m".
+	MinDuration string `yaml:"min_duration"`
+	MaxDuration string `yaml:"max_duration"`
+
+	// If set, the value is a secret such as a password, and is never included in error messages.
+	Sensitive bool `yaml:"sensitive"`
+
+	// For list values, a regex validation pattern that the string representation (%v) of each element must match.
+	ItemPattern string `yaml:"item_pattern"`
+
+	// For map values, keys that must be present (and not empty). For list values, each element must be a map with
+	// these keys. Use dotted paths like "tls.cert" to require keys in nested maps.
+	RequiredKeys []string `yaml:"required_keys"`
+
+	// Default value. If missing or null ("default:" or "default: ~"), the variable is considered required.
+	// Note that variables can have structured content.
 	DefaultValue interface{} `yaml:"default"`
+
+	// Set DefaultEmpty to make the empty string the default, so an author can make a variable optional without
+	// giving it a value. It cannot be combined with a default that is not empty.
+	DefaultEmpty bool `yaml:"default_empty"`
+
+	// Optional list of defaults that depend on other parameters, e.g. a port that defaults to 443 if TLS is enabled.
+	// The value of the first entry whose condition is true is used as the default, if none matches, DefaultValue is.
+	DefaultWhen []ConditionalDefault `yaml:"default_when"`
+
+	// Optional template applied to the value after validation, with the value available as .value, so all templates
+	// see a canonical form. For example '{{ .value | lower | replace " " "-" }}'. The result is always a string.
+	Transform string `yaml:"transform"`
+
+	// Optional list of old names for this variable, so it can be renamed without breaking existing render specs.
+	// If a render spec does not set the variable, but sets one of its aliases, the alias value is used instead.
+	Aliases []string `yaml:"aliases"`
+
+	// Optional value written into render specs for this variable if it has no default, e.g. "CHANGEME", so users
+	// can see which values they must edit. Takes precedence over Request.MissingValuePlaceholder. Rendering fails
+	// if the value of the variable is still its placeholder.
+	Placeholder string `yaml:"placeholder"`
+}
+
+// A default value that only applies if its condition is true, see VariableSpec.DefaultWhen
+type ConditionalDefault struct {
+	// Template evaluated against the other parameters, with their explicit or default values. If it evaluates to
+	// one of 'false', '0', 'no', 'skip', the entry does not apply.
+	Condition string `yaml:"when"`
+
+	// The default value to use if the condition is true. It is used as is, so it can be structured.
+	Value interface{} `yaml:"value"`
+}
+
+// VariableTypeBytes declares a variable whose value is given as base64. It is decoded before rendering, and templates
+// see a string holding the raw bytes, which can be written directly or re-encoded with b64enc.
+// Patterns are not checked for variables of this type.
+const VariableTypeBytes = "bytes"
+
+// VariableTypeList declares a variable whose value must be a list. If the request sets CoerceStrings, a string value
+// is parsed as json or yaml, so lists can be given on the command line, e.g. '["a","b"]'.
+const VariableTypeList = "list"
+
+// VariableTypeMap declares a variable whose value must be a map. If the request sets CoerceStrings, a string value
+// is parsed as json or yaml, e.g. '{"region":"eu"}'.
+const VariableTypeMap = "map"
+
+// VariableTypeDuration declares a variable whose value is a duration such as "30s" or "1h30m", as understood by
+// time.ParseDuration. Templates see a time.Duration, so they can use e.g. {{ .timeout.Seconds }}, while
+// {{ .timeout }} prints it in canonical form, e.g. "1h30m0s".
+const VariableTypeDuration = "duration"
+
+// HasDefault is true if the variable has a default value, that is, if it is not required.
+func (v *VariableSpec) HasDefault() bool {
+	return v.DefaultValue != nil
+}
+
+// UnmarshalYAML applies DefaultEmpty, so the rest of the library only needs to look at DefaultValue.
+func (v *VariableSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// a separate type without the UnmarshalYAML method, or we would recurse forever
+	type plainVariableSpec VariableSpec
+	if err := unmarshal((*plainVariableSpec)(v)); err != nil {
+		return err
+	}
+
+	if v.DefaultEmpty {
+		if v.DefaultValue != nil && v.DefaultValue != "" {
+			return fmt.Errorf("default_empty cannot be combined with the default %v", v.DefaultValue)
+		}
+		v.DefaultValue = ""
+	}
+	return nil
 }
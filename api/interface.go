@@ -1,6 +1,9 @@
 package api
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // Functionality that this library exposes.
 type Api interface {
@@ -10,6 +13,66 @@ type Api interface {
 	// Obtain a specific generator spec, read from "generator-<generatorName>.yaml" in sourceBaseDir
 	ObtainGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) (*GeneratorSpec, error)
 
+	// Obtain an overview of all generators in sourceBaseDir, with their specs (including variables) and which
+	// template produces which target path, e.g. for a "what can I generate" view.
+	//
+	// A generator whose spec cannot be obtained is still listed, with its errors, and does not prevent
+	// the others from being listed. Success is only true if all generator specs could be obtained.
+	CatalogGenerators(ctx context.Context, sourceBaseDir string) *GeneratorCatalog
+
+	// Like FindGeneratorNames, but looking for <options.Prefix>*<options.Extension> files instead.
+	//
+	// This allows generator specs to follow a different naming convention, or to coexist with other
+	// yaml files in the same directory.
+	FindGeneratorNamesWithOptions(ctx context.Context, sourceBaseDir string, options DiscoveryOptions) ([]string, error)
+
+	// Like ObtainGeneratorSpec, but reading "<options.Prefix><generatorName><options.Extension>" instead.
+	ObtainGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options DiscoveryOptions) (*GeneratorSpec, error)
+
+	// Read a specific generator spec file, "generator-<generatorName>.yaml" in sourceBaseDir, without parsing it.
+	//
+	// Returns the raw file contents, exactly as authored (including comments and formatting), and the path of the file.
+	ReadGeneratorSpecRaw(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, string, error)
+
+	// Check a specific generator spec for errors a generator author may have made.
+	//
+	// This reads the spec just like ObtainGeneratorSpec, then checks that each variable's default value
+	// (after evaluating it as a template) matches the variable's own validation pattern.
+	//
+	// It also parses every template, target path and condition with the functions available during rendering,
+	// including those added with WithExtraFuncs to ctx, so undefined functions and syntax errors are found
+	// for the whole generator at once. Templates are not executed.
+	//
+	// Returns all problems found, or an empty list if the generator spec is fine.
+	ValidateGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) []error
+
+	// Like ValidateGeneratorSpec, but also reports likely mistakes as warnings, or as errors if options.Strict is set.
+	//
+	// Currently, this warns about templates with with_items or with_entries whose target path does not refer to
+	// .item, .itemKey or .itemValue, because then all iterations write the same file.
+	ValidateGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options SpecValidationOptions) *SpecValidationResponse
+
+	// Compare two versions of a generator spec, e.g. to find out what changed when upgrading a generator.
+	//
+	// This is pure comparison logic, nothing is read from disk. Use ObtainGeneratorSpec to read the specs.
+	DiffGeneratorSpecs(ctx context.Context, oldSpec *GeneratorSpec, newSpec *GeneratorSpec) *GeneratorSpecDiff
+
+	// Export the variables of a specific generator spec as a JSON Schema (draft-07) document, for use with
+	// form builders, editors and validators.
+	//
+	// Each variable becomes a property, with its label as title, its description, and its validation pattern.
+	// Variables without a default are required. Type and default are taken from the default value, except that
+	// defaults containing templates are left out, because they are only evaluated during rendering.
+	ExportParameterSchema(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, error)
+
+	// Check parameter values against a specific generator spec without rendering anything, e.g. to validate
+	// each field of a form as the user types.
+	//
+	// Each given value is checked exactly as rendering would (type, pattern, item pattern and required keys),
+	// and required parameters without a value are listed separately. Set AllowIncomplete in the options to
+	// not treat the latter as an error.
+	ValidateParameters(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, options ParameterValidationOptions) *ParameterValidationResponse
+
 	// Write a fresh RenderSpec with defaults set from the GeneratorSpec for the given generator
 	//
 	// The name of the output file can be set in request.RenderSpecFile, but if left empty, it defaults to
@@ -19,6 +82,11 @@ type Api interface {
 	// generators and the generator targets in source control, so you can then review the changes made.
 	WriteRenderSpecWithDefaults(ctx context.Context, request *Request, generatorName string) *Response
 
+	// Like WriteRenderSpecWithDefaults, but writes the render spec to w instead of a file, in exactly the same format,
+	// e.g. to capture it in memory or send it over the network. request.RenderSpecFile is ignored, and the response
+	// lists no files.
+	WriteRenderSpecWithDefaultsTo(ctx context.Context, request *Request, generatorName string, w io.Writer) *Response
+
 	// Write a RenderSpec file with the provided parameter values
 	//
 	// The name of the output file can be set in request.RenderSpecFile, but if left empty, it defaults to
@@ -28,6 +96,18 @@ type Api interface {
 	// generators and the generator targets in source control, so you can then review the changes made.
 	WriteRenderSpecWithValues(ctx context.Context, request *Request, generatorName string, parameters map[string]interface{}) *Response
 
+	// Like WriteRenderSpecWithValues, but writes the render spec to w instead of a file, in exactly the same format.
+	// request.RenderSpecFile is ignored, and the response lists no files.
+	WriteRenderSpecWithValuesTo(ctx context.Context, request *Request, generatorName string, parameters map[string]interface{}, w io.Writer) *Response
+
+	// Tidy up an existing RenderSpec file after it was edited by hand or the GeneratorSpec has evolved.
+	//
+	// The file is read from request.RenderSpecFile, which defaults to "generated-<generatorName>.yaml", and must
+	// be for the given generator. Parameters given under an alias are renamed, parameters no longer in the
+	// GeneratorSpec are dropped, defaults are filled in for new variables, and the file is rewritten in the same
+	// canonical format as WriteRenderSpecWithDefaults uses. No validation is performed.
+	NormalizeRenderSpec(ctx context.Context, request *Request, generatorName string) *Response
+
 	// Render files from templates according to RenderSpec and the GeneratorSpec it references.
 	//
 	// First the RenderSpec is read from <request.TargetBaseDir>/<request.RenderSpecFile>".
@@ -38,4 +118,105 @@ type Api interface {
 	// Warning: existing files are silently overwritten! The idea is that you keep both your
 	// generators and the generator targets in source control, so you can then review the changes made.
 	Render(ctx context.Context, request *Request) *Response
+
+	// Render files from templates using the provided parameter values instead of reading a RenderSpec.
+	//
+	// request.RenderSpecFile is ignored, no render spec file is read or written. Missing parameters are
+	// set to their defaults, and all parameters are validated against the GeneratorSpec just like in Render.
+	//
+	// Warning: existing files are silently overwritten!
+	RenderWithValues(ctx context.Context, request *Request, generatorName string, parameters map[string]interface{}) *Response
+
+	// Like RenderWithValues, but the parameters are given as a struct (or pointer to a struct).
+	//
+	// Each exported field becomes a parameter. Its name is taken from the yaml tag, then the json tag, and
+	// finally the field name. Unexported fields and fields tagged "-" are ignored. Nil pointers and zero valued
+	// fields tagged omitempty leave their parameter unset, so it gets its default.
+	RenderWithStruct(ctx context.Context, request *Request, generatorName string, parameters interface{}) *Response
+
+	// Parse command-line style arguments into a parameter map suitable for RenderWithValues.
+	//
+	// "key=value" sets key to the string value. "key:=jsonvalue" parses the value as json, so numbers,
+	// booleans, lists and objects can be given (json numbers become float64). If a key is given more than once,
+	// the last one wins.
+	ParseParameterArgs(ctx context.Context, args []string) (map[string]interface{}, error)
+
+	// Read a dotenv style file of KEY=value lines into a parameter map suitable for RenderWithValues.
+	//
+	// Empty lines and lines starting with # are skipped, and an optional "export " prefix is ignored.
+	// Values in single quotes are taken literally, values in double quotes support the escapes \n, \t, \" and \\,
+	// and unquoted values end at a " #" comment. All values are strings, set request.CoerceStrings to convert
+	// them to the declared variable types. If a key is given more than once, the last one wins.
+	LoadParametersFromEnvFile(ctx context.Context, path string) (map[string]interface{}, error)
+
+	// Render a template given as a string, e.g. an expression a user wants to try out, instead of a file.
+	//
+	// The parameters are resolved from the render spec file for the given generator, just like Render does,
+	// including defaults, validation and transforms. request.IncludeBuildContext and request.ReproducibleBuild
+	// are honored. Nothing is written, the result is returned.
+	RenderExpression(ctx context.Context, request *Request, generatorName string, expression string) (string, error)
+
+	// Render several requests in sequence, e.g. first the "service" generator, then "ci", then "docs".
+	//
+	// Each request is rendered exactly as Render would, in the order given. A failing request does not stop
+	// the remaining ones from being rendered, its errors are collected in the BatchResponse instead.
+	//
+	// If several requests share a target directory, files written by a later request silently overwrite
+	// files of the same name written by an earlier one.
+	BatchRender(ctx context.Context, requests []*Request) *BatchResponse
+
+	// Check whether the files in the target directory are up to date, e.g. in CI to make sure generated
+	// files have been committed.
+	//
+	// Renders exactly as Render would, but in memory, and compares the results with the existing files.
+	// Nothing is written. Files that are missing or differ are listed in StaleFiles, with a diff for the latter.
+	CheckUpToDate(ctx context.Context, request *Request) *CheckResponse
+
+	// Render a single file and write its content to w instead of the target directory, e.g. to inspect it or use it in a pipeline.
+	//
+	// Renders exactly as Render would, but in memory, and writes only the file with the given target path
+	// (relative to the target directory) to w. Nothing is written to the target directory. Fails if the
+	// generator does not produce that file, e.g. because its condition is false.
+	RenderToWriter(ctx context.Context, request *Request, targetPath string, w io.Writer) *Response
+
+	// Find out which files a render run would produce, without rendering their contents or writing anything.
+	//
+	// Target paths, conditions and skip_if_target_exists are evaluated just like Render does, including
+	// with_items and with_entries, so the result lists each (source, target) pair, e.g. for a tree preview.
+	PlanRender(ctx context.Context, request *Request) *PlanResponse
+
+	// Find out which files a render run with the given parameter values would produce, like PlanRender,
+	// but without the need for a render spec file, e.g. to show users what a generator produces for their inputs.
+	//
+	// The parameters are validated exactly as they would be for RenderWithValues.
+	PlanRenderWithValues(ctx context.Context, request *Request, generatorName string, parameters map[string]interface{}) *PlanResponse
+
+	// Check a render spec file against its generator without rendering anything, like a linter, e.g. for
+	// editor integration.
+	//
+	// Reads the render spec file just like Render, makes sure the generator exists, and checks the parameters
+	// just like ValidateParameters, so unknown, invalid and missing required parameters are all reported.
+	ValidateRenderSpec(ctx context.Context, request *Request) *ParameterValidationResponse
+
+	// Explain how the value of each variable would be obtained for the given parameters, e.g. to debug a surprising value.
+	//
+	// Goes through the same steps as WriteRenderSpecWithValues, and reports for every variable the value that
+	// would be written to the render spec, and whether it was given explicitly, is the default of the variable
+	// as is or evaluated as a template, or was computed from default_when. Values are not validated.
+	ResolveDefaults(ctx context.Context, request *Request, generatorName string, parameters map[string]interface{}) *DefaultResolutionResponse
+
+	// Compare the parameters in an existing render spec to the current defaults of its generator, e.g. before upgrading.
+	//
+	// Reads the render spec file just like Render. Every parameter is classified as matching the default the variable
+	// would get now, customized, or no longer valid because it fails validation or the generator dropped the variable.
+	// Defaults are resolved just like ResolveDefaults does, given the other parameters in the render spec.
+	CompareRenderSpecToDefaults(ctx context.Context, request *Request) *DefaultComparisonResponse
+
+	// Find the declared variables of a generator that are never referred to, to trim generator specs and render specs.
+	//
+	// Reads the render spec file just like Render, and analyzes the templates of its generator, including files
+	// included with render by a literal path, and all fields that are evaluated as templates, such as targets and
+	// conditions. Nothing is rendered. The analysis is conservative: a template that uses the data as a whole,
+	// e.g. {{ toYaml . }}, counts as referring to every variable, so then none are reported.
+	FindUnusedVariables(ctx context.Context, request *Request) *UnusedVariablesResponse
 }
@@ -0,0 +1,35 @@
+package api
+
+// Controls how parameter values are checked, see Api.ValidateParameters
+type ParameterValidationOptions struct {
+	// If true, required parameters that have not been given yet do not count as an error, e.g. to validate
+	// a form while the user is still filling it in. They are still listed in MissingParameters.
+	AllowIncomplete bool
+}
+
+// The results of checking parameter values against a generator spec, see Api.ValidateParameters and Api.ValidateRenderSpec
+type ParameterValidationResponse struct {
+	// true if all given parameters are valid, and unless AllowIncomplete was set, no required parameter is missing
+	Success bool
+
+	// the given parameters that are invalid or unknown, sorted by name
+	InvalidParameters []InvalidParameter
+
+	// the names of required parameters that have not been given, sorted
+	MissingParameters []string
+
+	// errors that prevented validation, such as a missing generator spec
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+}
+
+// A parameter whose value is not acceptable
+type InvalidParameter struct {
+	// the name of the parameter, after resolving aliases
+	Name string
+
+	// what is wrong with the value, e.g. that it does not match the pattern of the variable
+	Error error
+}
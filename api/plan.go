@@ -0,0 +1,34 @@
+package api
+
+// Information about the files a render run would produce, see Api.PlanRender
+type PlanResponse struct {
+	// true if all target paths and conditions could be evaluated
+	Success bool
+
+	// the files in the same order Render would write them. Templates whose condition is false are not listed.
+	PlannedFiles []PlannedFile
+
+	// errors that prevented planning, such as a missing render spec or invalid parameter values
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+}
+
+// A file that a render run would produce
+type PlannedFile struct {
+	// the template the file would be rendered from, relative to the generator directory
+	RelativeSourcePath string
+
+	// the evaluated target path, relative to the target directory
+	RelativeTargetPath string
+
+	// true if the file would not be written because of skip_if_target_exists
+	Skipped bool
+
+	// true if the file would be deleted rather than written, see TemplateActionDelete. RelativeSourcePath is empty.
+	Delete bool
+
+	// errors evaluating the target path, condition or skip_if_target_exists of this file
+	Errors []error
+}
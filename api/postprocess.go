@@ -0,0 +1,16 @@
+package api
+
+// A post processor transforms the rendered output of a template before it is written, e.g. to format it.
+//
+// Templates name the post processors to apply in TemplateSpec.PostProcess. Besides the built-in ones
+// (PostProcessorGofmt and PostProcessorTrimSpace), callers can provide their own using generatorlib.WithPostProcessors.
+type PostProcessor func(output []byte) ([]byte, error)
+
+// Names of the built-in post processors
+const (
+	// formats go source code like gofmt does, failing if the output does not parse
+	PostProcessorGofmt = "gofmt"
+
+	// removes leading and trailing whitespace, including newlines
+	PostProcessorTrimSpace = "trimspace"
+)
@@ -1,11 +1,20 @@
 package api
 
+// The format version of render spec files written by this version of the library.
+//
+// Render spec files that do not specify a version are treated as version 1.
+const CurrentRenderSpecVersion = 1
+
 // All Information needed by a render run.
 //
 // The idea is that this is read from a generator-<name>.yaml file in the target directory so runs can be repeated.
 //
 // You can also have this library render a file with the default values for a given generator.
 type RenderSpec struct {
+	// Format version of the render spec file. Reading a file with a version newer than CurrentRenderSpecVersion
+	// fails, so older versions of this library do not misinterpret it.
+	Version int `yaml:"version,omitempty"`
+
 	// Name of the generator to use (determines yaml file to read for generator spec). The main one should be called
 	// generator-main.yaml, and GeneratorName should be set to "main".
 	GeneratorName string `yaml:"generator"`
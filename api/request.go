@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // Parameters you will need to provide for a render run. All the rest is read from parameters
 type Request struct {
 	// Directory where to find e.g. 'main.yaml' describing the generator. Required.
@@ -8,19 +10,220 @@ type Request struct {
 	// Directory where to find 'generator-main.yaml' specifying values and the generator to use. Required.
 	TargetBaseDir string `yaml:"targetdir"`
 
+	// Optional directory that relative SourceBaseDir and TargetBaseDir are resolved against, instead of the working
+	// directory of the process, for tools that operate on several roots. Absolute base directories are used as is.
+	WorkingDir string `yaml:"workingdir"`
+
 	// yaml-file to read for RenderSpec, if not set, defaults to "generated-main.yaml".
 	RenderSpecFile string `yaml:"renderspec"`
+
+	// Maximum time to spend rendering a single file, including its target path and condition. If exceeded,
+	// rendering the file is aborted and reported as an error for that file. Default values and transforms are
+	// aborted after the same time. Zero means no timeout. Rendering is also aborted when the context is cancelled.
+	RenderTimeout time.Duration `yaml:"rendertimeout"`
+
+	// Maximum size in bytes of a single rendered file. If a file's output gets larger, rendering it is aborted
+	// and reported as an error for that file, and nothing is written. Target paths, conditions, default values and
+	// transforms are subject to the same limit. Zero means no limit.
+	MaxFileBytes int64 `yaml:"maxfilebytes"`
+
+	// If true, the Response of a render run includes the fully resolved render spec in ResolvedSpec.
+	IncludeResolvedSpec bool `yaml:"includeresolvedspec"`
+
+	// If true, all templates can access information about the render run as .build, such as .build.timestamp,
+	// .build.os, .build.arch, .build.goVersion, .build.libraryVersion, .build.gitCommit and .build.gitBranch.
+	// The variable name "build" is then reserved.
+	IncludeBuildContext bool `yaml:"includebuildcontext"`
+
+	// The time to use for .build.timestamp. Defaults to the current time. Set it for reproducible output.
+	BuildTimestamp time.Time `yaml:"buildtimestamp"`
+
+	// If true, rendering produces identical output for identical inputs. Template functions with random or
+	// time dependent results (such as now, randAlphaNum or uuidv4) fail with an error, keys and values return
+	// their results sorted by key, and .build.timestamp defaults to the SOURCE_DATE_EPOCH environment variable
+	// (or the start of the unix epoch) instead of the current time.
+	ReproducibleBuild bool `yaml:"reproduciblebuild"`
+
+	// If not zero, the template functions randAlphaNum, randAlpha, randAscii, randNumeric, shuffle and uuidv4 draw
+	// from a random source seeded with this value, so rendering twice with the same seed produces identical output,
+	// e.g. for golden file tests. They are then also available in reproducible build mode. The results are not
+	// suitable for secrets.
+	Seed int64 `yaml:"seed"`
+
+	// If set, only templates that have at least one of these tags are rendered, all others are left out
+	// of the render run and the response. If empty, all templates are rendered.
+	RenderTags []string `yaml:"rendertags"`
+
+	// If true, default values are evaluated in safe defaults mode, where template functions that depend on the host
+	// (env, expandenv, getHostByName) or differ between runs (such as now or randAlphaNum) fail with an error,
+	// so a default cannot silently depend on the machine it is computed on.
+	SafeDefaults bool `yaml:"safedefaults"`
+
+	// If true, all templates, including target paths, conditions, default values and expressions given to
+	// RenderExpression, are evaluated in safe functions mode, where the same template functions as in safe defaults
+	// mode fail with an error. Set it when templates or expressions come from users, e.g. in a preview UI, so they
+	// cannot read the environment of the host.
+	SafeFunctions bool `yaml:"safefunctions"`
+
+	// How often to attempt reading a file from the generator or target directory before giving up, for file
+	// systems with transient errors, such as network shares. Zero or one means no retries. Errors that cannot be
+	// transient, such as a missing file, are never retried.
+	ReadAttempts int `yaml:"readattempts"`
+
+	// The time to wait before the first retry of a failed read, doubled for each further retry.
+	ReadRetryBackoff time.Duration `yaml:"readretrybackoff"`
+
+	// If true, a file whose target path differs from a path already written in the same render run only in case,
+	// e.g. "README.md" and "Readme.md", is reported as an error instead of silently overwriting it on
+	// case-insensitive file systems (the default on macOS and Windows).
+	DetectCaseCollisions bool `yaml:"detectcasecollisions"`
+
+	// If true, all target paths are converted to lower case before writing, so the output is the same on every
+	// file system. Collisions are detected on the paths before conversion.
+	LowercaseTargetPaths bool `yaml:"lowercasetargetpaths"`
+
+	// If true, an error evaluating the target path or condition of any template aborts the whole render run before
+	// anything is written, reporting all such errors as top level errors, because it usually indicates a broken
+	// generator spec. By default, such an error only fails the file concerned, and all other files are rendered.
+	FailOnTargetErrors bool `yaml:"failontargeterrors"`
+
+	// If true, write access to the target path of every file is checked before anything is written, and if any
+	// file could not be written, e.g. because a directory is read-only or a file is in the way of a directory,
+	// the render run is aborted, reporting all such problems as top level errors.
+	PreflightWriteCheck bool `yaml:"preflightwritecheck"`
+
+	// If true, a string value given for a variable of type list or map is parsed as json or yaml, for parameters
+	// that come from command line flags or environment variables, which are always strings.
+	CoerceStrings bool `yaml:"coercestrings"`
+
+	// If true, every rendered file ends in exactly one newline, as many editors and linters expect, unless its
+	// template sets ensure_final_newline to false. Files with just_copy set are written unchanged.
+	EnsureFinalNewline bool `yaml:"ensurefinalnewline"`
+
+	// If true, files that already exist keep their file permissions when they are overwritten, even if their
+	// template sets a mode, so permissions a user has changed with chmod are not reset. New files are still
+	// created with the mode of their template.
+	PreserveExistingMode bool `yaml:"preserveexistingmode"`
+
+	// If set, templates with with_items or with_entries only render the iterations starting at this zero based
+	// index (entries are counted in sorted key order), e.g. to try out a large generator quickly during development.
+	// Items keep their numbers in error messages.
+	ItemOffset int `yaml:"itemoffset"`
+
+	// If greater than zero, templates with with_items or with_entries render at most this many iterations,
+	// starting at ItemOffset. The response only contains FileResults for the rendered iterations.
+	// Zero, the default, renders all of them.
+	ItemLimit int `yaml:"itemlimit"`
+
+	// Optional name of a profile to render, for generators that offer variants such as "minimal" and "full".
+	// The profile name is available to all templates, conditions and default values as {{ .profile }}, and the
+	// defaults of the profile in GeneratorSpec.Profiles apply. If the generator declares profiles, the name
+	// must be one of them.
+	Profile string `yaml:"profile"`
+
+	// If true, all templates, conditions and default values can access the absolute path of the target directory
+	// as {{ .targetDir }}, e.g. to compute a module path. The variable name "targetDir" is then reserved. The path
+	// depends on the machine, so leave this off if the output must be reproducible.
+	IncludeTargetDir bool `yaml:"includetargetdir"`
+
+	// If true, all templates can tell which parameters the render spec sets explicitly from those that got their
+	// default, by looking them up in .provided, e.g. {{ if not .provided.port }} # auto-defaulted{{ end }}.
+	// A parameter counts as provided even if its value equals the default. The variable name "provided" is then reserved.
+	IncludeProvided bool `yaml:"includeprovided"`
+
+	// If true, files that git would ignore according to the .gitignore files of the target directory and the
+	// repository it is in are not written, so generators do not create artifacts the repository ignores.
+	// Such files are reported with Skipped set and SkipReason SkipReasonIgnoredByGit.
+	GitignoreAware bool `yaml:"gitignoreaware"`
+
+	// If true, templates whose condition is false are reported in RenderedFiles with their evaluated target path,
+	// Skipped set and SkipReason SkipReasonConditionFalse, so callers can see everything the generator decided.
+	// By default, they are left out of the response.
+	ReportConditionSkipped bool `yaml:"reportconditionskipped"`
+
+	// Optional value written into render specs for variables without a default, unless they declare their own
+	// placeholder, e.g. "CHANGEME". If empty, the empty string is written.
+	MissingValuePlaceholder string `yaml:"missingvalueplaceholder"`
+
+	// If true, WriteRenderSpecWithValues and WriteRenderSpecWithValuesTo leave out parameters whose value is the
+	// one the variable gets by default anyway, so the render spec only contains intentional customizations.
+	// Rendering resolves the omitted values from the defaults again, so it uses the same values.
+	OmitDefaults bool `yaml:"omitdefaults"`
+
+	// Optional parameter values that are deep-merged over those read from the render spec file, e.g. for quick
+	// experiments without rewriting the file. Where both have a map for the same parameter, the maps are merged,
+	// all other values are replaced. Unlike the render spec file, overrides must only name known parameters.
+	Overrides map[string]interface{} `yaml:"overrides"`
+
+	// Optional callback for embedding applications, called with the resolved parameters (after defaults and
+	// validation) just before the templates are rendered, e.g. to add values computed by the application.
+	// The returned map is used for rendering, or the original one if it returns nil. An error fails the render run.
+	// Changes are not part of ResolvedSpec, as they cannot be reproduced from a render spec file.
+	ParameterHook func(parameters map[string]interface{}) (map[string]interface{}, error) `yaml:"-"`
 }
 
 // Information about the results of a render run
 type Response struct {
-	Success       bool
+	Success bool
+
+	// The files in a deterministic order: the order of the templates in the generator spec, and for each template
+	// the order of its with_items (or the sorted keys of its with_entries). This order is guaranteed, so it is
+	// safe to compare against golden files.
 	RenderedFiles []FileResult
-	Errors        []error
+
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+
+	// The render spec actually used, with aliases resolved and all defaults filled in. Values of sensitive and
+	// bytes variables are replaced by "***".
+	// Only set by render operations, and only if requested by setting IncludeResolvedSpec in the Request.
+	ResolvedSpec *RenderSpec
 }
 
 type FileResult struct {
 	Success          bool
 	RelativeFilePath string
 	Errors           []error
+
+	// true if the file was intentionally not written, e.g. because of skip_if_target_exists. Success is true in this case.
+	Skipped bool
+
+	// why the file was skipped, one of the SkipReason constants. Only set if Skipped is true.
+	SkipReason string
+
+	// true if the file was deleted by a template with TemplateActionDelete. If there was nothing to delete,
+	// Skipped is set instead.
+	Deleted bool
+}
+
+// The values of FileResult.SkipReason
+const (
+	// the condition of the template is false, only reported if Request.ReportConditionSkipped is set
+	SkipReasonConditionFalse = "condition false"
+
+	// the path given in skip_if_target_exists exists in the target directory
+	SkipReasonTargetExists = "target exists"
+
+	// a template with TemplateActionDelete found no file to delete
+	SkipReasonNothingToDelete = "nothing to delete"
+
+	// git would ignore the file, see Request.GitignoreAware
+	SkipReasonIgnoredByGit = "ignored by git"
+)
+
+// Information about the results of a batch render run
+type BatchResponse struct {
+	// true only if every request in the batch was rendered successfully
+	Success bool
+
+	// one Response per request, in the order the requests were given
+	Responses []*Response
+
+	// the top level errors of all requests, prefixed with the number of the request they occurred in
+	Errors []error
+
+	// the warnings of all requests, prefixed with the number of the request they occurred in
+	Warnings []string
 }
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AsError returns nil if the render run was successful, and otherwise a single error for idiomatic error handling,
+// e.g. `if err := response.AsError(); err != nil`.
+//
+// If there is just one error, it is returned as is. Otherwise, the result is a *MultiError holding all top level
+// errors, followed by the errors of the individual files, prefixed with their path.
+func (r *Response) AsError() error {
+	var errs []error
+	errs = append(errs, r.Errors...)
+	for _, f := range r.RenderedFiles {
+		for _, err := range f.Errors {
+			errs = append(errs, fmt.Errorf("%s: %w", f.RelativeFilePath, err))
+		}
+	}
+	switch {
+	case len(errs) == 1:
+		return errs[0]
+	case len(errs) > 1:
+		return &MultiError{Errors: errs}
+	case !r.Success:
+		return fmt.Errorf("render run failed without reporting an error")
+	default:
+		return nil
+	}
+}
+
+// Err returns the first error of the render run, or nil if there is none, see AsError.
+func (r *Response) Err() error {
+	err := r.AsError()
+	if multi, ok := err.(*MultiError); ok {
+		return multi.Errors[0]
+	}
+	return err
+}
+
+// MultiError combines several errors into one, see Response.AsError.
+type MultiError struct {
+	Errors []error
+}
+
+// Error lists the messages of all errors, one per line.
+func (e *MultiError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap makes errors.Is and errors.As look at all errors (Go 1.20 and later).
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,53 @@
+package api
+
+// The differences between two versions of a GeneratorSpec, e.g. when upgrading a generator.
+//
+// All lists are sorted by variable name or in template order, respectively, and empty if there is no such change.
+type GeneratorSpecDiff struct {
+	// Variables only present in the new spec
+	AddedVariables []string
+
+	// Variables only present in the old spec
+	RemovedVariables []string
+
+	// Variables that are required in the new spec (no default), but were absent or optional in the old spec.
+	// Users upgrading to the new spec will need to supply values for these.
+	NewlyRequiredVariables []string
+
+	// Variables present in both specs whose declaration changed
+	ChangedVariables []VariableChange
+
+	// Templates only present in the new spec, matched by source and target path
+	AddedTemplates []TemplateSpec
+
+	// Templates only present in the old spec, matched by source and target path
+	RemovedTemplates []TemplateSpec
+
+	// Templates present in both specs whose other fields (condition, items, ...) changed
+	ChangedTemplates []TemplateChange
+}
+
+// A variable whose declaration differs between two versions of a GeneratorSpec
+type VariableChange struct {
+	Name string
+
+	Old VariableSpec
+	New VariableSpec
+
+	DefaultChanged     bool
+	PatternChanged     bool
+	DescriptionChanged bool
+}
+
+// A template whose declaration differs between two versions of a GeneratorSpec
+type TemplateChange struct {
+	Old TemplateSpec
+	New TemplateSpec
+}
+
+// true if the two specs did not differ
+func (d *GeneratorSpecDiff) IsEmpty() bool {
+	return len(d.AddedVariables) == 0 && len(d.RemovedVariables) == 0 && len(d.NewlyRequiredVariables) == 0 &&
+		len(d.ChangedVariables) == 0 && len(d.AddedTemplates) == 0 && len(d.RemovedTemplates) == 0 &&
+		len(d.ChangedTemplates) == 0
+}
@@ -0,0 +1,20 @@
+package api
+
+// Controls how a generator spec is checked, see Api.ValidateGeneratorSpecWithOptions
+type SpecValidationOptions struct {
+	// If true, likely mistakes that are only warnings by default, such as a with_items template whose target path
+	// does not depend on the item, are reported as errors instead.
+	Strict bool
+}
+
+// The results of checking a generator spec, see Api.ValidateGeneratorSpecWithOptions
+type SpecValidationResponse struct {
+	// true if there are no errors. Warnings do not affect Success.
+	Success bool
+
+	// all problems found, or an empty list if the generator spec is fine
+	Errors []error
+
+	// likely mistakes that do not prevent rendering, such as several iterations of a template writing the same file
+	Warnings []string
+}
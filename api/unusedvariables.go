@@ -0,0 +1,21 @@
+package api
+
+// Reports the variables of a generator that nothing refers to, see Api.FindUnusedVariables
+type UnusedVariablesResponse struct {
+	// true if all templates could be analyzed
+	Success bool
+
+	// the declared variables that no template, target path, condition or other field evaluated as a template
+	// refers to, sorted by name
+	UnusedVariables []string
+
+	// the unused variables that the render spec sets a value for, sorted by name. These can be removed
+	// from the render spec, too.
+	SetInRenderSpec []string
+
+	// errors that prevented the analysis, such as a missing render spec or a template that does not parse
+	Errors []error
+
+	// Non-fatal advisories, such as the use of a deprecated parameter alias. These do not affect Success.
+	Warnings []string
+}
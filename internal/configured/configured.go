@@ -0,0 +1,138 @@
+package configured
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"io"
+)
+
+// Generator applies the configuration of an instance, such as extra template functions or a cache, to every call,
+// by adding it to the context before delegating to the wrapped implementation.
+type Generator struct {
+	Wrapped   api.Api
+	Configure func(ctx context.Context) context.Context
+}
+
+func (g *Generator) FindGeneratorNames(ctx context.Context, sourceBaseDir string) ([]string, error) {
+	return g.Wrapped.FindGeneratorNames(g.Configure(ctx), sourceBaseDir)
+}
+
+func (g *Generator) ObtainGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) (*api.GeneratorSpec, error) {
+	return g.Wrapped.ObtainGeneratorSpec(g.Configure(ctx), sourceBaseDir, generatorName)
+}
+
+func (g *Generator) CatalogGenerators(ctx context.Context, sourceBaseDir string) *api.GeneratorCatalog {
+	return g.Wrapped.CatalogGenerators(g.Configure(ctx), sourceBaseDir)
+}
+
+func (g *Generator) FindGeneratorNamesWithOptions(ctx context.Context, sourceBaseDir string, options api.DiscoveryOptions) ([]string, error) {
+	return g.Wrapped.FindGeneratorNamesWithOptions(g.Configure(ctx), sourceBaseDir, options)
+}
+
+func (g *Generator) ObtainGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.DiscoveryOptions) (*api.GeneratorSpec, error) {
+	return g.Wrapped.ObtainGeneratorSpecWithOptions(g.Configure(ctx), sourceBaseDir, generatorName, options)
+}
+
+func (g *Generator) ReadGeneratorSpecRaw(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, string, error) {
+	return g.Wrapped.ReadGeneratorSpecRaw(g.Configure(ctx), sourceBaseDir, generatorName)
+}
+
+func (g *Generator) ValidateGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) []error {
+	return g.Wrapped.ValidateGeneratorSpec(g.Configure(ctx), sourceBaseDir, generatorName)
+}
+
+func (g *Generator) ValidateGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.SpecValidationOptions) *api.SpecValidationResponse {
+	return g.Wrapped.ValidateGeneratorSpecWithOptions(g.Configure(ctx), sourceBaseDir, generatorName, options)
+}
+
+func (g *Generator) DiffGeneratorSpecs(ctx context.Context, oldSpec *api.GeneratorSpec, newSpec *api.GeneratorSpec) *api.GeneratorSpecDiff {
+	return g.Wrapped.DiffGeneratorSpecs(g.Configure(ctx), oldSpec, newSpec)
+}
+
+func (g *Generator) ExportParameterSchema(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, error) {
+	return g.Wrapped.ExportParameterSchema(g.Configure(ctx), sourceBaseDir, generatorName)
+}
+
+func (g *Generator) ValidateParameters(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, options api.ParameterValidationOptions) *api.ParameterValidationResponse {
+	return g.Wrapped.ValidateParameters(g.Configure(ctx), sourceBaseDir, generatorName, parameters, options)
+}
+
+func (g *Generator) WriteRenderSpecWithDefaults(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	return g.Wrapped.WriteRenderSpecWithDefaults(g.Configure(ctx), request, generatorName)
+}
+
+func (g *Generator) WriteRenderSpecWithDefaultsTo(ctx context.Context, request *api.Request, generatorName string, w io.Writer) *api.Response {
+	return g.Wrapped.WriteRenderSpecWithDefaultsTo(g.Configure(ctx), request, generatorName, w)
+}
+
+func (g *Generator) WriteRenderSpecWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	return g.Wrapped.WriteRenderSpecWithValues(g.Configure(ctx), request, generatorName, parameters)
+}
+
+func (g *Generator) WriteRenderSpecWithValuesTo(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}, w io.Writer) *api.Response {
+	return g.Wrapped.WriteRenderSpecWithValuesTo(g.Configure(ctx), request, generatorName, parameters, w)
+}
+
+func (g *Generator) NormalizeRenderSpec(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	return g.Wrapped.NormalizeRenderSpec(g.Configure(ctx), request, generatorName)
+}
+
+func (g *Generator) Render(ctx context.Context, request *api.Request) *api.Response {
+	return g.Wrapped.Render(g.Configure(ctx), request)
+}
+
+func (g *Generator) RenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	return g.Wrapped.RenderWithValues(g.Configure(ctx), request, generatorName, parameters)
+}
+
+func (g *Generator) RenderWithStruct(ctx context.Context, request *api.Request, generatorName string, parameters interface{}) *api.Response {
+	return g.Wrapped.RenderWithStruct(g.Configure(ctx), request, generatorName, parameters)
+}
+
+func (g *Generator) ParseParameterArgs(ctx context.Context, args []string) (map[string]interface{}, error) {
+	return g.Wrapped.ParseParameterArgs(g.Configure(ctx), args)
+}
+
+func (g *Generator) LoadParametersFromEnvFile(ctx context.Context, path string) (map[string]interface{}, error) {
+	return g.Wrapped.LoadParametersFromEnvFile(g.Configure(ctx), path)
+}
+
+func (g *Generator) RenderExpression(ctx context.Context, request *api.Request, generatorName string, expression string) (string, error) {
+	return g.Wrapped.RenderExpression(g.Configure(ctx), request, generatorName, expression)
+}
+
+func (g *Generator) BatchRender(ctx context.Context, requests []*api.Request) *api.BatchResponse {
+	return g.Wrapped.BatchRender(g.Configure(ctx), requests)
+}
+
+func (g *Generator) CheckUpToDate(ctx context.Context, request *api.Request) *api.CheckResponse {
+	return g.Wrapped.CheckUpToDate(g.Configure(ctx), request)
+}
+
+func (g *Generator) RenderToWriter(ctx context.Context, request *api.Request, targetPath string, w io.Writer) *api.Response {
+	return g.Wrapped.RenderToWriter(g.Configure(ctx), request, targetPath, w)
+}
+
+func (g *Generator) PlanRender(ctx context.Context, request *api.Request) *api.PlanResponse {
+	return g.Wrapped.PlanRender(g.Configure(ctx), request)
+}
+
+func (g *Generator) PlanRenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.PlanResponse {
+	return g.Wrapped.PlanRenderWithValues(g.Configure(ctx), request, generatorName, parameters)
+}
+
+func (g *Generator) ValidateRenderSpec(ctx context.Context, request *api.Request) *api.ParameterValidationResponse {
+	return g.Wrapped.ValidateRenderSpec(g.Configure(ctx), request)
+}
+
+func (g *Generator) ResolveDefaults(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.DefaultResolutionResponse {
+	return g.Wrapped.ResolveDefaults(g.Configure(ctx), request, generatorName, parameters)
+}
+
+func (g *Generator) CompareRenderSpecToDefaults(ctx context.Context, request *api.Request) *api.DefaultComparisonResponse {
+	return g.Wrapped.CompareRenderSpecToDefaults(g.Configure(ctx), request)
+}
+
+func (g *Generator) FindUnusedVariables(ctx context.Context, request *api.Request) *api.UnusedVariablesResponse {
+	return g.Wrapped.FindUnusedVariables(g.Configure(ctx), request)
+}
@@ -0,0 +1,133 @@
+package implementation
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const buildContextParameterName = "build"
+
+const libraryModulePath = "github.com/mundobaton/go-generator-lib"
+
+// buildContext assembles the values made available to all templates as .build if requested.
+//
+// Git information is read directly from the .git directory the target directory is in, if any,
+// so no git installation is needed. All values are strings, missing information is the empty string.
+func (i *GeneratorImpl) buildContext(ctx context.Context, request *api.Request) (map[string]interface{}, error) {
+	timestamp := request.BuildTimestamp
+	if timestamp.IsZero() {
+		if isReproducibleBuild(ctx) {
+			var err error
+			timestamp, err = sourceDateEpoch()
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			timestamp = time.Now()
+		}
+	}
+	gitCommit, gitBranch := gitInfo(workingdir.Resolve(ctx, request.TargetBaseDir))
+	return map[string]interface{}{
+		"timestamp":      timestamp.UTC().Format(time.RFC3339),
+		"os":             runtime.GOOS,
+		"arch":           runtime.GOARCH,
+		"goVersion":      runtime.Version(),
+		"libraryVersion": libraryVersion(),
+		"gitCommit":      gitCommit,
+		"gitBranch":      gitBranch,
+	}, nil
+}
+
+func libraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Path == libraryModulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == libraryModulePath {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+func gitInfo(dir string) (commit string, branch string) {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return "", ""
+	}
+	head, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", ""
+	}
+	headStr := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(headStr, "ref: ") {
+		// detached head
+		return headStr, ""
+	}
+	ref := strings.TrimPrefix(headStr, "ref: ")
+	branch = strings.TrimPrefix(ref, "refs/heads/")
+	if refContents, err := ioutil.ReadFile(filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(refContents)), branch
+	}
+	return packedRef(gitDir, ref), branch
+}
+
+// findGitDir looks for a .git directory in dir and its parents. For worktrees, .git is a file pointing to it.
+func findGitDir(dir string) string {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		candidate := filepath.Join(current, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return candidate
+			}
+			contents, err := ioutil.ReadFile(candidate)
+			if err == nil && bytes.HasPrefix(contents, []byte("gitdir: ")) {
+				gitDir := strings.TrimSpace(strings.TrimPrefix(string(contents), "gitdir: "))
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(current, gitDir)
+				}
+				return gitDir
+			}
+			return ""
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return ""
+		}
+		current = parent
+	}
+}
+
+func packedRef(gitDir string, ref string) string {
+	file, err := os.Open(filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+	return ""
+}
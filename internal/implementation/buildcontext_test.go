@@ -0,0 +1,58 @@
+package implementation
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// these tests cover reading git information without a git installation
+
+func TestGitInfo_BranchFromLooseRef(t *testing.T) {
+	dir := fakeGitRepo(t, map[string]string{
+		"HEAD":                 "ref: refs/heads/feature/x\n",
+		"refs/heads/feature/x": "0123456789abcdef0123456789abcdef01234567\n",
+	})
+	defer os.RemoveAll(dir)
+
+	commit, branch := gitInfo(filepath.Join(dir, "sub"))
+	require.Equal(t, "0123456789abcdef0123456789abcdef01234567", commit)
+	require.Equal(t, "feature/x", branch)
+}
+
+func TestGitInfo_BranchFromPackedRef(t *testing.T) {
+	dir := fakeGitRepo(t, map[string]string{
+		"HEAD":        "ref: refs/heads/main\n",
+		"packed-refs": "# pack-refs with: peeled fully-peeled sorted\nfedcba9876543210fedcba9876543210fedcba98 refs/heads/main\n",
+	})
+	defer os.RemoveAll(dir)
+
+	commit, branch := gitInfo(filepath.Join(dir, "sub"))
+	require.Equal(t, "fedcba9876543210fedcba9876543210fedcba98", commit)
+	require.Equal(t, "main", branch)
+}
+
+func TestGitInfo_DetachedHead(t *testing.T) {
+	dir := fakeGitRepo(t, map[string]string{
+		"HEAD": "0123456789abcdef0123456789abcdef01234567\n",
+	})
+	defer os.RemoveAll(dir)
+
+	commit, branch := gitInfo(filepath.Join(dir, "sub"))
+	require.Equal(t, "0123456789abcdef0123456789abcdef01234567", commit)
+	require.Equal(t, "", branch)
+}
+
+func fakeGitRepo(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "generator-gitinfo-")
+	require.Nil(t, err)
+	require.Nil(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	for name, contents := range files {
+		path := filepath.Join(dir, ".git", filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+	return dir
+}
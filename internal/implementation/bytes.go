@@ -0,0 +1,54 @@
+package implementation
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"strings"
+	"time"
+)
+
+// decodeTypedValue converts a value given in a render spec according to the type declared for the variable.
+//
+// Values of type list and map must have that structure, see decodeStructuredValue.
+//
+// Values of type bytes are given as base64 and are made available to templates as a string holding the
+// decoded bytes, so they can be written out directly, or passed to b64enc.
+//
+// Values of type duration are made available as a time.Duration, see decodeDurationValue.
+func (i *GeneratorImpl) decodeTypedValue(ctx context.Context, varName string, varSpec api.VariableSpec, val interface{}) (interface{}, error) {
+	switch varSpec.Type {
+	case "":
+		return val, nil
+	case api.VariableTypeBytes:
+		encoded, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("value for parameter '%s' must be a base64 encoded string", varName)
+		}
+		// allow line breaks, as in yaml block scalars
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+		if err != nil {
+			// do not include the value in the error, it may be a secret such as a keystore
+			return nil, fmt.Errorf("value for parameter '%s' is not valid base64", varName)
+		}
+		return string(decoded), nil
+	case api.VariableTypeList, api.VariableTypeMap:
+		return decodeStructuredValue(ctx, varName, varSpec.Type, val)
+	case api.VariableTypeDuration:
+		return decodeDurationValue(varName, varSpec, val)
+	default:
+		return nil, fmt.Errorf("variable declaration %s has unknown type %s (this is an error in the generator spec)", varName, varSpec.Type)
+	}
+}
+
+// encodeTypedValue is the reverse of decodeTypedValue, used to obtain a value that can be written to a render spec.
+func (i *GeneratorImpl) encodeTypedValue(varSpec api.VariableSpec, val interface{}) interface{} {
+	if decoded, ok := val.(string); ok && varSpec.Type == api.VariableTypeBytes {
+		return base64.StdEncoding.EncodeToString([]byte(decoded))
+	}
+	if duration, ok := val.(time.Duration); ok && varSpec.Type == api.VariableTypeDuration {
+		return duration.String()
+	}
+	return val
+}
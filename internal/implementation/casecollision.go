@@ -0,0 +1,48 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// the target paths written so far are needed across all templates of a render run. withRequestOptions is called
+// once per run, so each run starts with an empty set of paths.
+type caseCollisionsKey struct{}
+
+type caseCollisions struct {
+	// lowercased target path -> target path as first written
+	seen map[string]string
+}
+
+func withCaseCollisionDetection(ctx context.Context) context.Context {
+	return context.WithValue(ctx, caseCollisionsKey{}, &caseCollisions{seen: map[string]string{}})
+}
+
+// checkCaseCollision records targetPath, and fails if a different path that only differs in case was written
+// before in the same render run. Writing the same path twice is not a collision.
+func checkCaseCollision(ctx context.Context, targetPath string) error {
+	collisions, ok := ctx.Value(caseCollisionsKey{}).(*caseCollisions)
+	if !ok {
+		return nil
+	}
+	key := strings.ToLower(targetPath)
+	if previous, ok := collisions.seen[key]; ok && previous != targetPath {
+		return fmt.Errorf("target path '%s' differs from '%s' only in case, so it would overwrite it on case-insensitive file systems", targetPath, previous)
+	}
+	collisions.seen[key] = targetPath
+	return nil
+}
+
+type lowercaseTargetPathsKey struct{}
+
+func withLowercaseTargetPaths(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lowercaseTargetPathsKey{}, true)
+}
+
+func normalizeTargetPathCase(ctx context.Context, targetPath string) string {
+	if lowercase, _ := ctx.Value(lowercaseTargetPathsKey{}).(bool); lowercase {
+		return strings.ToLower(targetPath)
+	}
+	return targetPath
+}
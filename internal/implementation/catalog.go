@@ -0,0 +1,38 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+)
+
+func (i *GeneratorImpl) CatalogGenerators(ctx context.Context, sourceBaseDir string) *api.GeneratorCatalog {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+	names, err := sourceDir.FindGeneratorNames(ctx)
+	if err != nil {
+		return &api.GeneratorCatalog{Generators: []api.CatalogEntry{}, Errors: []error{err}}
+	}
+
+	result := &api.GeneratorCatalog{Success: true, Generators: []api.CatalogEntry{}}
+	for _, name := range names {
+		genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, name)
+		if err != nil {
+			// one broken generator must not hide the others
+			result.Generators = append(result.Generators, api.CatalogEntry{Name: name, Outputs: []api.CatalogOutput{}, Errors: []error{err}})
+			result.Success = false
+			continue
+		}
+
+		entry := api.CatalogEntry{Name: name, Spec: genSpec, Outputs: []api.CatalogOutput{}, Errors: []error{}}
+		for _, tplSpec := range genSpec.Templates {
+			entry.Outputs = append(entry.Outputs, api.CatalogOutput{
+				Source:      templateSourceName(&tplSpec),
+				Target:      tplSpec.RelativeTargetPath,
+				Iterates:    len(tplSpec.WithItems) > 0 || len(tplSpec.WithEntries) > 0,
+				Conditional: tplSpec.Condition != "",
+			})
+		}
+		result.Generators = append(result.Generators, entry)
+	}
+	return result
+}
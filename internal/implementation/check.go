@@ -0,0 +1,134 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"strings"
+)
+
+// above this many line pairs, computing a diff is too expensive, and we just report that the files differ
+const maxDiffComplexity = 4000000
+
+func (i *GeneratorImpl) CheckUpToDate(ctx context.Context, request *api.Request) *api.CheckResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return &api.CheckResponse{Errors: []error{err}}
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return &api.CheckResponse{Errors: []error{err}}
+	}
+
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return &api.CheckResponse{Errors: []error{err}}
+	}
+
+	ctx = withParameterSource(ctx, "render spec file "+targetDir.RenderSpecFilenameOrDefault(ctx, request.RenderSpecFile))
+	capturingDir := targetDir.WithCapturedWrites()
+	response := i.renderWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, capturingDir)
+	if !response.Success {
+		result := &api.CheckResponse{Errors: response.Errors}
+		for _, f := range response.RenderedFiles {
+			for _, err := range f.Errors {
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %s", f.RelativeFilePath, err.Error()))
+			}
+		}
+		return result
+	}
+
+	result := &api.CheckResponse{Success: true}
+	checked := map[string]bool{}
+	for _, f := range response.RenderedFiles {
+		if f.Skipped || checked[f.RelativeFilePath] {
+			continue
+		}
+		checked[f.RelativeFilePath] = true
+
+		rendered := capturingDir.Captured()[f.RelativeFilePath]
+		existing, err := targetDir.ReadFile(ctx, f.RelativeFilePath)
+		if f.Deleted {
+			// a delete template would remove the file, so it is stale if it still exists
+			if err != nil {
+				continue
+			}
+			result.StaleFiles = append(result.StaleFiles, api.StaleFile{RelativeFilePath: f.RelativeFilePath, Diff: lineDiff(string(existing), "")})
+		} else if err != nil {
+			result.StaleFiles = append(result.StaleFiles, api.StaleFile{RelativeFilePath: f.RelativeFilePath, Missing: true})
+		} else if !bytes.Equal(existing, rendered) {
+			result.StaleFiles = append(result.StaleFiles, api.StaleFile{RelativeFilePath: f.RelativeFilePath, Diff: lineDiff(string(existing), string(rendered))})
+		}
+	}
+	result.Success = len(result.StaleFiles) == 0
+	return result
+}
+
+// lineDiff produces a simple diff from old to new, listing every line of both with a prefix of "-" (only in old),
+// "+" (only in new) or " " (in both).
+func lineDiff(old string, new string) string {
+	oldLines := strings.SplitAfter(old, "\n")
+	newLines := strings.SplitAfter(new, "\n")
+	if len(oldLines)*len(newLines) > maxDiffComplexity {
+		return "files differ, too large to compute a diff\n"
+	}
+
+	// lcs[x][y] is the length of the longest common subsequence of oldLines[x:] and newLines[y:]
+	lcs := make([][]int, len(oldLines)+1)
+	for x := range lcs {
+		lcs[x] = make([]int, len(newLines)+1)
+	}
+	for x := len(oldLines) - 1; x >= 0; x-- {
+		for y := len(newLines) - 1; y >= 0; y-- {
+			if oldLines[x] == newLines[y] {
+				lcs[x][y] = lcs[x+1][y+1] + 1
+			} else if lcs[x+1][y] >= lcs[x][y+1] {
+				lcs[x][y] = lcs[x+1][y]
+			} else {
+				lcs[x][y] = lcs[x][y+1]
+			}
+		}
+	}
+
+	var buf strings.Builder
+	writeLine := func(prefix string, line string) {
+		if line == "" {
+			// SplitAfter produces an empty last element if the text ends with a newline
+			return
+		}
+		buf.WriteString(prefix)
+		buf.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n")
+		}
+	}
+	x, y := 0, 0
+	for x < len(oldLines) && y < len(newLines) {
+		if oldLines[x] == newLines[y] {
+			writeLine(" ", oldLines[x])
+			x++
+			y++
+		} else if lcs[x+1][y] >= lcs[x][y+1] {
+			writeLine("-", oldLines[x])
+			x++
+		} else {
+			writeLine("+", newLines[y])
+			y++
+		}
+	}
+	for ; x < len(oldLines); x++ {
+		writeLine("-", oldLines[x])
+	}
+	for ; y < len(newLines); y++ {
+		writeLine("+", newLines[y])
+	}
+	return buf.String()
+}
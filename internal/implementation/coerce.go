@@ -0,0 +1,46 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"gopkg.in/yaml.v2"
+)
+
+type coerceStringsKey struct{}
+
+func withCoerceStrings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, coerceStringsKey{}, true)
+}
+
+func isCoerceStrings(ctx context.Context) bool {
+	coerce, _ := ctx.Value(coerceStringsKey{}).(bool)
+	return coerce
+}
+
+// decodeStructuredValue checks that the value of a variable of type list or map has that structure.
+//
+// If string coercion is on, a string value is parsed first. Yaml is a superset of json, so both work.
+// Parsed maps get string keys, like structured values from the generator spec.
+func decodeStructuredValue(ctx context.Context, varName string, varType string, val interface{}) (interface{}, error) {
+	if str, ok := val.(string); ok && isCoerceStrings(ctx) {
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(str), &parsed); err != nil {
+			return nil, fmt.Errorf("value for parameter '%s' cannot be parsed as a %s: %s", varName, varType, err.Error())
+		}
+		val = generatordir.NormalizeYamlValue(parsed)
+	}
+
+	switch val.(type) {
+	case []interface{}:
+		if varType == api.VariableTypeList {
+			return val, nil
+		}
+	case map[interface{}]interface{}, map[string]interface{}:
+		if varType == api.VariableTypeMap {
+			return val, nil
+		}
+	}
+	return nil, fmt.Errorf("value for parameter '%s' must be a %s", varName, varType)
+}
@@ -0,0 +1,114 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"regexp"
+	"strings"
+)
+
+var referencedVariableRegex = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// conditionalDefaultsResolver evaluates default_when for the variables that were not given a value.
+//
+// Conditions refer to other parameters, which may have conditional defaults themselves, so they are resolved
+// on demand, and chain lists the variables currently being resolved, so cycles can be detected.
+type conditionalDefaultsResolver struct {
+	impl     *GeneratorImpl
+	genSpec  *api.GeneratorSpec
+	given    map[string]interface{}
+	resolved map[string]interface{}
+	matched  map[string]bool
+	chain    []string
+}
+
+// applyConditionalDefaults returns a copy of parameters that additionally contains the value of the first matching
+// conditional default for each variable that has not been given. Variables where no condition matches are left
+// out, so their regular default applies.
+func (i *GeneratorImpl) applyConditionalDefaults(ctx context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(parameters))
+	for k, v := range parameters {
+		result[k] = v
+	}
+
+	r := &conditionalDefaultsResolver{impl: i, genSpec: genSpec, given: parameters, resolved: map[string]interface{}{}, matched: map[string]bool{}}
+	for _, varName := range sortedVariableNames(genSpec) {
+		if _, given := parameters[varName]; given || len(genSpec.Variables[varName].DefaultWhen) == 0 {
+			continue
+		}
+		val, err := r.resolve(ctx, varName)
+		if err != nil {
+			return nil, err
+		}
+		if r.matched[varName] {
+			result[varName] = val
+		}
+	}
+	return result, nil
+}
+
+// resolve obtains the value the variable has for the purpose of evaluating conditions: the given value,
+// the matching conditional default, or the regular default, in that order. Missing values are nil.
+func (r *conditionalDefaultsResolver) resolve(ctx context.Context, varName string) (interface{}, error) {
+	if val, ok := r.given[varName]; ok {
+		return val, nil
+	}
+	if val, ok := r.resolved[varName]; ok {
+		return val, nil
+	}
+	for _, name := range r.chain {
+		if name == varName {
+			return nil, fmt.Errorf("variable declaration %s has cyclic conditional defaults: %s -> %s (this is an error in the generator spec)",
+				varName, strings.Join(r.chain, " -> "), varName)
+		}
+	}
+	r.chain = append(r.chain, varName)
+	defer func() { r.chain = r.chain[:len(r.chain)-1] }()
+
+	varSpec, ok := r.genSpec.Variables[varName]
+	if !ok {
+		return nil, nil
+	}
+	for counter, conditional := range varSpec.DefaultWhen {
+		data, err := r.conditionData(ctx, conditional.Condition)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := r.impl.evaluateCondition(ctx, conditional.Condition, data, fmt.Sprintf("__defaultwhen_%s_%d", varName, counter+1))
+		if err != nil {
+			return nil, fmt.Errorf("variable declaration %s has invalid condition in default_when (this is an error in the generator spec): %s", varName, err.Error())
+		}
+		if matches {
+			r.resolved[varName] = conditional.Value
+			r.matched[varName] = true
+			return conditional.Value, nil
+		}
+	}
+
+	var val interface{}
+	if defaultStr, ok := varSpec.DefaultValue.(string); ok {
+		rendered, err := r.impl.renderStringDefaultFromTemplate(ctx, varName, defaultStr)
+		if err != nil {
+			return nil, err
+		}
+		val = rendered
+	} else if varSpec.DefaultValue != nil {
+		val = varSpec.DefaultValue
+	}
+	r.resolved[varName] = val
+	return val, nil
+}
+
+// conditionData resolves the variables a condition refers to
+func (r *conditionalDefaultsResolver) conditionData(ctx context.Context, condition string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for _, match := range referencedVariableRegex.FindAllStringSubmatch(condition, -1) {
+		val, err := r.resolve(ctx, match[1])
+		if err != nil {
+			return nil, err
+		}
+		data[match[1]] = val
+	}
+	return data, nil
+}
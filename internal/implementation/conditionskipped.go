@@ -0,0 +1,14 @@
+package implementation
+
+import "context"
+
+type reportConditionSkippedKey struct{}
+
+func withReportConditionSkipped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reportConditionSkippedKey{}, true)
+}
+
+func shouldReportConditionSkipped(ctx context.Context) bool {
+	report, _ := ctx.Value(reportConditionSkippedKey{}).(bool)
+	return report
+}
@@ -0,0 +1,76 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"gopkg.in/yaml.v2"
+)
+
+func (i *GeneratorImpl) CompareRenderSpecToDefaults(ctx context.Context, request *api.Request) *api.DefaultComparisonResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return &api.DefaultComparisonResponse{Errors: []error{err}}
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return &api.DefaultComparisonResponse{Errors: []error{err}}
+	}
+
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, renderSpec.Parameters)
+	result := &api.DefaultComparisonResponse{
+		Success:    true,
+		Parameters: map[string]api.ComparedParameter{},
+		Warnings:   warnings,
+	}
+	for _, name := range sortedParameterNames(parameters) {
+		value := parameters[name]
+		varSpec, ok := genSpec.Variables[name]
+		if !ok {
+			result.Parameters[name] = api.ComparedParameter{Value: value, Status: api.ParameterStatusNoLongerValid, Reason: "the generator no longer has this variable"}
+			continue
+		}
+
+		// the default may depend on the other values, so it is resolved as if just this one was missing
+		others := make(map[string]interface{}, len(parameters))
+		for k, v := range parameters {
+			if k != name {
+				others[k] = v
+			}
+		}
+		resolved := i.resolveDefaults(ctx, renderSpec.GeneratorName, genSpec, others)
+		if !resolved.Success {
+			return &api.DefaultComparisonResponse{Errors: resolved.Errors, Warnings: warnings}
+		}
+		compared := api.ComparedParameter{Value: value, Default: resolved.Parameters[name].Value}
+
+		if _, err := i.validateParameterValue(ctx, name, varSpec, value); err != nil {
+			compared.Status = api.ParameterStatusNoLongerValid
+			compared.Reason = redactHiddenValues(withHiddenValues(ctx, genSpec), parameters, err.Error())
+		} else if varSpec.HasDefault() && sameValue(value, compared.Default) {
+			compared.Status = api.ParameterStatusMatchesDefault
+		} else {
+			compared.Status = api.ParameterStatusCustomized
+		}
+		result.Parameters[name] = compared
+	}
+	return result
+}
+
+// sameValue compares two values in their yaml form, which has sorted map keys, so maps with interface{} keys
+// as read from a render spec compare equal to maps with string keys as obtained from a generator spec
+func sameValue(a interface{}, b interface{}) bool {
+	aYaml, errA := yaml.Marshal(a)
+	bYaml, errB := yaml.Marshal(b)
+	if errA != nil || errB != nil {
+		return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	}
+	return string(aYaml) == string(bYaml)
+}
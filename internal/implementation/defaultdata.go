@@ -0,0 +1,60 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+const generatorNameDefaultValueName = "generatorName"
+
+// default values are evaluated deep down while constructing the parameters, but what they can refer to is known
+// for the whole request
+type defaultValueDataKey struct{}
+
+func withDefaultValueData(ctx context.Context, name string, value interface{}) context.Context {
+	data := map[string]interface{}{}
+	for k, v := range defaultValueData(ctx) {
+		data[k] = v
+	}
+	data[name] = value
+	return context.WithValue(ctx, defaultValueDataKey{}, data)
+}
+
+// defaultValueData is the data default value templates are executed with. It is never nil.
+func defaultValueData(ctx context.Context) map[string]interface{} {
+	if data, ok := ctx.Value(defaultValueDataKey{}).(map[string]interface{}); ok {
+		return data
+	}
+	return map[string]interface{}{}
+}
+
+// the build context is computed once per request, so default values and templates see the same timestamp
+type buildContextKey struct{}
+
+// withBuildContextIfRequested computes the build context if the request asks for it, and makes it available
+// to default values, except in safe defaults mode, because it depends on the host and the time.
+func (i *GeneratorImpl) withBuildContextIfRequested(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec) (context.Context, error) {
+	if !request.IncludeBuildContext {
+		return ctx, nil
+	}
+	if _, ok := genSpec.Variables[buildContextParameterName]; ok {
+		return ctx, fmt.Errorf("variable name '%s' is reserved for the build context, cannot include it", buildContextParameterName)
+	}
+	buildContext, err := i.buildContext(ctx, request)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = context.WithValue(ctx, buildContextKey{}, buildContext)
+	if !isSafeDefaults(ctx) {
+		ctx = withDefaultValueData(ctx, buildContextParameterName, buildContext)
+	}
+	return ctx, nil
+}
+
+// addBuildContext makes the build context available to all templates as .build, if it was requested
+func addBuildContext(ctx context.Context, parameters map[string]interface{}) {
+	if buildContext, ok := ctx.Value(buildContextKey{}).(map[string]interface{}); ok {
+		parameters[buildContextParameterName] = buildContext
+	}
+}
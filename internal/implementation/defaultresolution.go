@@ -0,0 +1,64 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"strings"
+)
+
+func (i *GeneratorImpl) ResolveDefaults(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.DefaultResolutionResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return &api.DefaultResolutionResponse{Errors: []error{err}}
+	}
+	return i.resolveDefaults(ctx, generatorName, genSpec, parameters)
+}
+
+func (i *GeneratorImpl) resolveDefaults(ctx context.Context, generatorName string, genSpec *api.GeneratorSpec, parameters map[string]interface{}) *api.DefaultResolutionResponse {
+	given, warnings := i.resolveParameterAliases(ctx, genSpec, parameters)
+	ctx = withDefaultValueData(ctx, generatorNameDefaultValueName, generatorName)
+	withConditional, err := i.applyConditionalDefaults(ctx, genSpec, given)
+	if err != nil {
+		return &api.DefaultResolutionResponse{Errors: []error{err}, Warnings: warnings}
+	}
+
+	// conditional defaults that matched are now present, so they are taken as is
+	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, withConditional, noPlaceholders)
+	if err != nil {
+		return &api.DefaultResolutionResponse{Errors: []error{err}, Warnings: warnings}
+	}
+
+	result := &api.DefaultResolutionResponse{
+		Success:    true,
+		Parameters: map[string]api.ResolvedParameter{},
+		Warnings:   warnings,
+	}
+	for _, varName := range sortedVariableNames(genSpec) {
+		result.Parameters[varName] = api.ResolvedParameter{
+			Value:  renderSpec.Parameters[varName],
+			Source: parameterSource(genSpec.Variables[varName], given[varName], withConditional[varName], renderSpec.Parameters[varName]),
+		}
+	}
+	return result
+}
+
+// parameterSource classifies where a value came from, following the same precedence as constructRenderSpecWithValuesOrDefaults
+func parameterSource(varSpec api.VariableSpec, given interface{}, withConditional interface{}, resolved interface{}) string {
+	if given != nil {
+		return api.ParameterSourceExplicit
+	}
+	if withConditional != nil {
+		return api.ParameterSourceComputed
+	}
+	if resolved == nil {
+		return api.ParameterSourceNil
+	}
+	if defaultStr, ok := varSpec.DefaultValue.(string); ok && strings.Contains(defaultStr, "{{") {
+		return api.ParameterSourceDefaultTemplate
+	}
+	return api.ParameterSourceDefaultLiteral
+}
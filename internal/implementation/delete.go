@@ -0,0 +1,66 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"strings"
+)
+
+func checkTemplateAction(tplSpec *api.TemplateSpec) error {
+	switch tplSpec.Action {
+	case "", api.TemplateActionRender, api.TemplateActionDelete:
+		return nil
+	default:
+		return fmt.Errorf("template for target %s has unknown action '%s', must be one of %s, %s (this is an error in the generator spec)",
+			tplSpec.RelativeTargetPath, tplSpec.Action, api.TemplateActionRender, api.TemplateActionDelete)
+	}
+}
+
+func isDeleteAction(tplSpec *api.TemplateSpec) bool {
+	return tplSpec.Action == api.TemplateActionDelete
+}
+
+// deleteTemplateName is the base for the internal template names of a delete template, which has no source to name it after
+func deleteTemplateName(tplSpec *api.TemplateSpec) string {
+	return "__delete_" + strings.ReplaceAll(tplSpec.RelativeTargetPath, "/", "_")
+}
+
+// deleteSingleTemplate works like renderSingleTemplate for a template with the delete action, but instead of
+// rendering anything, it deletes the file at the target path of each iteration whose condition is true.
+func (i *GeneratorImpl) deleteSingleTemplate(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, targetDir *targetdir.TargetDirectory) ([]api.FileResult, bool) {
+	if len(tplSpec.WithItems) > 0 && len(tplSpec.WithEntries) > 0 {
+		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("template for target %s must not specify both with_items and with_entries", tplSpec.RelativeTargetPath))}, false
+	}
+
+	templateName := deleteTemplateName(tplSpec)
+	deletedFiles := []api.FileResult{}
+	allSuccessful := true
+	forEachIteration(ctx, tplSpec, parameters, func(parameters map[string]interface{}, templateNameExtension string, errorMessageItemExtension string) {
+		ctx, cancel := withFileDeadline(ctx)
+		defer cancel()
+		targetPath, condition, skip, err := i.resolveTarget(ctx, tplSpec, parameters, templateName, templateNameExtension, errorMessageItemExtension, targetDir)
+		if err == nil && condition && !skip {
+			targetPath, err = resolveInsideTargetDir("delete", targetPath)
+		}
+		if err != nil {
+			deletedFiles = append(deletedFiles, i.errorFileResult(ctx, targetPath, err))
+			allSuccessful = false
+		} else if !condition {
+			if shouldReportConditionSkipped(ctx) {
+				deletedFiles = append(deletedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonConditionFalse))
+			}
+		} else if skip {
+			deletedFiles = append(deletedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonTargetExists))
+		} else if existed, err := targetDir.DeleteFile(ctx, targetPath); err != nil {
+			deletedFiles = append(deletedFiles, i.errorFileResult(ctx, targetPath, fmt.Errorf("error deleting target '%s'%s: %s", targetPath, errorMessageItemExtension, err)))
+			allSuccessful = false
+		} else if existed {
+			deletedFiles = append(deletedFiles, i.deletedFileResult(ctx, targetPath))
+		} else {
+			deletedFiles = append(deletedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonNothingToDelete))
+		}
+	})
+	return deletedFiles, allSuccessful
+}
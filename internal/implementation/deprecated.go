@@ -0,0 +1,21 @@
+package implementation
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+// deprecationWarnings lists a warning for every file a deprecated template has written. Files that were
+// skipped or failed are left out, as their users are not affected by the deprecation.
+func deprecationWarnings(tplSpec *api.TemplateSpec, rendered []api.FileResult) []string {
+	if tplSpec.Deprecated == "" {
+		return nil
+	}
+	warnings := []string{}
+	for _, f := range rendered {
+		if f.Success && !f.Skipped && !f.Deleted {
+			warnings = append(warnings, fmt.Sprintf("target '%s' is rendered from a deprecated template: %s", f.RelativeFilePath, tplSpec.Deprecated))
+		}
+	}
+	return warnings
+}
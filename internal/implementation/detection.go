@@ -0,0 +1,51 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"sort"
+)
+
+const detectedParameterName = "detected"
+
+type detectedKey struct{}
+
+// withDetectionIfRequested checks the marker paths the generator declares in its detect section, if any, and makes
+// the results available to default values.
+func (i *GeneratorImpl) withDetectionIfRequested(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec) (context.Context, error) {
+	if len(genSpec.Detect) == 0 {
+		return ctx, nil
+	}
+	if _, ok := genSpec.Variables[detectedParameterName]; ok {
+		return ctx, fmt.Errorf("variable name '%s' is reserved for the detect section, cannot detect anything (this is an error in the generator spec)", detectedParameterName)
+	}
+
+	names := make([]string, 0, len(genSpec.Detect))
+	for name := range genSpec.Detect {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+	detected := make(map[string]interface{}, len(names))
+	for _, name := range names {
+		markerPath, err := resolveInsideTargetDir("detect", genSpec.Detect[name])
+		if err != nil {
+			return ctx, fmt.Errorf("detect entry %s is invalid (this is an error in the generator spec): %s", name, err)
+		}
+		detected[name] = targetDir.Exists(ctx, markerPath)
+	}
+
+	ctx = context.WithValue(ctx, detectedKey{}, detected)
+	ctx = withDefaultValueData(ctx, detectedParameterName, detected)
+	return ctx, nil
+}
+
+// addDetected makes the detection results available to all templates as .detected, if the generator declares any
+func addDetected(ctx context.Context, parameters map[string]interface{}) {
+	if detected, ok := ctx.Value(detectedKey{}).(map[string]interface{}); ok {
+		parameters[detectedParameterName] = detected
+	}
+}
@@ -0,0 +1,45 @@
+package implementation
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"time"
+)
+
+// decodeDurationValue parses a duration given as a string, and checks it against the bounds of the variable.
+// A value that was decoded before is accepted as is, so decoding twice does no harm.
+func decodeDurationValue(varName string, varSpec api.VariableSpec, val interface{}) (interface{}, error) {
+	var duration time.Duration
+	switch typed := val.(type) {
+	case time.Duration:
+		duration = typed
+	case string:
+		parsed, err := time.ParseDuration(typed)
+		if err != nil {
+			return nil, fmt.Errorf("value for parameter '%s' must be a duration such as 30s or 1h30m: %s", varName, err.Error())
+		}
+		duration = parsed
+	default:
+		return nil, fmt.Errorf("value for parameter '%s' must be a duration such as 30s or 1h30m, but is %T", varName, val)
+	}
+
+	if varSpec.MinDuration != "" {
+		min, err := time.ParseDuration(varSpec.MinDuration)
+		if err != nil {
+			return nil, fmt.Errorf("variable declaration %s has invalid min_duration (this is an error in the generator spec, not the render request): %s", varName, err.Error())
+		}
+		if duration < min {
+			return nil, fmt.Errorf("value for parameter '%s' must be at least %s, but is %s", varName, min, duration)
+		}
+	}
+	if varSpec.MaxDuration != "" {
+		max, err := time.ParseDuration(varSpec.MaxDuration)
+		if err != nil {
+			return nil, fmt.Errorf("variable declaration %s has invalid max_duration (this is an error in the generator spec, not the render request): %s", varName, err.Error())
+		}
+		if duration > max {
+			return nil, fmt.Errorf("value for parameter '%s' must be at most %s, but is %s", varName, max, duration)
+		}
+	}
+	return duration, nil
+}
@@ -0,0 +1,71 @@
+package implementation
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"strings"
+	"unicode/utf8"
+)
+
+// encodingAliases are names accepted for an encoding that the IANA registry does not know
+var encodingAliases = map[string]string{
+	"utf8":      "UTF-8",
+	"iso8859-1": "ISO-8859-1",
+	"ascii":     "US-ASCII",
+}
+
+// encodeOutput transcodes rendered UTF-8 output to the encoding requested for the template.
+//
+// Encodings are looked up by their IANA name or alias, see ianaindex.
+func encodeOutput(encodingName string, writeBOM bool, output []byte) ([]byte, error) {
+	enc, err := lookupEncoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+	if enc == unicode.UTF8 {
+		return output, nil
+	}
+	if writeBOM {
+		return nil, fmt.Errorf("write_bom cannot be combined with encoding %s (this is an error in the generator spec)", encodingName)
+	}
+
+	// check each rune first, so errors can name the character and line
+	line := 1
+	for pos := 0; pos < len(output); {
+		r, size := utf8.DecodeRune(output[pos:])
+		if r == utf8.RuneError && size == 1 {
+			return nil, fmt.Errorf("rendered output is not valid UTF-8 in line %d", line)
+		}
+		if _, err := enc.NewEncoder().Bytes(output[pos : pos+size]); err != nil {
+			return nil, fmt.Errorf("character '%c' (U+%04X) in line %d cannot be represented in encoding %s", r, r, line, encodingName)
+		}
+		if r == '\n' {
+			line++
+		}
+		pos += size
+	}
+
+	// transcode in one go, stateful encodings depend on what came before
+	result, err := enc.NewEncoder().Bytes(output)
+	if err != nil {
+		return nil, fmt.Errorf("rendered output cannot be transcoded to encoding %s: %s", encodingName, err)
+	}
+	return result, nil
+}
+
+func lookupEncoding(encodingName string) (encoding.Encoding, error) {
+	if encodingName == "" {
+		return unicode.UTF8, nil
+	}
+	name := encodingName
+	if alias, ok := encodingAliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		return nil, fmt.Errorf("unsupported encoding %s (this is an error in the generator spec)", encodingName)
+	}
+	return enc, nil
+}
@@ -0,0 +1,46 @@
+package implementation
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEncodeOutput_ShouldTranscodeAnyIanaEncoding(t *testing.T) {
+	actual, err := encodeOutput("windows-1252", false, []byte("5 €\n"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("5 \x80\n"), actual)
+
+	actual, err = encodeOutput("Shift_JIS", false, []byte("日本"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("\x93\xfa\x96\x7b"), actual)
+}
+
+func TestEncodeOutput_ShouldAcceptAliases(t *testing.T) {
+	for _, name := range []string{"", "utf8", "UTF-8"} {
+		actual, err := encodeOutput(name, false, []byte("Köln"))
+		require.Nil(t, err, name)
+		require.Equal(t, []byte("Köln"), actual, name)
+	}
+	for _, name := range []string{"latin1", "iso8859-1", "ISO-8859-1"} {
+		actual, err := encodeOutput(name, false, []byte("Köln"))
+		require.Nil(t, err, name)
+		require.Equal(t, []byte("K\xf6ln"), actual, name)
+	}
+	actual, err := encodeOutput("ascii", false, []byte("Koeln"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("Koeln"), actual)
+}
+
+func TestEncodeOutput_ShouldReportErrors(t *testing.T) {
+	_, err := encodeOutput("klingon", false, []byte("Qapla'"))
+	require.EqualError(t, err, "unsupported encoding klingon (this is an error in the generator spec)")
+
+	_, err = encodeOutput("latin1", true, []byte("K\xc3\xb6ln"))
+	require.EqualError(t, err, "write_bom cannot be combined with encoding latin1 (this is an error in the generator spec)")
+
+	_, err = encodeOutput("latin1", false, []byte("line 1\nK\xf6ln"))
+	require.EqualError(t, err, "rendered output is not valid UTF-8 in line 2")
+
+	_, err = encodeOutput("latin1", false, []byte("line 1\nline 2\n5 €"))
+	require.EqualError(t, err, "character '€' (U+20AC) in line 3 cannot be represented in encoding latin1")
+}
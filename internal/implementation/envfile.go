@@ -0,0 +1,88 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+func (i *GeneratorImpl) LoadParametersFromEnvFile(_ context.Context, path string) (map[string]interface{}, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading env file %s: %s", path, err)
+	}
+
+	result := make(map[string]interface{})
+	for index, line := range strings.Split(strings.ReplaceAll(string(contents), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		pos := strings.Index(line, "=")
+		if pos < 0 {
+			return nil, fmt.Errorf("invalid line %d in env file %s: must be of the form KEY=value", index+1, path)
+		}
+		key := strings.TrimSpace(line[:pos])
+		if key == "" {
+			return nil, fmt.Errorf("invalid line %d in env file %s: key must not be empty", index+1, path)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(line[pos+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d in env file %s: %s", index+1, path, err)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseEnvValue handles the value part of a dotenv line.
+//
+// Single quoted values are taken literally, double quoted values support the escapes \n, \t, \" and \\.
+// Unquoted values end at a comment introduced by whitespace and #.
+func parseEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	quote := raw[0]
+	if quote != '"' && quote != '\'' {
+		for pos := 1; pos < len(raw); pos++ {
+			if raw[pos] == '#' && (raw[pos-1] == ' ' || raw[pos-1] == '\t') {
+				return strings.TrimSpace(raw[:pos]), nil
+			}
+		}
+		return raw, nil
+	}
+
+	var value strings.Builder
+	for pos := 1; pos < len(raw); pos++ {
+		c := raw[pos]
+		if c == quote {
+			rest := strings.TrimSpace(raw[pos+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected characters after closing quote: %s", rest)
+			}
+			return value.String(), nil
+		}
+		if c == '\\' && quote == '"' && pos+1 < len(raw) {
+			pos++
+			switch raw[pos] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\':
+				value.WriteByte(raw[pos])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(raw[pos])
+			}
+			continue
+		}
+		value.WriteByte(c)
+	}
+	return "", fmt.Errorf("missing closing quote %c", quote)
+}
@@ -0,0 +1,26 @@
+package implementation
+
+import (
+	"context"
+	"text/template"
+)
+
+type extraFuncsKey struct{}
+
+// WithExtraFuncs returns a context that makes funcs available to all templates rendered with it,
+// in addition to the sprig functions. Functions of the same name replace the sprig ones.
+func WithExtraFuncs(ctx context.Context, funcs template.FuncMap) context.Context {
+	merged := template.FuncMap{}
+	for name, f := range extraFuncs(ctx) {
+		merged[name] = f
+	}
+	for name, f := range funcs {
+		merged[name] = f
+	}
+	return context.WithValue(ctx, extraFuncsKey{}, merged)
+}
+
+func extraFuncs(ctx context.Context) template.FuncMap {
+	funcs, _ := ctx.Value(extraFuncsKey{}).(template.FuncMap)
+	return funcs
+}
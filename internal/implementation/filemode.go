@@ -0,0 +1,31 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type preserveExistingModeKey struct{}
+
+func withPreserveExistingMode(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preserveExistingModeKey{}, true)
+}
+
+func shouldPreserveExistingMode(ctx context.Context) bool {
+	preserve, _ := ctx.Value(preserveExistingModeKey{}).(bool)
+	return preserve
+}
+
+// parseFileMode parses the octal mode of a template. The empty string gives 0, meaning no mode is set.
+func parseFileMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || parsed == 0 || parsed > 0777 {
+		return 0, fmt.Errorf("invalid mode '%s', must be octal file permissions such as 0644 or 0755", mode)
+	}
+	return os.FileMode(parsed), nil
+}
@@ -0,0 +1,39 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+type ensureFinalNewlineKey struct{}
+
+func withEnsureFinalNewline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ensureFinalNewlineKey{}, true)
+}
+
+// shouldEnsureFinalNewline applies the setting of the template if it has one, else the one of the request
+func shouldEnsureFinalNewline(ctx context.Context, tplSpec *api.TemplateSpec) bool {
+	if tplSpec.JustCopy {
+		return false
+	}
+	if tplSpec.EnsureFinalNewline != nil {
+		return *tplSpec.EnsureFinalNewline
+	}
+	ensure, _ := ctx.Value(ensureFinalNewlineKey{}).(bool)
+	return ensure
+}
+
+// ensureFinalNewline makes output end in exactly one newline. Output using windows line endings gets one, too.
+func ensureFinalNewline(output []byte) []byte {
+	if len(output) == 0 {
+		return output
+	}
+	newline := []byte("\n")
+	if bytes.Contains(output, []byte("\r\n")) {
+		newline = []byte("\r\n")
+	}
+	trimmed := bytes.TrimRight(output, "\r\n")
+	result := make([]byte, 0, len(trimmed)+len(newline))
+	return append(append(result, trimmed...), newline...)
+}
@@ -0,0 +1,14 @@
+package implementation
+
+import "context"
+
+type gitignoreAwareKey struct{}
+
+func withGitignoreAware(ctx context.Context) context.Context {
+	return context.WithValue(ctx, gitignoreAwareKey{}, true)
+}
+
+func isGitignoreAware(ctx context.Context) bool {
+	aware, _ := ctx.Value(gitignoreAwareKey{}).(bool)
+	return aware
+}
@@ -0,0 +1,59 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"sort"
+)
+
+func hasGroupCondition(genSpec *api.GeneratorSpec, varSpec api.VariableSpec) bool {
+	if varSpec.Group == "" {
+		return false
+	}
+	_, ok := genSpec.GroupConditions[varSpec.Group]
+	return ok
+}
+
+// addConditionalGroupParameters evaluates the group conditions against the parameters that are not in a conditional
+// group, then adds the parameters of each group, validated if the group is enabled, and nil if it is not.
+func (i *GeneratorImpl) addConditionalGroupParameters(ctx context.Context, genSpec *api.GeneratorSpec, renderSpecParameters map[string]interface{}, parameters map[string]interface{}) error {
+	if len(genSpec.GroupConditions) == 0 {
+		return nil
+	}
+
+	enabledGroups := map[string]bool{}
+	for _, group := range sortedGroupNames(genSpec) {
+		enabled, err := i.evaluateCondition(ctx, genSpec.GroupConditions[group], parameters, "__groupcondition_"+group)
+		if err != nil {
+			return fmt.Errorf("group %s has invalid condition (this is an error in the generator spec): %s", group, err.Error())
+		}
+		enabledGroups[group] = enabled
+	}
+
+	for _, varName := range sortedVariableNames(genSpec) {
+		varSpec := genSpec.Variables[varName]
+		if !hasGroupCondition(genSpec, varSpec) {
+			continue
+		}
+		if !enabledGroups[varSpec.Group] {
+			parameters[varName] = nil
+			continue
+		}
+		val, err := i.constructAndValidateParameter(ctx, varName, varSpec, renderSpecParameters)
+		if err != nil {
+			return err
+		}
+		parameters[varName] = val
+	}
+	return nil
+}
+
+func sortedGroupNames(genSpec *api.GeneratorSpec) []string {
+	names := make([]string, 0, len(genSpec.GroupConditions))
+	for k := range genSpec.GroupConditions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
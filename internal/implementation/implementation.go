@@ -5,12 +5,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/Masterminds/sprig"
 	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
 	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/glob"
 	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
-	"regexp"
+	"io"
+	"reflect"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"text/template"
 )
@@ -19,16 +23,74 @@ type GeneratorImpl struct {
 }
 
 func (i *GeneratorImpl) FindGeneratorNames(ctx context.Context, sourceBaseDir string) ([]string, error) {
-	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+	return i.FindGeneratorNamesWithOptions(ctx, sourceBaseDir, api.DiscoveryOptions{})
+}
+
+func (i *GeneratorImpl) FindGeneratorNamesWithOptions(ctx context.Context, sourceBaseDir string, options api.DiscoveryOptions) ([]string, error) {
+	sourceDir := generatordir.InstanceWithDiscoveryOptions(ctx, sourceBaseDir, options)
 	return sourceDir.FindGeneratorNames(ctx)
 }
 
 func (i *GeneratorImpl) ObtainGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) (*api.GeneratorSpec, error) {
-	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+	return i.ObtainGeneratorSpecWithOptions(ctx, sourceBaseDir, generatorName, api.DiscoveryOptions{})
+}
+
+func (i *GeneratorImpl) ObtainGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.DiscoveryOptions) (*api.GeneratorSpec, error) {
+	sourceDir := generatordir.InstanceWithDiscoveryOptions(ctx, sourceBaseDir, options)
 	return sourceDir.ObtainGeneratorSpec(ctx, generatorName)
 }
 
+func (i *GeneratorImpl) ReadGeneratorSpecRaw(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, string, error) {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+	return sourceDir.ReadGeneratorSpecRaw(ctx, generatorName)
+}
+
+func (i *GeneratorImpl) ValidateGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) []error {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return []error{err}
+	}
+
+	errs := i.validateDefaultValues(withDefaultValueData(ctx, generatorNameDefaultValueName, generatorName), genSpec)
+	return append(errs, i.validateTemplates(ctx, genSpec, sourceDir)...)
+}
+
+func (i *GeneratorImpl) ValidateGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.SpecValidationOptions) *api.SpecValidationResponse {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return &api.SpecValidationResponse{Errors: []error{err}, Warnings: []string{}}
+	}
+
+	result := &api.SpecValidationResponse{
+		Errors:   i.validateDefaultValues(withDefaultValueData(ctx, generatorNameDefaultValueName, generatorName), genSpec),
+		Warnings: []string{},
+	}
+	result.Errors = append(result.Errors, i.validateTemplates(ctx, genSpec, sourceDir)...)
+	for _, warning := range iterationTargetWarnings(genSpec) {
+		if options.Strict {
+			result.Errors = append(result.Errors, errors.New(warning))
+		} else {
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+	result.Success = len(result.Errors) == 0
+	return result
+}
+
 func (i *GeneratorImpl) WriteRenderSpecWithDefaults(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	return i.writeRenderSpecWithDefaults(ctx, request, generatorName, i.renderSpecFileSink(request.RenderSpecFile))
+}
+
+func (i *GeneratorImpl) WriteRenderSpecWithDefaultsTo(ctx context.Context, request *api.Request, generatorName string, w io.Writer) *api.Response {
+	return i.writeRenderSpecWithDefaults(ctx, request, generatorName, i.renderSpecWriterSink(w))
+}
+
+func (i *GeneratorImpl) writeRenderSpecWithDefaults(ctx context.Context, request *api.Request, generatorName string, sink renderSpecSink) *api.Response {
+	ctx = withRequestOptions(ctx, request)
 	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
 	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
 
@@ -37,9 +99,9 @@ func (i *GeneratorImpl) WriteRenderSpecWithDefaults(ctx context.Context, request
 		return i.errorResponseToplevel(ctx, err)
 	}
 
-	// for missing default values, default to the empty string rather than nil
-	// this makes the spec entry be an empty string, resulting in a valid render spec
-	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, map[string]interface{}{}, "")
+	// for missing default values, default to a placeholder or the empty string rather than nil
+	// this makes the spec entry be a string, resulting in a valid render spec
+	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, map[string]interface{}{}, placeholders(request))
 	if err != nil {
 		return i.errorResponseToplevel(ctx, err)
 	}
@@ -47,14 +109,23 @@ func (i *GeneratorImpl) WriteRenderSpecWithDefaults(ctx context.Context, request
 	// no validation here because the defaults may be empty or may intentionally not match the validation rule
 	// (might be something like 'put in your fqdn name here')
 
-	targetFile, err := targetDir.WriteRenderSpec(ctx, renderSpec, request.RenderSpecFile)
+	fileResults, err := sink(ctx, targetDir, renderSpec, i.parameterGroups(genSpec))
 	if err != nil {
 		return i.errorResponseToplevel(ctx, err)
 	}
-	return i.successResponse(ctx, []api.FileResult{i.successFileResult(ctx, targetFile)})
+	return i.successResponse(ctx, fileResults)
 }
 
 func (i *GeneratorImpl) WriteRenderSpecWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	return i.writeRenderSpecWithValues(ctx, request, generatorName, parameters, i.renderSpecFileSink(request.RenderSpecFile))
+}
+
+func (i *GeneratorImpl) WriteRenderSpecWithValuesTo(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}, w io.Writer) *api.Response {
+	return i.writeRenderSpecWithValues(ctx, request, generatorName, parameters, i.renderSpecWriterSink(w))
+}
+
+func (i *GeneratorImpl) writeRenderSpecWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}, sink renderSpecSink) *api.Response {
+	ctx = withRequestOptions(ctx, request)
 	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
 	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
 
@@ -63,33 +134,82 @@ func (i *GeneratorImpl) WriteRenderSpecWithValues(ctx context.Context, request *
 		return i.errorResponseToplevel(ctx, err)
 	}
 
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, parameters)
+
 	// when the user is providing a set of values for the parameter, we want missing parameter values to be reported as missing
 	// therefore, actually set the nilDefault to nil
-	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, parameters, nil)
+	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, parameters, noPlaceholders)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+
+	_, _, err = i.constructAndValidateParameterMap(ctx, genSpec, renderSpec)
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+
+	err = i.checkNoExtraneousParameters(ctx, genSpec, parameters)
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+
+	if request.OmitDefaults {
+		renderSpec.Parameters, err = i.omitDefaultParameters(ctx, request, generatorName, genSpec, renderSpec.Parameters)
+		if err != nil {
+			return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+		}
+	}
+
+	fileResults, err := sink(ctx, targetDir, renderSpec, i.parameterGroups(genSpec))
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+	return i.withWarnings(i.successResponse(ctx, fileResults), warnings)
+}
+
+func (i *GeneratorImpl) NormalizeRenderSpec(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpecFile := targetDir.RenderSpecFilenameOrDefaultForGenerator(ctx, request.RenderSpecFile, generatorName)
+	existingRenderSpec, err := targetDir.ObtainRenderSpec(ctx, renderSpecFile)
 	if err != nil {
 		return i.errorResponseToplevel(ctx, err)
 	}
+	if existingRenderSpec.GeneratorName != generatorName {
+		return i.errorResponseToplevel(ctx, fmt.Errorf("render spec file %s is for generator '%s', not '%s'", renderSpecFile, existingRenderSpec.GeneratorName, generatorName))
+	}
 
-	_, err = i.constructAndValidateParameterMap(ctx, genSpec, renderSpec)
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
 	if err != nil {
 		return i.errorResponseToplevel(ctx, err)
 	}
 
-	// check for extraneous parameters
-	for k := range parameters {
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, existingRenderSpec.Parameters)
+	for _, k := range sortedParameterNames(parameters) {
 		if _, ok := genSpec.Variables[k]; !ok {
-			return i.errorResponseToplevel(ctx, fmt.Errorf("parameter '%s' is not allowed according to generator spec", k))
+			warnings = append(warnings, fmt.Sprintf("parameter '%s' is not allowed according to generator spec, dropping it", k))
+			delete(parameters, k)
 		}
 	}
 
-	targetFile, err := targetDir.WriteRenderSpec(ctx, renderSpec, request.RenderSpecFile)
+	// fill in defaults for parameters that were added to the generator spec, just like WriteRenderSpecWithDefaults,
+	// and no validation for the same reasons
+	renderSpec, err := i.constructRenderSpecWithValuesOrDefaults(ctx, generatorName, genSpec, parameters, placeholders(request))
 	if err != nil {
-		return i.errorResponseToplevel(ctx, err)
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
 	}
-	return i.successResponse(ctx, []api.FileResult{i.successFileResult(ctx, targetFile)})
+
+	targetFile, err := targetDir.WriteRenderSpec(ctx, renderSpec, i.parameterGroups(genSpec), renderSpecFile)
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+	return i.withWarnings(i.successResponse(ctx, []api.FileResult{i.successFileResult(ctx, targetFile)}), warnings)
 }
 
 func (i *GeneratorImpl) Render(ctx context.Context, request *api.Request) *api.Response {
+	ctx = withRequestOptions(ctx, request)
 	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
 	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
 
@@ -103,35 +223,309 @@ func (i *GeneratorImpl) Render(ctx context.Context, request *api.Request) *api.R
 		return i.errorResponseToplevel(ctx, err)
 	}
 
-	parameters, err := i.constructAndValidateParameterMap(ctx, genSpec, renderSpec)
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+
+	ctx = withParameterSource(ctx, "render spec file "+targetDir.RenderSpecFilenameOrDefault(ctx, request.RenderSpecFile))
+	return i.renderWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, targetDir)
+}
+
+func (i *GeneratorImpl) RenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
 	if err != nil {
 		return i.errorResponseToplevel(ctx, err)
 	}
 
-	renderedFiles, allSuccessful := i.renderAllTemplates(ctx, genSpec, parameters, sourceDir, targetDir)
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, parameters)
+
+	err = i.checkNoExtraneousParameters(ctx, genSpec, parameters)
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+
+	renderSpec := &api.RenderSpec{
+		GeneratorName: generatorName,
+		Parameters:    parameters,
+	}
+	return i.withWarnings(i.renderWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, targetDir), warnings)
+}
+
+func (i *GeneratorImpl) RenderWithStruct(ctx context.Context, request *api.Request, generatorName string, parameters interface{}) *api.Response {
+	parameterMap, err := structToParameterMap(parameters)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+	return i.RenderWithValues(ctx, request, generatorName, parameterMap)
+}
+
+func (i *GeneratorImpl) RenderExpression(ctx context.Context, request *api.Request, generatorName string, expression string) (string, error) {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpecFile := targetDir.RenderSpecFilenameOrDefaultForGenerator(ctx, request.RenderSpecFile, generatorName)
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, renderSpecFile)
+	if err != nil {
+		return "", err
+	}
+	if renderSpec.GeneratorName != generatorName {
+		return "", fmt.Errorf("render spec file %s is for generator '%s', not '%s'", renderSpecFile, renderSpec.GeneratorName, generatorName)
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return "", err
+	}
+
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, _, parameters, _, err := i.resolveParameters(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := i.renderString(ctx, parameters, "__expression", expression)
+	if err != nil {
+		return "", fmt.Errorf("error evaluating expression: %s", err.Error())
+	}
+	return result, nil
+}
+
+func (i *GeneratorImpl) BatchRender(ctx context.Context, requests []*api.Request) *api.BatchResponse {
+	result := &api.BatchResponse{
+		Success:   true,
+		Responses: []*api.Response{},
+		Errors:    []error{},
+	}
+	for counter, request := range requests {
+		response := i.Render(ctx, request)
+		result.Responses = append(result.Responses, response)
+		result.Success = result.Success && response.Success
+		for _, err := range response.Errors {
+			result.Errors = append(result.Errors, fmt.Errorf("request #%d: %s", counter+1, err.Error()))
+		}
+		for _, warning := range response.Warnings {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("request #%d: %s", counter+1, warning))
+		}
+	}
+	return result
+}
+
+// helper functions
+
+func (i *GeneratorImpl) renderWithRenderSpec(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) *api.Response {
+	ctx, genSpec, parameters, warnings, err := i.resolveParameters(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+
+	var resolvedSpec *api.RenderSpec
+	if request.IncludeResolvedSpec {
+		// take a copy now, because rendering adds item variables to the parameters
+		resolvedSpec = i.resolvedSpec(genSpec, renderSpec, parameters)
+	}
+
+	if request.ParameterHook != nil {
+		hooked, err := request.ParameterHook(parameters)
+		if err != nil {
+			return i.withWarnings(i.errorResponseToplevel(ctx, fmt.Errorf("parameter hook failed: %s", err.Error())), warnings)
+		}
+		if hooked != nil {
+			parameters = hooked
+		}
+	}
+
+	if err := i.checkRequiredTargetFiles(ctx, genSpec, parameters, targetDir); err != nil {
+		return i.withWarnings(i.errorResponseToplevel(ctx, err), warnings)
+	}
+
+	if errs := i.preflight(ctx, request, genSpec, parameters, sourceDir, targetDir); len(errs) > 0 {
+		return i.withWarnings(&api.Response{Errors: errs}, warnings)
+	}
+
+	renderedFiles, allSuccessful, renderWarnings := i.renderAllTemplates(ctx, request, genSpec, parameters, sourceDir, targetDir)
+	warnings = append(warnings, renderWarnings...)
+	var response *api.Response
 	if allSuccessful {
-		return i.successResponse(ctx, renderedFiles)
+		response = i.successResponse(ctx, renderedFiles)
 	} else {
-		return i.errorResponseRender(ctx, renderedFiles)
+		response = i.errorResponseRender(ctx, renderedFiles)
 	}
+	response.ResolvedSpec = resolvedSpec
+	return i.withWarnings(response, warnings)
 }
 
-// helper functions
+// resolveParameters constructs and validates the parameters for a render spec, and adds the reserved parameters
+// the request asks for, such as the build context or the detected markers. The returned context and generator spec
+// must be used from then on, because a profile may change the generator spec.
+func (i *GeneratorImpl) resolveParameters(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec) (context.Context, *api.GeneratorSpec, map[string]interface{}, []string, error) {
+	ctx, err := i.withBuildContextIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return ctx, genSpec, nil, nil, err
+	}
+	ctx, genSpec, err = i.withProfileIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return ctx, genSpec, nil, nil, err
+	}
+	ctx, err = i.withTargetDirIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return ctx, genSpec, nil, nil, err
+	}
+	ctx, err = i.withDetectionIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return ctx, genSpec, nil, nil, err
+	}
+	ctx, err = i.withProvidedIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return ctx, genSpec, nil, nil, err
+	}
+
+	parameters, warnings, err := i.constructAndValidateParameterMap(ctx, genSpec, renderSpec)
+	if err != nil {
+		return ctx, genSpec, nil, warnings, err
+	}
+	addBuildContext(ctx, parameters)
+	addProfile(ctx, parameters)
+	addTargetDir(ctx, parameters)
+	addDetected(ctx, parameters)
+	i.addProvided(ctx, genSpec, renderSpec, parameters)
+	return ctx, genSpec, parameters, warnings, nil
+}
+
+// resolvedSpec turns the parameters into a render spec, leaving out reserved parameters such as the build context. Nested maps and lists are copied, so the parameter hook
+// cannot change it. The values of sensitive and bytes variables are redacted, so it can be shown or persisted.
+func (i *GeneratorImpl) resolvedSpec(genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec, parameters map[string]interface{}) *api.RenderSpec {
+	resolved := &api.RenderSpec{
+		Version:       api.CurrentRenderSpecVersion,
+		GeneratorName: renderSpec.GeneratorName,
+		Parameters:    make(map[string]interface{}, len(parameters)),
+	}
+	for k, v := range parameters {
+		if _, declared := genSpec.Variables[k]; !declared {
+			continue
+		}
+		if isHiddenVariable(genSpec.Variables[k]) && v != nil {
+			resolved.Parameters[k] = redactedValue
+			continue
+		}
+		resolved.Parameters[k] = deepCopyValue(i.encodeTypedValue(genSpec.Variables[k], v))
+	}
+	return resolved
+}
+
+// deepCopyValue copies maps and slices recursively, other values are returned as they are
+func deepCopyValue(val interface{}) interface{} {
+	original := reflect.ValueOf(val)
+	switch original.Kind() {
+	case reflect.Map:
+		if original.IsNil() {
+			return val
+		}
+		result := reflect.MakeMapWithSize(original.Type(), original.Len())
+		iter := original.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), deepCopyElement(iter.Value()))
+		}
+		return result.Interface()
+	case reflect.Slice:
+		if original.IsNil() {
+			return val
+		}
+		result := reflect.MakeSlice(original.Type(), original.Len(), original.Len())
+		for index := 0; index < original.Len(); index++ {
+			result.Index(index).Set(deepCopyElement(original.Index(index)))
+		}
+		return result.Interface()
+	default:
+		return val
+	}
+}
+
+func deepCopyElement(element reflect.Value) reflect.Value {
+	if copied := deepCopyValue(element.Interface()); copied != nil {
+		return reflect.ValueOf(copied)
+	}
+	// a nil element of an interface{} map or slice
+	return reflect.Zero(element.Type())
+}
+
+func (i *GeneratorImpl) parameterGroups(genSpec *api.GeneratorSpec) map[string]string {
+	groups := map[string]string{}
+	for k, v := range genSpec.Variables {
+		if v.Group != "" {
+			groups[k] = v.Group
+		}
+	}
+	return groups
+}
+
+// resolveParameterAliases returns a copy of parameters where values given under one of a variable's aliases
+// are moved to the variable's actual name. If both are given, the actual name wins.
+//
+// Any use of an alias results in a deprecation warning.
+func (i *GeneratorImpl) resolveParameterAliases(_ context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}) (map[string]interface{}, []string) {
+	var warnings []string
+	result := make(map[string]interface{})
+	for k, v := range parameters {
+		result[k] = v
+	}
+	for _, varName := range sortedVariableNames(genSpec) {
+		for _, alias := range genSpec.Variables[varName].Aliases {
+			val, ok := result[alias]
+			if !ok {
+				continue
+			}
+			delete(result, alias)
+			if _, exists := result[varName]; exists {
+				warnings = append(warnings, fmt.Sprintf("parameter '%s' is a deprecated alias for '%s', ignoring it because '%s' is also set", alias, varName, varName))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("parameter '%s' is a deprecated alias for '%s', please rename it", alias, varName))
+				result[varName] = val
+			}
+		}
+	}
+	return result, warnings
+}
+
+func (i *GeneratorImpl) checkNoExtraneousParameters(_ context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}) error {
+	for k := range parameters {
+		if _, ok := genSpec.Variables[k]; !ok {
+			return fmt.Errorf("parameter '%s' is not allowed according to generator spec", k)
+		}
+	}
+	return nil
+}
 
-func (i *GeneratorImpl) constructRenderSpecWithValuesOrDefaults(_ context.Context, generatorName string, genSpec *api.GeneratorSpec, parameters map[string]interface{}, nilDefault interface{}) (*api.RenderSpec, error) {
+func (i *GeneratorImpl) constructRenderSpecWithValuesOrDefaults(ctx context.Context, generatorName string, genSpec *api.GeneratorSpec, parameters map[string]interface{}, placeholder func(varSpec api.VariableSpec) interface{}) (*api.RenderSpec, error) {
+	ctx = withDefaultValueData(ctx, generatorNameDefaultValueName, generatorName)
+	parameters, err := i.applyConditionalDefaults(ctx, genSpec, parameters)
+	if err != nil {
+		return nil, err
+	}
 	renderSpec := &api.RenderSpec{
 		GeneratorName: generatorName,
 		Parameters:    map[string]interface{}{},
 	}
-	for k, v := range genSpec.Variables {
+	// in a fixed order, so default values that call seeded random functions get the same results every time
+	for _, k := range sortedVariableNames(genSpec) {
+		v := genSpec.Variables[k]
 		// a fetch on a map missing key will produce the empty value for that type, i.e. nil here
 		renderSpec.Parameters[k] = parameters[k]
 		if renderSpec.Parameters[k] == nil {
-			if v.DefaultValue == nil {
-				renderSpec.Parameters[k] = nilDefault
+			if !v.HasDefault() {
+				renderSpec.Parameters[k] = placeholder(v)
 			} else if defaultStr, ok := v.DefaultValue.(string); ok {
 				// again, the default may be the empty string
-				renderedDefaultValue, err := i.renderStringDefaultFromTemplate(k, defaultStr)
+				renderedDefaultValue, err := i.renderStringDefaultFromTemplate(ctx, k, defaultStr)
 				if err != nil {
 					return nil, err
 				}
@@ -146,158 +540,469 @@ func (i *GeneratorImpl) constructRenderSpecWithValuesOrDefaults(_ context.Contex
 	return renderSpec, nil
 }
 
-func (i *GeneratorImpl) renderStringDefaultFromTemplate(variableName string, defaultStr string) (interface{}, error) {
-	templateName := "__defaultvalue_" + variableName
-	tmpl, err := template.New(templateName).Funcs(sprig.TxtFuncMap()).Parse(defaultStr)
+func (i *GeneratorImpl) renderStringDefaultFromTemplate(ctx context.Context, variableName string, defaultStr string) (interface{}, error) {
+	result, err := executeString(ctx, i.defaultValueFuncs(ctx), "__defaultvalue_"+variableName, defaultStr, defaultValueData(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("variable declaration %s has invalid default (this is an error in the generator spec): %s", variableName, err.Error())
 	}
+	return result, nil
+}
 
-	var buf bytes.Buffer
-	err = tmpl.ExecuteTemplate(&buf, templateName, map[string]interface{}{})
+func (i *GeneratorImpl) constructAndValidateParameterMap(ctx context.Context, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec) (map[string]interface{}, []string, error) {
+	ctx = withDefaultValueData(ctx, generatorNameDefaultValueName, renderSpec.GeneratorName)
+	renderSpecParameters, warnings := i.resolveParameterAliases(ctx, genSpec, renderSpec.Parameters)
+	renderSpecParameters, err := i.applyConditionalDefaults(ctx, genSpec, renderSpecParameters)
 	if err != nil {
-		// unsure if this is reachable. All errors I've been able to produce are found during template parse
-		return nil, fmt.Errorf("variable declaration %s has invalid default (this is an error in the generator spec): %s", variableName, err.Error())
+		return nil, warnings, err
 	}
-
-	return buf.String(), nil
-}
-
-func (i *GeneratorImpl) constructAndValidateParameterMap(_ context.Context, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec) (map[string]interface{}, error) {
 	parameters := make(map[string]interface{})
-	for varName, varSpec := range genSpec.Variables {
-		val, ok := renderSpec.Parameters[varName]
-		if !ok {
-			if defaultStr, ok := varSpec.DefaultValue.(string); ok {
-				renderedDefaultValue, err := i.renderStringDefaultFromTemplate(varName, defaultStr)
-				if err != nil {
-					return nil, err
-				}
+	// in a fixed order, so the same invalid render spec always reports the same error, and default values that
+	// call seeded random functions get the same results every time
+	for _, varName := range sortedVariableNames(genSpec) {
+		varSpec := genSpec.Variables[varName]
+		if hasGroupCondition(genSpec, varSpec) {
+			continue
+		}
+		val, err := i.constructAndValidateParameter(ctx, varName, varSpec, renderSpecParameters)
+		if err != nil {
+			return nil, warnings, err
+		}
+		parameters[varName] = val
+	}
+	if err := i.addConditionalGroupParameters(ctx, genSpec, renderSpecParameters, parameters); err != nil {
+		return nil, warnings, err
+	}
+	return parameters, warnings, nil
+}
 
-				val = renderedDefaultValue
-			} else {
-				val = varSpec.DefaultValue
+func (i *GeneratorImpl) constructAndValidateParameter(ctx context.Context, varName string, varSpec api.VariableSpec, renderSpecParameters map[string]interface{}) (interface{}, error) {
+	val, ok := renderSpecParameters[varName]
+	if !ok {
+		if defaultStr, ok := varSpec.DefaultValue.(string); ok {
+			renderedDefaultValue, err := i.renderStringDefaultFromTemplate(ctx, varName, defaultStr)
+			if err != nil {
+				return nil, err
 			}
+
+			val = renderedDefaultValue
+		} else {
+			val = varSpec.DefaultValue
 		}
+	}
+
+	if val == nil {
+		return nil, fmt.Errorf("parameter '%s' is required but missing", varName)
+	}
+	val, err := i.validateParameterValue(ctx, varName, varSpec, val)
+	if err != nil {
+		return nil, err
+	}
+	if varSpec.Transform != "" {
+		val, err = i.renderString(ctx, map[string]interface{}{"value": val}, "__transform_"+varName, varSpec.Transform)
+		if err != nil {
+			return nil, fmt.Errorf("variable declaration %s has invalid transform (this is an error in the generator spec): %s", varName, err.Error())
+		}
+	}
+	return val, nil
+}
+
+// validateParameterValue checks a value that is present against its variable declaration, and returns it decoded
+func (i *GeneratorImpl) validateParameterValue(ctx context.Context, varName string, varSpec api.VariableSpec, val interface{}) (interface{}, error) {
+	val, err := i.decodeTypedValue(ctx, varName, varSpec, val)
+	if err != nil {
+		return nil, err
+	}
+	failed, err := i.failedValidationPatterns(varName, varSpec, val)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("value for parameter '%s' does not match %s", varName, describeFailedPatterns(varSpec, failed))
+	}
+	if isPlaceholder(varSpec, val) {
+		return nil, fmt.Errorf("value for parameter '%s' is still the placeholder '%s', please set it", varName, varSpec.Placeholder)
+	}
+	if err := i.validateStructure(varName, varSpec, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
 
-		if val == nil {
-			return nil, fmt.Errorf("parameter '%s' is required but missing", varName)
+func (i *GeneratorImpl) validateDefaultValues(ctx context.Context, genSpec *api.GeneratorSpec) []error {
+	errs := []error{}
+	for _, varName := range sortedVariableNames(genSpec) {
+		varSpec := genSpec.Variables[varName]
+		if !varSpec.HasDefault() {
+			// required variable, nothing to check
+			continue
 		}
-		if varSpec.ValidationPattern != "" {
-			matches, err := regexp.MatchString(varSpec.ValidationPattern, fmt.Sprintf("%v", val))
+
+		val := varSpec.DefaultValue
+		if defaultStr, ok := varSpec.DefaultValue.(string); ok {
+			renderedDefaultValue, err := i.renderStringDefaultFromTemplate(ctx, varName, defaultStr)
 			if err != nil {
-				return nil, fmt.Errorf("variable declaration %s has invalid pattern (this is an error in the generator spec, not the render request): %s", varName, err.Error())
-			}
-			if !matches {
-				return nil, fmt.Errorf("value for parameter '%s' does not match pattern %s", varName, varSpec.ValidationPattern)
+				errs = append(errs, err)
+				continue
 			}
+			val = renderedDefaultValue
 		}
-		parameters[varName] = val
+
+		failed, err := i.failedValidationPatterns(varName, varSpec, val)
+		if err != nil {
+			errs = append(errs, err)
+		} else if len(failed) > 0 {
+			errs = append(errs, fmt.Errorf("variable declaration %s has default value '%v' that does not match its own %s (this is an error in the generator spec)", varName, val, describeFailedPatterns(varSpec, failed)))
+		}
+	}
+	return errs
+}
+
+func sortedVariableNames(genSpec *api.GeneratorSpec) []string {
+	names := make([]string, 0, len(genSpec.Variables))
+	for k := range genSpec.Variables {
+		names = append(names, k)
 	}
-	return parameters, nil
+	sort.Strings(names)
+	return names
 }
 
-func (i *GeneratorImpl) renderAllTemplates(ctx context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) ([]api.FileResult, bool) {
+func sortedParameterNames(parameters map[string]interface{}) []string {
+	names := make([]string, 0, len(parameters))
+	for k := range parameters {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (i *GeneratorImpl) renderAllTemplates(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) ([]api.FileResult, bool, []string) {
 	var renderedFiles []api.FileResult
+	var warnings []string
 	allSuccessful := true
+	ctx = withHiddenValues(ctx, genSpec)
+	ctx = withRenderExcludes(ctx, genSpec)
 	for _, tplSpec := range genSpec.Templates {
+		if isExcludedTemplate(tplSpec, genSpec.Excludes) {
+			continue
+		}
+		if !hasAnyTag(tplSpec.Tags, request.RenderTags) {
+			continue
+		}
 		rendered, success := i.renderSingleTemplate(ctx, &tplSpec, parameters, sourceDir, targetDir)
 		renderedFiles = append(renderedFiles, rendered...)
 		allSuccessful = allSuccessful && success
+		warnings = append(warnings, deprecationWarnings(&tplSpec, rendered)...)
 	}
-	return renderedFiles, allSuccessful
+	return renderedFiles, allSuccessful, warnings
+}
+
+// isExcludedTemplate is true if the source of the template, or any of its fragments, matches an exclude pattern
+func isExcludedTemplate(tplSpec api.TemplateSpec, excludes []string) bool {
+	if glob.MatchesAny(tplSpec.RelativeSourcePath, excludes) {
+		return true
+	}
+	for _, fragment := range tplSpec.RelativeSourcePaths {
+		if glob.MatchesAny(fragment, excludes) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyTag is true if no tags were requested, or if the template has at least one of the requested tags
+func hasAnyTag(templateTags []string, requestedTags []string) bool {
+	if len(requestedTags) == 0 {
+		return true
+	}
+	for _, requested := range requestedTags {
+		for _, tag := range templateTags {
+			if tag == requested {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (i *GeneratorImpl) renderSingleTemplate(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) ([]api.FileResult, bool) {
-	templateName := strings.ReplaceAll(tplSpec.RelativeSourcePath, "/", "_")
-	templateContents, err := sourceDir.ReadFile(ctx, tplSpec.RelativeSourcePath)
+	if isDeleteAction(tplSpec) {
+		if err := checkTemplateSource(tplSpec); err != nil {
+			return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, err)}, false
+		}
+		return i.deleteSingleTemplate(ctx, tplSpec, parameters, targetDir)
+	}
+	sourceName := templateSourceName(tplSpec)
+	templateName := strings.ReplaceAll(sourceName, "/", "_")
+	templateContents, err := i.loadTemplate(ctx, tplSpec, sourceDir)
 	if err != nil {
-		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("failed to load template %s: %s", tplSpec.RelativeSourcePath, err))}, false
+		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, err)}, false
 	}
 
-	tmplw, err := templatewrapper.New(tplSpec.JustCopy, templateContents, templateName, tplSpec.RelativeSourcePath).Parse()
+	if tplSpec.FrontMatter {
+		frontMatter, body, err := templatewrapper.ParseFrontMatter(templateContents)
+		if err != nil {
+			return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("failed to parse front matter of template %s: %s", sourceName, err))}, false
+		}
+		tplSpec = i.applyFrontMatter(tplSpec, frontMatter)
+		templateContents = body
+	}
+
+	tmplw, err := templatewrapper.New(tplSpec.JustCopy, templateContents, templateName, sourceName).WithFuncs(i.templateFuncsWithRender(ctx, sourceDir, templateSourceDir(tplSpec), []string{sourceName})).WithCache(cache.From(ctx)).Parse()
 	if err != nil {
-		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("failed to parse template %s: %s", tplSpec.RelativeSourcePath, err))}, false
+		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("failed to parse template %s: %s", sourceName, err))}, false
+	}
+	tmplw = tmplw.WithTimeout(renderTimeout(ctx)).WithMaxBytes(maxFileBytes(ctx)).
+		WithContextFuncs(i.contextRenderFuncs(sourceDir, templateSourceDir(tplSpec), []string{sourceName}))
+
+	if len(tplSpec.WithItems) > 0 && len(tplSpec.WithEntries) > 0 {
+		return []api.FileResult{i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("template %s must not specify both with_items and with_entries", sourceName))}, false
 	}
 
 	renderedFiles := []api.FileResult{}
 	allSuccessful := true
+	forEachIteration(ctx, tplSpec, parameters, func(parameters map[string]interface{}, templateNameExtension string, errorMessageItemExtension string) {
+		renderedFiles, allSuccessful = i.renderSingleTemplateIteration(ctx, tplSpec, parameters, templateName, templateNameExtension,
+			errorMessageItemExtension, renderedFiles, allSuccessful, tmplw, targetDir)
+	})
+	return renderedFiles, allSuccessful
+}
+
+// forEachIteration calls iteration once per item in with_items, once per entry in with_entries, or just once,
+// passing a copy of parameters with the item variables set, so they do not leak into other templates.
+// If the request limits the item range, only the iterations in that range are done.
+func forEachIteration(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, iteration func(parameters map[string]interface{}, templateNameExtension string, errorMessageItemExtension string)) {
 	if len(tplSpec.WithItems) > 0 {
-		for counter, item := range tplSpec.WithItems {
-			parameters["item"] = item
-			renderedFiles, allSuccessful = i.renderSingleTemplateIteration(ctx, tplSpec, parameters, templateName, fmt.Sprintf("_%d", counter+1),
-				fmt.Sprintf(" for item #%d", counter+1), renderedFiles, allSuccessful, tmplw, targetDir)
+		from, to := itemRange(ctx, len(tplSpec.WithItems))
+		for counter := from; counter < to; counter++ {
+			iterationParameters := withIterationVariables(parameters, map[string]interface{}{"item": tplSpec.WithItems[counter]})
+			iteration(iterationParameters, fmt.Sprintf("_%d", counter+1), fmt.Sprintf(" for item #%d", counter+1))
+		}
+	} else if len(tplSpec.WithEntries) > 0 {
+		// iterate in sorted key order so the output does not depend on map iteration order
+		keys := make([]string, 0, len(tplSpec.WithEntries))
+		for k := range tplSpec.WithEntries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		from, to := itemRange(ctx, len(keys))
+		for _, key := range keys[from:to] {
+			iterationParameters := withIterationVariables(parameters, map[string]interface{}{"itemKey": key, "itemValue": tplSpec.WithEntries[key]})
+			iteration(iterationParameters, "_"+key, fmt.Sprintf(" for entry '%s'", key))
 		}
 	} else {
-		renderedFiles, allSuccessful = i.renderSingleTemplateIteration(ctx, tplSpec, parameters, templateName, "",
-			"", renderedFiles, allSuccessful, tmplw, targetDir)
+		iteration(parameters, "", "")
 	}
-	return renderedFiles, allSuccessful
+}
+
+// withIterationVariables returns a shallow copy of parameters with variables added
+func withIterationVariables(parameters map[string]interface{}, variables map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(parameters)+len(variables))
+	for k, v := range parameters {
+		result[k] = v
+	}
+	for k, v := range variables {
+		result[k] = v
+	}
+	return result
+}
+
+// applyFrontMatter returns a copy of tplSpec with the fields set in the front matter overridden
+func (i *GeneratorImpl) applyFrontMatter(tplSpec *api.TemplateSpec, frontMatter *templatewrapper.FrontMatter) *api.TemplateSpec {
+	result := *tplSpec
+	if frontMatter.RelativeTargetPath != "" {
+		result.RelativeTargetPath = frontMatter.RelativeTargetPath
+	}
+	if frontMatter.Condition != "" {
+		result.Condition = frontMatter.Condition
+	}
+	if frontMatter.JustCopy != nil {
+		result.JustCopy = *frontMatter.JustCopy
+	}
+	return &result
 }
 
 func (i *GeneratorImpl) renderSingleTemplateIteration(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, templateName string, templateNameExtension string,
-	errorMessageItemExtension string, renderedFiles []api.FileResult, allSuccessful bool, tmpl *templatewrapper.TemplateWrapper, targetDir *targetdir.TargetDirectory) ([]api.FileResult, bool) {
-	targetPath, err := i.renderString(ctx, parameters, fmt.Sprintf("%s_path%s", templateName, templateNameExtension), tplSpec.RelativeTargetPath)
+	errorMessageItemExtension string, renderedFiles []api.FileResult, allSuccessful bool, tmpl *templatewrapper.TemplateWrapper, targetDir *targetdir.TargetDirectory) (resultFiles []api.FileResult, resultSuccessful bool) {
+	ctx, cancel := withFileDeadline(ctx)
+	defer cancel()
+
+	// a panic must not crash the caller, so we turn it into an error for this file and continue with the next one
+	defer func() {
+		if r := recover(); r != nil {
+			resultFiles = append(renderedFiles, i.errorFileResult(ctx, tplSpec.RelativeTargetPath, fmt.Errorf("panic while rendering target '%s'%s: %v\n%s", tplSpec.RelativeTargetPath, errorMessageItemExtension, r, debug.Stack())))
+			resultSuccessful = false
+		}
+	}()
+
+	targetPath, condition, skip, err := i.resolveTarget(ctx, tplSpec, parameters, templateName, templateNameExtension, errorMessageItemExtension, targetDir)
 	if err != nil {
-		renderedFiles = append(renderedFiles, i.errorFileResult(ctx, targetPath, fmt.Errorf("error evaluating target path from '%s'%s: %s", tplSpec.RelativeTargetPath, errorMessageItemExtension, err)))
+		renderedFiles = append(renderedFiles, i.errorFileResult(ctx, targetPath, err))
 		allSuccessful = false
-	} else {
-		condition, err := i.evaluateCondition(ctx, tplSpec.Condition, parameters, fmt.Sprintf("%s_condition%s", templateName, templateNameExtension))
+	} else if !condition && shouldReportConditionSkipped(ctx) {
+		renderedFiles = append(renderedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonConditionFalse))
+	} else if skip {
+		renderedFiles = append(renderedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonTargetExists))
+	} else if condition && isGitignoreAware(ctx) && targetDir.IsGitignored(ctx, targetPath) {
+		renderedFiles = append(renderedFiles, i.skippedFileResult(ctx, targetPath, api.SkipReasonIgnoredByGit))
+	} else if condition {
+		err := i.renderAndWriteFile(ctx, tplSpec, parameters, tmpl, templateName, targetDir, targetPath)
 		if err != nil {
-			renderedFiles = append(renderedFiles, i.errorFileResult(ctx, targetPath, fmt.Errorf("error evaluating condition from '%s'%s: %s", tplSpec.Condition, errorMessageItemExtension, err)))
+			renderedFiles = append(renderedFiles, i.errorFileResult(ctx, targetPath, fmt.Errorf("error evaluating template for target '%s'%s: %s", targetPath, errorMessageItemExtension, err)))
 			allSuccessful = false
-		} else if condition {
-			err := i.renderAndWriteFile(ctx, parameters, tmpl, templateName, targetDir, targetPath)
-			if err != nil {
-				renderedFiles = append(renderedFiles, i.errorFileResult(ctx, targetPath, fmt.Errorf("error evaluating template for target '%s'%s: %s", targetPath, errorMessageItemExtension, err)))
-				allSuccessful = false
-			} else {
-				renderedFiles = append(renderedFiles, i.successFileResult(ctx, targetPath))
-			}
+		} else {
+			renderedFiles = append(renderedFiles, i.successFileResult(ctx, targetPath))
 		}
 	}
 	return renderedFiles, allSuccessful
 }
 
+// resolveTarget evaluates the target path, the condition, and skip_if_target_exists of a template for one iteration,
+// without rendering its contents. skip is only ever true if condition is.
+func (i *GeneratorImpl) resolveTarget(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, templateName string, templateNameExtension string,
+	errorMessageItemExtension string, targetDir *targetdir.TargetDirectory) (targetPath string, condition bool, skip bool, err error) {
+	targetPath, err = i.renderString(ctx, parameters, fmt.Sprintf("%s_path%s", templateName, templateNameExtension), tplSpec.RelativeTargetPath)
+	if err != nil {
+		return targetPath, false, false, fmt.Errorf("error evaluating target path from '%s'%s: %s", tplSpec.RelativeTargetPath, errorMessageItemExtension, err)
+	}
+	condition, err = i.evaluateConditionWithFuncs(ctx, i.conditionFuncs(ctx, targetDir), tplSpec.Condition, parameters, fmt.Sprintf("%s_condition%s", templateName, templateNameExtension))
+	if err != nil {
+		return targetPath, false, false, fmt.Errorf("error evaluating condition from '%s'%s: %s", tplSpec.Condition, errorMessageItemExtension, err)
+	}
+	if !condition {
+		return targetPath, false, false, nil
+	}
+	skip, err = i.evaluateSkipIfTargetExists(ctx, tplSpec.SkipIfTargetExists, parameters, fmt.Sprintf("%s_skipiftargetexists%s", templateName, templateNameExtension), targetDir)
+	if err != nil {
+		return targetPath, true, false, fmt.Errorf("error evaluating skip_if_target_exists from '%s'%s: %s", tplSpec.SkipIfTargetExists, errorMessageItemExtension, err)
+	}
+	if !skip {
+		if err := checkCaseCollision(ctx, targetPath); err != nil {
+			return targetPath, true, false, err
+		}
+	}
+	return normalizeTargetPathCase(ctx, targetPath), true, skip, nil
+}
+
+func (i *GeneratorImpl) evaluateSkipIfTargetExists(ctx context.Context, skipIfTargetExists string, parameters map[string]interface{}, templateName string, targetDir *targetdir.TargetDirectory) (bool, error) {
+	if skipIfTargetExists == "" {
+		return false, nil
+	}
+	markerPath, err := i.renderString(ctx, parameters, templateName, skipIfTargetExists)
+	if err != nil {
+		return false, err
+	}
+	if markerPath == "" {
+		return false, nil
+	}
+	markerPath, err = resolveInsideTargetDir("check", markerPath)
+	if err != nil {
+		return false, err
+	}
+	return targetDir.Exists(ctx, markerPath), nil
+}
+
 func (i *GeneratorImpl) evaluateCondition(ctx context.Context, condition string, parameters map[string]interface{}, templateName string) (bool, error) {
+	return i.evaluateConditionWithFuncs(ctx, i.templateFuncs(ctx), condition, parameters, templateName)
+}
+
+func (i *GeneratorImpl) evaluateConditionWithFuncs(ctx context.Context, funcs template.FuncMap, condition string, parameters map[string]interface{}, templateName string) (bool, error) {
 	if condition == "" {
 		return true, nil
 	}
-	rendered, err := i.renderString(ctx, parameters, templateName, condition)
+	rendered, err := i.renderStringWithFuncs(ctx, funcs, parameters, templateName, condition)
 	if err != nil {
 		return false, err
 	}
 	return rendered != "false" && rendered != "0" && rendered != "no" && rendered != "skip", nil
 }
 
-func (i *GeneratorImpl) renderAndWriteFile(ctx context.Context, parameters map[string]interface{}, tmplw *templatewrapper.TemplateWrapper, templateName string, targetDir *targetdir.TargetDirectory, targetPath string) error {
+func (i *GeneratorImpl) renderAndWriteFile(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, tmplw *templatewrapper.TemplateWrapper, templateName string, targetDir *targetdir.TargetDirectory, targetPath string) error {
+	// the parameters map is modified for each item, so the template gets its own copy in case it is aborted and keeps running
+	parametersCopy := copyParameters(parameters)
+
 	var buf bytes.Buffer
-	err := tmplw.Write(&buf, templateName, parameters)
+	err := tmplw.WriteWithContext(ctx, &buf, templateName, parametersCopy)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			// aborted, the parameters have nothing to do with it
+			return err
+		}
+		var limitErr *templatewrapper.OutputLimitError
+		if errors.As(err, &limitErr) {
+			return err
+		}
+		// typically a mismatch between the template and the shape of the parameter values
+		message := redactHiddenValues(ctx, parametersCopy, err.Error())
+		if offending := describeOffendingValue(ctx, parametersCopy, err); offending != "" {
+			return fmt.Errorf("%s (%s; %s)", message, describeParameters(ctx, parametersCopy), offending)
+		}
+		return fmt.Errorf("%s (%s)", message, describeParameters(ctx, parametersCopy))
+	}
+
+	if tplSpec.FailOnEmpty && len(bytes.TrimSpace(buf.Bytes())) == 0 {
+		return errors.New("rendered output is empty, but fail_on_empty is set")
+	}
+
+	output, err := postProcess(ctx, tplSpec.PostProcess, buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if shouldEnsureFinalNewline(ctx, tplSpec) {
+		output = ensureFinalNewline(output)
+	}
+	if err := validateOutputFormat(tplSpec.ValidateAs, targetPath, output); err != nil {
+		return err
+	}
+	output, err = encodeOutput(tplSpec.Encoding, tplSpec.WriteBOM, output)
 	if err != nil {
-		// unsure if this is reachable. All errors I've been able to produce are found during template parse
 		return err
 	}
+	if tplSpec.WriteBOM {
+		output = templatewrapper.AddBOM(output)
+	}
 
-	err = targetDir.WriteFile(ctx, targetPath, buf.Bytes())
+	mode, err := parseFileMode(tplSpec.Mode)
+	if err != nil {
+		return err
+	}
+
+	err = targetDir.WriteFileWithMode(ctx, targetPath, output, mode, shouldPreserveExistingMode(ctx))
 	return err
 }
 
-func (i *GeneratorImpl) renderString(_ context.Context, parameters map[string]interface{}, templateName string, templateContents string) (string, error) {
-	tmpl, err := template.New(templateName).Funcs(sprig.TxtFuncMap()).Parse(templateContents)
+func (i *GeneratorImpl) renderString(ctx context.Context, parameters map[string]interface{}, templateName string, templateContents string) (result string, err error) {
+	return i.renderStringWithFuncs(ctx, i.templateFuncs(ctx), parameters, templateName, templateContents)
+}
+
+func (i *GeneratorImpl) renderStringWithFuncs(ctx context.Context, funcs template.FuncMap, parameters map[string]interface{}, templateName string, templateContents string) (string, error) {
+	// like in renderAndWriteFile, the parameters map may be modified while an aborted template keeps running
+	return executeString(ctx, funcs, templateName, templateContents, copyParameters(parameters))
+}
+
+// executeString executes a template given as a string, such as a target path or a condition, with the same timeout
+// and size limit as a file, see templatewrapper.WriteWithContext. A panic during execution is returned as an error.
+func executeString(ctx context.Context, funcs template.FuncMap, templateName string, templateContents string, data interface{}) (string, error) {
+	tmplw, err := templatewrapper.New(false, []byte(templateContents), templateName, templateName).WithFuncs(funcs).Parse()
 	if err != nil {
 		return "", err
 	}
 
 	var buf bytes.Buffer
-	err = tmpl.ExecuteTemplate(&buf, templateName, parameters)
+	err = tmplw.WithTimeout(renderTimeout(ctx)).WithMaxBytes(maxFileBytes(ctx)).WriteWithContext(ctx, &buf, templateName, data)
 	if err != nil {
-		// unsure if this is reachable. All errors I've been able to produce are found during template parse
 		return "", err
 	}
-
 	return buf.String(), nil
 }
 
+func copyParameters(parameters map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(parameters))
+	for k, v := range parameters {
+		result[k] = v
+	}
+	return result
+}
+
 // --- response helpers
 
 func (i *GeneratorImpl) errorResponseToplevel(_ context.Context, err error) *api.Response {
@@ -321,6 +1026,14 @@ func (i *GeneratorImpl) errorResponseRender(_ context.Context, renderedFiles []a
 	}
 }
 
+// withWarnings adds non-fatal warnings to a response, in front of any warnings it already has.
+func (i *GeneratorImpl) withWarnings(response *api.Response, warnings []string) *api.Response {
+	if len(warnings) > 0 {
+		response.Warnings = append(warnings, response.Warnings...)
+	}
+	return response
+}
+
 func (i *GeneratorImpl) successFileResult(_ context.Context, relativeFilePath string) api.FileResult {
 	return api.FileResult{
 		Success:          true,
@@ -328,6 +1041,23 @@ func (i *GeneratorImpl) successFileResult(_ context.Context, relativeFilePath st
 	}
 }
 
+func (i *GeneratorImpl) skippedFileResult(_ context.Context, relativeFilePath string, skipReason string) api.FileResult {
+	return api.FileResult{
+		Success:          true,
+		Skipped:          true,
+		SkipReason:       skipReason,
+		RelativeFilePath: relativeFilePath,
+	}
+}
+
+func (i *GeneratorImpl) deletedFileResult(_ context.Context, relativeFilePath string) api.FileResult {
+	return api.FileResult{
+		Success:          true,
+		Deleted:          true,
+		RelativeFilePath: relativeFilePath,
+	}
+}
+
 func (i *GeneratorImpl) errorFileResult(_ context.Context, relativeFilePath string, err error) api.FileResult {
 	return api.FileResult{
 		Success:          false,
@@ -0,0 +1,76 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+	"text/template"
+)
+
+// these tests add coverage for some internal error conditions only
+
+func _testRenderSingleTemplateIteration(t *testing.T, ctx context.Context, tplSpec *api.TemplateSpec, contents string) ([]api.FileResult, bool, *targetdir.TargetDirectory) {
+	dir, err := ioutil.TempDir("", "panic")
+	require.Nil(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	targetDir := targetdir.Instance(ctx, dir)
+
+	cut := &GeneratorImpl{}
+	tmplw, err := templatewrapper.New(false, []byte(contents), "item.txt.tmpl", "item.txt.tmpl").WithFuncs(cut.templateFuncs(ctx)).Parse()
+	require.Nil(t, err)
+	previous := []api.FileResult{{Success: true, RelativeFilePath: "previous.txt"}}
+
+	actualFiles, actualSuccess := cut.renderSingleTemplateIteration(ctx, tplSpec, map[string]interface{}{"message": "hi"},
+		"item.txt.tmpl", "", "", previous, true, tmplw, targetDir)
+	require.Equal(t, 2, len(actualFiles))
+	require.Equal(t, previous[0], actualFiles[0])
+	return actualFiles, actualSuccess, targetDir
+}
+
+func TestRenderSingleTemplateIteration_PanicBecomesFileError(t *testing.T) {
+	// post processors run outside of template execution, which would otherwise recover the panic itself
+	ctx := WithPostProcessors(context.TODO(), map[string]api.PostProcessor{
+		"explode": func(output []byte) ([]byte, error) {
+			panic("post processor exploded")
+		},
+	})
+	tplSpec := &api.TemplateSpec{
+		RelativeSourcePath: "item.txt.tmpl",
+		RelativeTargetPath: "item.txt",
+		PostProcess:        []string{"explode"},
+	}
+
+	actualFiles, actualSuccess, targetDir := _testRenderSingleTemplateIteration(t, ctx, tplSpec, "{{ .message }}")
+
+	require.False(t, actualSuccess)
+	require.False(t, actualFiles[1].Success)
+	require.Equal(t, "item.txt", actualFiles[1].RelativeFilePath)
+	require.Contains(t, actualFiles[1].Errors[0].Error(), "panic while rendering target 'item.txt': post processor exploded")
+	require.False(t, targetDir.Exists(ctx, "item.txt"))
+}
+
+func TestRenderSingleTemplateIteration_PanicInFunctionBecomesFileError(t *testing.T) {
+	ctx := WithExtraFuncs(context.TODO(), template.FuncMap{
+		"explode": func(s string) string {
+			panic("function exploded")
+		},
+	})
+	tplSpec := &api.TemplateSpec{
+		RelativeSourcePath: "item.txt.tmpl",
+		RelativeTargetPath: "item.txt",
+	}
+
+	actualFiles, actualSuccess, targetDir := _testRenderSingleTemplateIteration(t, ctx, tplSpec, "{{ .message | explode }}")
+
+	require.False(t, actualSuccess)
+	require.False(t, actualFiles[1].Success)
+	require.Equal(t, "item.txt", actualFiles[1].RelativeFilePath)
+	require.Contains(t, actualFiles[1].Errors[0].Error(), "error evaluating template for target 'item.txt': ")
+	require.Contains(t, actualFiles[1].Errors[0].Error(), "function exploded")
+	require.False(t, targetDir.Exists(ctx, "item.txt"))
+}
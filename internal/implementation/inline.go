@@ -0,0 +1,78 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"strings"
+)
+
+// templateSourceName names the template in error messages, and is the base for the internal template names.
+// Inline templates have no source path, so they are named after their target, and concatenated templates after their fragments.
+func templateSourceName(tplSpec *api.TemplateSpec) string {
+	if tplSpec.InlineContent != "" {
+		return "inline template for target " + tplSpec.RelativeTargetPath
+	}
+	if len(tplSpec.RelativeSourcePaths) > 0 {
+		return strings.Join(tplSpec.RelativeSourcePaths, "+")
+	}
+	return tplSpec.RelativeSourcePath
+}
+
+func checkTemplateSource(tplSpec *api.TemplateSpec) error {
+	if err := checkTemplateAction(tplSpec); err != nil {
+		return err
+	}
+	set := 0
+	for _, isSet := range []bool{tplSpec.RelativeSourcePath != "", tplSpec.InlineContent != "", len(tplSpec.RelativeSourcePaths) > 0} {
+		if isSet {
+			set++
+		}
+	}
+	if isDeleteAction(tplSpec) {
+		if set != 0 {
+			return fmt.Errorf("template for target %s deletes its target, so it must not set source, sources or content (this is an error in the generator spec)", tplSpec.RelativeTargetPath)
+		}
+		return nil
+	}
+	if set != 1 {
+		return fmt.Errorf("template for target %s must set exactly one of source, sources and content (this is an error in the generator spec)", tplSpec.RelativeTargetPath)
+	}
+	return nil
+}
+
+// loadTemplate obtains the contents of a template, either from its source file or inline from the generator spec.
+func (i *GeneratorImpl) loadTemplate(ctx context.Context, tplSpec *api.TemplateSpec, sourceDir *generatordir.GeneratorDirectory) ([]byte, error) {
+	if err := checkTemplateSource(tplSpec); err != nil {
+		return nil, err
+	}
+	if tplSpec.InlineContent != "" {
+		return []byte(tplSpec.InlineContent), nil
+	}
+	if len(tplSpec.RelativeSourcePaths) > 0 {
+		return i.loadFragments(ctx, tplSpec, sourceDir)
+	}
+	templateContents, err := sourceDir.ReadFile(ctx, tplSpec.RelativeSourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %s", tplSpec.RelativeSourcePath, err)
+	}
+	return templateContents, nil
+}
+
+// loadFragments concatenates the fragments of a template, with the separator between them.
+func (i *GeneratorImpl) loadFragments(ctx context.Context, tplSpec *api.TemplateSpec, sourceDir *generatordir.GeneratorDirectory) ([]byte, error) {
+	var combined bytes.Buffer
+	for counter, fragment := range tplSpec.RelativeSourcePaths {
+		fragmentContents, err := sourceDir.ReadFile(ctx, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load template fragment %s: %s", fragment, err)
+		}
+		if counter > 0 {
+			combined.WriteString(tplSpec.Separator)
+		}
+		combined.Write(fragmentContents)
+	}
+	return combined.Bytes(), nil
+}
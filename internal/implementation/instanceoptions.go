@@ -0,0 +1,57 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"text/template"
+)
+
+// InstanceOptions is the configuration of a generator instance, which applies to every call made through it.
+type InstanceOptions struct {
+	ExtraFuncs        template.FuncMap
+	PostProcessors    map[string]api.PostProcessor
+	Cache             *cache.Cache
+	SafeDefaults      bool
+	ReproducibleBuild bool
+	Discovery         *api.DiscoveryOptions
+}
+
+// Apply adds the configuration to the context of a call. What the caller put into the context takes precedence,
+// so extra functions and post processors of the same name, or another cache, can be given for a single call.
+func (o *InstanceOptions) Apply(ctx context.Context) context.Context {
+	if len(o.ExtraFuncs) > 0 {
+		merged := template.FuncMap{}
+		for name, f := range o.ExtraFuncs {
+			merged[name] = f
+		}
+		for name, f := range extraFuncs(ctx) {
+			merged[name] = f
+		}
+		ctx = context.WithValue(ctx, extraFuncsKey{}, merged)
+	}
+	if len(o.PostProcessors) > 0 {
+		merged := map[string]api.PostProcessor{}
+		for name, p := range o.PostProcessors {
+			merged[name] = p
+		}
+		for name, p := range extraPostProcessors(ctx) {
+			merged[name] = p
+		}
+		ctx = context.WithValue(ctx, postProcessorsKey{}, merged)
+	}
+	if o.Cache != nil && cache.From(ctx) == nil {
+		ctx = cache.WithCache(ctx, o.Cache)
+	}
+	if o.SafeDefaults {
+		ctx = withSafeDefaults(ctx)
+	}
+	if o.ReproducibleBuild {
+		ctx = withReproducibleBuild(ctx)
+	}
+	if _, ok := generatordir.DiscoveryOptionsFrom(ctx); o.Discovery != nil && !ok {
+		ctx = generatordir.WithDiscoveryOptions(ctx, *o.Discovery)
+	}
+	return ctx
+}
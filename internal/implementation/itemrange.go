@@ -0,0 +1,34 @@
+package implementation
+
+import "context"
+
+type itemRangeKey struct{}
+
+type itemRangeValue struct {
+	offset int
+	limit  int
+}
+
+func withItemRange(ctx context.Context, offset int, limit int) context.Context {
+	return context.WithValue(ctx, itemRangeKey{}, itemRangeValue{offset: offset, limit: limit})
+}
+
+// itemRange returns the indexes [from, to) of the iterations to render out of count, as limited by the request
+func itemRange(ctx context.Context, count int) (int, int) {
+	value, ok := ctx.Value(itemRangeKey{}).(itemRangeValue)
+	if !ok {
+		return 0, count
+	}
+	from := value.offset
+	if from < 0 {
+		from = 0
+	}
+	if from > count {
+		from = count
+	}
+	to := count
+	if value.limit > 0 && from+value.limit < to {
+		to = from + value.limit
+	}
+	return from, to
+}
@@ -0,0 +1,34 @@
+package implementation
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"regexp"
+)
+
+var itemReferenceRegex = regexp.MustCompile(`\.item(Key|Value)?\b`)
+
+// iterationTargetWarnings finds templates that iterate over with_items or with_entries, but whose target path
+// does not refer to the item, so every iteration writes the same file.
+//
+// A condition that refers to the item may make sure only one iteration writes, and front matter may set the
+// target, so such templates are not reported.
+func iterationTargetWarnings(genSpec *api.GeneratorSpec) []string {
+	warnings := []string{}
+	for _, tplSpec := range genSpec.Templates {
+		var iteratesOver string
+		if len(tplSpec.WithItems) > 0 {
+			iteratesOver = "with_items"
+		} else if len(tplSpec.WithEntries) > 0 {
+			iteratesOver = "with_entries"
+		} else {
+			continue
+		}
+		if tplSpec.FrontMatter || itemReferenceRegex.MatchString(tplSpec.RelativeTargetPath) || itemReferenceRegex.MatchString(tplSpec.Condition) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("template %s iterates over %s, but its target path '%s' does not refer to the item, so all iterations write the same file",
+			templateSourceName(&tplSpec), iteratesOver, tplSpec.RelativeTargetPath))
+	}
+	return warnings
+}
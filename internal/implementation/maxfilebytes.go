@@ -0,0 +1,15 @@
+package implementation
+
+import "context"
+
+type maxFileBytesKey struct{}
+
+func withMaxFileBytes(ctx context.Context, maxBytes int64) context.Context {
+	return context.WithValue(ctx, maxFileBytesKey{}, maxBytes)
+}
+
+// maxFileBytes is the maximum size of a single rendered file, zero means no limit
+func maxFileBytes(ctx context.Context) int64 {
+	maxBytes, _ := ctx.Value(maxFileBytesKey{}).(int64)
+	return maxBytes
+}
@@ -0,0 +1,53 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+// omitDefaultParameters drops the parameters whose value the variable would get anyway, so a written render spec
+// only contains what was set intentionally. A parameter is only dropped if resolving the defaults without it,
+// and without those dropped before, still gives the same values, so rendering the written render spec uses the
+// same values, even for defaults that depend on other parameters. Defaults that differ between runs, such as
+// random ones, never match, so such values are kept.
+func (i *GeneratorImpl) omitDefaultParameters(ctx context.Context, request *api.Request, generatorName string, genSpec *api.GeneratorSpec, parameters map[string]interface{}) (map[string]interface{}, error) {
+	// the profile changes the defaults that rendering will apply
+	ctx, genSpec, err := i.withProfileIfRequested(ctx, request, genSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[string]interface{}, len(parameters))
+	for k, v := range parameters {
+		kept[k] = v
+	}
+	omitted := []string{}
+	for _, name := range sortedParameterNames(parameters) {
+		if _, ok := genSpec.Variables[name]; !ok || parameters[name] == nil {
+			continue
+		}
+
+		candidate := make(map[string]interface{}, len(kept))
+		for k, v := range kept {
+			if k != name {
+				candidate[k] = v
+			}
+		}
+		resolved := i.resolveDefaults(ctx, generatorName, genSpec, candidate)
+		if !resolved.Success {
+			return nil, resolved.Errors[0]
+		}
+		same := true
+		for _, other := range append(omitted, name) {
+			if !sameValue(resolved.Parameters[other].Value, parameters[other]) {
+				same = false
+				break
+			}
+		}
+		if same {
+			kept = candidate
+			omitted = append(omitted, name)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,43 @@
+package implementation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"go/parser"
+	"go/token"
+	"gopkg.in/yaml.v2"
+	"io"
+)
+
+// validateOutputFormat checks that rendered output parses as the format declared in validate_as, if any.
+//
+// Yaml output may consist of several documents, each of which must parse.
+func validateOutputFormat(validateAs string, targetPath string, output []byte) error {
+	var err error
+	switch validateAs {
+	case "":
+		return nil
+	case api.OutputFormatJSON:
+		var parsed interface{}
+		err = json.Unmarshal(output, &parsed)
+	case api.OutputFormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(output))
+		for err == nil {
+			var parsed interface{}
+			err = decoder.Decode(&parsed)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+	case api.OutputFormatGo:
+		_, err = parser.ParseFile(token.NewFileSet(), targetPath, output, 0)
+	default:
+		return fmt.Errorf("unknown output format %s in validate_as (this is an error in the generator spec)", validateAs)
+	}
+	if err != nil {
+		return fmt.Errorf("rendered output is not valid %s: %s", validateAs, err.Error())
+	}
+	return nil
+}
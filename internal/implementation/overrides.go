@@ -0,0 +1,45 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+// applyOverrides returns a copy of a render spec read from a file, with the overrides from the request deep-merged
+// over its parameters. The file itself is left unchanged.
+//
+// Render spec files are not checked for parameters the generator does not know, but overrides are given at call
+// time, so a typo there is reported just like for RenderWithValues.
+func (i *GeneratorImpl) applyOverrides(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec) (*api.RenderSpec, error) {
+	if len(request.Overrides) == 0 {
+		return renderSpec, nil
+	}
+
+	resolvedOverrides, _ := i.resolveParameterAliases(ctx, genSpec, request.Overrides)
+	if err := i.checkNoExtraneousParameters(ctx, genSpec, resolvedOverrides); err != nil {
+		return nil, err
+	}
+
+	result := *renderSpec
+	result.Parameters = deepMerge(renderSpec.Parameters, request.Overrides)
+	return &result, nil
+}
+
+// deepMerge returns a new map with the values of override set over those of base. Where both have a map
+// for the same key, these are merged recursively, all other values are replaced.
+func deepMerge(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseIsMap := toStringKeyedMap(result[k])
+		overrideMap, overrideIsMap := toStringKeyedMap(v)
+		if baseIsMap && overrideIsMap {
+			result[k] = deepMerge(baseMap, overrideMap)
+		} else {
+			result[k] = v
+		}
+	}
+	return result
+}
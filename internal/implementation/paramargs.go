@@ -0,0 +1,37 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func (i *GeneratorImpl) ParseParameterArgs(_ context.Context, args []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for _, arg := range args {
+		pos := strings.Index(arg, "=")
+		if pos < 0 {
+			return nil, fmt.Errorf("invalid parameter argument '%s': must be of the form key=value or key:=jsonvalue", arg)
+		}
+		key, value := arg[:pos], arg[pos+1:]
+
+		if strings.HasSuffix(key, ":") {
+			key = strings.TrimSuffix(key, ":")
+			if key == "" {
+				return nil, fmt.Errorf("invalid parameter argument '%s': key must not be empty", arg)
+			}
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+				return nil, fmt.Errorf("invalid parameter argument '%s': value is not valid json: %s", arg, err)
+			}
+			result[key] = parsed
+		} else {
+			if key == "" {
+				return nil, fmt.Errorf("invalid parameter argument '%s': key must not be empty", arg)
+			}
+			result[key] = value
+		}
+	}
+	return result, nil
+}
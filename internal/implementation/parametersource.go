@@ -0,0 +1,101 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// where the parameters of a render run came from is only needed for error messages deep down in rendering
+type parameterSourceKey struct{}
+
+func withParameterSource(ctx context.Context, description string) context.Context {
+	return context.WithValue(ctx, parameterSourceKey{}, description)
+}
+
+// describeParameters names the render spec file the parameters were read from, if any, and the parameters
+// in scope, so template errors can be correlated with the render spec. Values are left out, they may be secrets.
+func describeParameters(ctx context.Context, parameters map[string]interface{}) string {
+	names := strings.Join(sortedParameterNames(parameters), ", ")
+	if source, ok := ctx.Value(parameterSourceKey{}).(string); ok {
+		return fmt.Sprintf("parameters from %s: %s", source, names)
+	}
+	return fmt.Sprintf("parameters: %s", names)
+}
+
+// which parameter values must never appear in error messages is known from the generator spec, which
+// is not available where templates are executed
+type hiddenValuesKey struct{}
+
+func withHiddenValues(ctx context.Context, genSpec *api.GeneratorSpec) context.Context {
+	hidden := map[string]bool{}
+	for name, varSpec := range genSpec.Variables {
+		if isHiddenVariable(varSpec) {
+			hidden[name] = true
+		}
+	}
+	return context.WithValue(ctx, hiddenValuesKey{}, hidden)
+}
+
+// isHiddenVariable is true for variables whose values must not be shown, because they are secrets or binary
+func isHiddenVariable(varSpec api.VariableSpec) bool {
+	return varSpec.Sensitive || varSpec.Type == api.VariableTypeBytes
+}
+
+// what is shown instead of a hidden value
+const redactedValue = "***"
+
+const maxDescribedValueLength = 40
+
+// text/template names the expression that failed like this: executing "name" at <(index .upstreams 0).host>: ...
+var failedExpressionRegex = regexp.MustCompile(`at <([^>]*)>:`)
+
+var fieldRegex = regexp.MustCompile(`\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// describeOffendingValue finds the first parameter referenced by the expression that failed, and describes
+// its type and a truncated form of its value, unless it is sensitive. Returns the empty string if there is none.
+func describeOffendingValue(ctx context.Context, parameters map[string]interface{}, err error) string {
+	expression := failedExpressionRegex.FindStringSubmatch(err.Error())
+	if expression == nil {
+		return ""
+	}
+	for _, field := range fieldRegex.FindAllStringSubmatch(expression[1], -1) {
+		name := field[1]
+		val, ok := parameters[name]
+		if !ok {
+			continue
+		}
+		if hidden, _ := ctx.Value(hiddenValuesKey{}).(map[string]bool); hidden[name] {
+			return fmt.Sprintf("%s is %T, value not shown", name, val)
+		}
+		return fmt.Sprintf("%s is %T %q", name, val, truncate(fmt.Sprintf("%v", val), maxDescribedValueLength))
+	}
+	return ""
+}
+
+// below this length, a hidden value is too likely to occur in an error message by coincidence, e.g. in a line number
+const minRedactedValueLength = 4
+
+// redactHiddenValues removes the values of sensitive parameters from an error message. text/template includes
+// values in some messages, e.g. "range can't iterate over <value>".
+func redactHiddenValues(ctx context.Context, parameters map[string]interface{}, message string) string {
+	hidden, _ := ctx.Value(hiddenValuesKey{}).(map[string]bool)
+	for name := range hidden {
+		if val, ok := parameters[name]; ok && val != nil {
+			if valueStr := fmt.Sprintf("%v", val); len(valueStr) >= minRedactedValueLength {
+				message = strings.ReplaceAll(message, valueStr, redactedValue)
+			}
+		}
+	}
+	return message
+}
+
+func truncate(value string, maxLength int) string {
+	if utf8.RuneCountInString(value) <= maxLength {
+		return value
+	}
+	return string([]rune(value)[:maxLength]) + "..."
+}
@@ -0,0 +1,68 @@
+package implementation
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"regexp"
+	"strings"
+)
+
+// validationPatterns lists all patterns of a variable, the single ValidationPattern first
+func validationPatterns(varSpec api.VariableSpec) []string {
+	patterns := []string{}
+	if varSpec.ValidationPattern != "" {
+		patterns = append(patterns, varSpec.ValidationPattern)
+	}
+	for _, pattern := range varSpec.ValidationPatterns {
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// failedValidationPatterns checks a value against the patterns of its variable and returns the patterns that make
+// it invalid, that is, the ones it does not match in mode all, or all of them if it matches none in mode any.
+// The result is empty if the value is valid.
+func (i *GeneratorImpl) failedValidationPatterns(varName string, varSpec api.VariableSpec, val interface{}) ([]string, error) {
+	if varSpec.ValidationMode != "" && varSpec.ValidationMode != api.ValidationModeAll && varSpec.ValidationMode != api.ValidationModeAny {
+		return nil, fmt.Errorf("variable declaration %s has unknown pattern_mode '%s', must be one of %s, %s (this is an error in the generator spec, not the render request)",
+			varName, varSpec.ValidationMode, api.ValidationModeAll, api.ValidationModeAny)
+	}
+	patterns := validationPatterns(varSpec)
+	if len(patterns) == 0 || varSpec.Type == api.VariableTypeBytes {
+		// patterns are meaningless for binary content
+		return nil, nil
+	}
+
+	value := fmt.Sprintf("%v", val)
+	failed := []string{}
+	for _, pattern := range patterns {
+		matches, err := regexp.MatchString(pattern, value)
+		if err != nil {
+			return nil, fmt.Errorf("variable declaration %s has invalid pattern (this is an error in the generator spec, not the render request): %s", varName, err.Error())
+		}
+		if !matches {
+			failed = append(failed, pattern)
+		}
+	}
+	if varSpec.ValidationMode == api.ValidationModeAny && len(failed) < len(patterns) {
+		return nil, nil
+	}
+	return failed, nil
+}
+
+// describeFailedPatterns completes an error message like "does not match ..." for the result of failedValidationPatterns
+func describeFailedPatterns(varSpec api.VariableSpec, failed []string) string {
+	if len(failed) == 1 {
+		return "pattern " + failed[0]
+	}
+	quoted := make([]string, len(failed))
+	for k, pattern := range failed {
+		quoted[k] = "'" + pattern + "'"
+	}
+	if varSpec.ValidationMode == api.ValidationModeAny {
+		return "any of the patterns " + strings.Join(quoted, ", ")
+	}
+	return "patterns " + strings.Join(quoted, ", ")
+}
@@ -0,0 +1,25 @@
+package implementation
+
+import "github.com/mundobaton/go-generator-lib/api"
+
+// placeholders returns what is written into render specs for variables without a default: their own placeholder,
+// else the one given in the request, else the empty string, so the render spec is valid and shows what to edit
+func placeholders(request *api.Request) func(varSpec api.VariableSpec) interface{} {
+	return func(varSpec api.VariableSpec) interface{} {
+		if varSpec.Placeholder != "" {
+			return varSpec.Placeholder
+		}
+		return request.MissingValuePlaceholder
+	}
+}
+
+// noPlaceholders leaves variables without a default unset
+func noPlaceholders(_ api.VariableSpec) interface{} {
+	return nil
+}
+
+// isPlaceholder is true if a value was left at the placeholder of its variable
+func isPlaceholder(varSpec api.VariableSpec, val interface{}) bool {
+	str, ok := val.(string)
+	return ok && varSpec.Placeholder != "" && str == varSpec.Placeholder
+}
@@ -0,0 +1,133 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"strings"
+)
+
+func (i *GeneratorImpl) PlanRender(ctx context.Context, request *api.Request) *api.PlanResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}}
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}}
+	}
+
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}}
+	}
+
+	return i.planWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, targetDir)
+}
+
+func (i *GeneratorImpl) PlanRenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.PlanResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}}
+	}
+
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, parameters)
+
+	err = i.checkNoExtraneousParameters(ctx, genSpec, parameters)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}, Warnings: warnings}
+	}
+
+	renderSpec := &api.RenderSpec{
+		GeneratorName: generatorName,
+		Parameters:    parameters,
+	}
+	result := i.planWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, targetDir)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result
+}
+
+func (i *GeneratorImpl) planWithRenderSpec(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) *api.PlanResponse {
+	ctx, genSpec, parameters, warnings, err := i.resolveParameters(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return &api.PlanResponse{Errors: []error{err}, Warnings: warnings}
+	}
+
+	result := &api.PlanResponse{Success: true, PlannedFiles: i.planAllTemplates(ctx, request, genSpec, parameters, sourceDir, targetDir), Warnings: warnings}
+	for _, f := range result.PlannedFiles {
+		result.Success = result.Success && len(f.Errors) == 0
+	}
+	return result
+}
+
+func (i *GeneratorImpl) planAllTemplates(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) []api.PlannedFile {
+	plannedFiles := []api.PlannedFile{}
+	for _, tplSpec := range genSpec.Templates {
+		if isExcludedTemplate(tplSpec, genSpec.Excludes) {
+			continue
+		}
+		if !hasAnyTag(tplSpec.Tags, request.RenderTags) {
+			continue
+		}
+		plannedFiles = append(plannedFiles, i.planSingleTemplate(ctx, &tplSpec, parameters, sourceDir, targetDir)...)
+	}
+	return plannedFiles
+}
+
+// planSingleTemplate works like renderSingleTemplate, but only reads the template if it has front matter,
+// and does not render its contents.
+func (i *GeneratorImpl) planSingleTemplate(ctx context.Context, tplSpec *api.TemplateSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) []api.PlannedFile {
+	plannedError := func(err error) []api.PlannedFile {
+		return []api.PlannedFile{{RelativeSourcePath: tplSpec.RelativeSourcePath, RelativeTargetPath: tplSpec.RelativeTargetPath, Errors: []error{err}}}
+	}
+
+	if err := checkTemplateSource(tplSpec); err != nil {
+		return plannedError(err)
+	}
+	sourceName := templateSourceName(tplSpec)
+	if tplSpec.FrontMatter && !isDeleteAction(tplSpec) {
+		templateContents, err := i.loadTemplate(ctx, tplSpec, sourceDir)
+		if err != nil {
+			return plannedError(err)
+		}
+		frontMatter, _, err := templatewrapper.ParseFrontMatter(templateContents)
+		if err != nil {
+			return plannedError(fmt.Errorf("failed to parse front matter of template %s: %s", sourceName, err))
+		}
+		tplSpec = i.applyFrontMatter(tplSpec, frontMatter)
+	}
+
+	if len(tplSpec.WithItems) > 0 && len(tplSpec.WithEntries) > 0 {
+		return plannedError(fmt.Errorf("template %s must not specify both with_items and with_entries", sourceName))
+	}
+
+	templateName := strings.ReplaceAll(sourceName, "/", "_")
+	if isDeleteAction(tplSpec) {
+		templateName = deleteTemplateName(tplSpec)
+	}
+	plannedFiles := []api.PlannedFile{}
+	forEachIteration(ctx, tplSpec, parameters, func(parameters map[string]interface{}, templateNameExtension string, errorMessageItemExtension string) {
+		targetPath, condition, skip, err := i.resolveTarget(ctx, tplSpec, parameters, templateName, templateNameExtension, errorMessageItemExtension, targetDir)
+		if err == nil && condition && !skip && isDeleteAction(tplSpec) {
+			targetPath, err = resolveInsideTargetDir("delete", targetPath)
+		}
+		if err != nil {
+			plannedFiles = append(plannedFiles, api.PlannedFile{RelativeSourcePath: tplSpec.RelativeSourcePath, RelativeTargetPath: targetPath, Errors: []error{err}})
+		} else if condition {
+			plannedFiles = append(plannedFiles, api.PlannedFile{RelativeSourcePath: tplSpec.RelativeSourcePath, RelativeTargetPath: targetPath, Skipped: skip, Delete: isDeleteAction(tplSpec)})
+		}
+	})
+	return plannedFiles
+}
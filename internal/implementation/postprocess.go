@@ -0,0 +1,55 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"go/format"
+)
+
+type postProcessorsKey struct{}
+
+var builtinPostProcessors = map[string]api.PostProcessor{
+	api.PostProcessorGofmt: format.Source,
+	api.PostProcessorTrimSpace: func(output []byte) ([]byte, error) {
+		return bytes.TrimSpace(output), nil
+	},
+}
+
+// WithPostProcessors returns a context that makes processors available to all templates rendered with it,
+// in addition to the built-in ones. Post processors of the same name replace the built-in ones.
+func WithPostProcessors(ctx context.Context, processors map[string]api.PostProcessor) context.Context {
+	merged := map[string]api.PostProcessor{}
+	for name, p := range extraPostProcessors(ctx) {
+		merged[name] = p
+	}
+	for name, p := range processors {
+		merged[name] = p
+	}
+	return context.WithValue(ctx, postProcessorsKey{}, merged)
+}
+
+func extraPostProcessors(ctx context.Context) map[string]api.PostProcessor {
+	processors, _ := ctx.Value(postProcessorsKey{}).(map[string]api.PostProcessor)
+	return processors
+}
+
+// postProcess runs the output through the named post processors in the given order
+func postProcess(ctx context.Context, names []string, output []byte) ([]byte, error) {
+	for _, name := range names {
+		processor, ok := extraPostProcessors(ctx)[name]
+		if !ok {
+			processor, ok = builtinPostProcessors[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown post processor '%s'", name)
+		}
+		processed, err := processor(output)
+		if err != nil {
+			return nil, fmt.Errorf("post processor %s failed: %s", name, err)
+		}
+		output = processed
+	}
+	return output, nil
+}
@@ -0,0 +1,35 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+)
+
+// preflight plans the render run if the request asks for any checks before writing, and returns all problems found,
+// so the render run can be aborted before anything is written.
+//
+// With FailOnTargetErrors, these are the errors evaluating target paths and conditions, with PreflightWriteCheck,
+// the target paths that cannot be written.
+func (i *GeneratorImpl) preflight(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec, parameters map[string]interface{}, sourceDir *generatordir.GeneratorDirectory, targetDir *targetdir.TargetDirectory) []error {
+	if !request.FailOnTargetErrors && !request.PreflightWriteCheck {
+		return nil
+	}
+
+	var errs []error
+	checked := map[string]bool{}
+	for _, f := range i.planAllTemplates(ctx, request, genSpec, parameters, sourceDir, targetDir) {
+		if request.FailOnTargetErrors {
+			errs = append(errs, f.Errors...)
+		}
+		if !request.PreflightWriteCheck || len(f.Errors) > 0 || f.Skipped || checked[f.RelativeTargetPath] {
+			continue
+		}
+		checked[f.RelativeTargetPath] = true
+		if err := targetDir.CheckWritable(ctx, f.RelativeTargetPath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
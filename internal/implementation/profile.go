@@ -0,0 +1,61 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"sort"
+	"strings"
+)
+
+const profileParameterName = "profile"
+
+type profileKey struct{}
+
+// withProfileIfRequested makes the profile selected in the request available to default values, and returns
+// a copy of genSpec with the defaults of the profile applied. Without a profile, genSpec is returned unchanged.
+func (i *GeneratorImpl) withProfileIfRequested(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec) (context.Context, *api.GeneratorSpec, error) {
+	if request.Profile == "" {
+		return ctx, genSpec, nil
+	}
+	if _, ok := genSpec.Variables[profileParameterName]; ok {
+		return ctx, genSpec, fmt.Errorf("variable name '%s' is reserved for the selected profile, cannot select profile %s", profileParameterName, request.Profile)
+	}
+
+	result := *genSpec
+	if len(genSpec.Profiles) > 0 {
+		profile, ok := genSpec.Profiles[request.Profile]
+		if !ok {
+			names := make([]string, 0, len(genSpec.Profiles))
+			for name := range genSpec.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return ctx, genSpec, fmt.Errorf("unknown profile '%s', available profiles are: %s", request.Profile, strings.Join(names, ", "))
+		}
+
+		result.Variables = make(map[string]api.VariableSpec, len(genSpec.Variables))
+		for name, varSpec := range genSpec.Variables {
+			result.Variables[name] = varSpec
+		}
+		for name, value := range profile.Defaults {
+			varSpec, ok := result.Variables[name]
+			if !ok {
+				return ctx, genSpec, fmt.Errorf("profile %s sets a default for undeclared variable %s (this is an error in the generator spec)", request.Profile, name)
+			}
+			varSpec.DefaultValue = value
+			result.Variables[name] = varSpec
+		}
+	}
+
+	ctx = context.WithValue(ctx, profileKey{}, request.Profile)
+	ctx = withDefaultValueData(ctx, profileParameterName, request.Profile)
+	return ctx, &result, nil
+}
+
+// addProfile makes the selected profile available to all templates as .profile, if one was selected
+func addProfile(ctx context.Context, parameters map[string]interface{}) {
+	if profile, ok := ctx.Value(profileKey{}).(string); ok {
+		parameters[profileParameterName] = profile
+	}
+}
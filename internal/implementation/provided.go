@@ -0,0 +1,39 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+const providedParameterName = "provided"
+
+type providedKey struct{}
+
+// withProvidedIfRequested reserves the variable name for .provided, if the request asks for it
+func (i *GeneratorImpl) withProvidedIfRequested(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec) (context.Context, error) {
+	if !request.IncludeProvided {
+		return ctx, nil
+	}
+	if _, ok := genSpec.Variables[providedParameterName]; ok {
+		return ctx, fmt.Errorf("variable name '%s' is reserved for the provided parameters, cannot include them", providedParameterName)
+	}
+	return context.WithValue(ctx, providedKey{}, true), nil
+}
+
+// addProvided makes the names of the parameters that the render spec sets explicitly available to all templates
+// as .provided, a map from name to true, if it was requested. A parameter set to nil counts as not provided,
+// because it gets its default.
+func (i *GeneratorImpl) addProvided(ctx context.Context, genSpec *api.GeneratorSpec, renderSpec *api.RenderSpec, parameters map[string]interface{}) {
+	if requested, _ := ctx.Value(providedKey{}).(bool); !requested {
+		return
+	}
+	given, _ := i.resolveParameterAliases(ctx, genSpec, renderSpec.Parameters)
+	provided := map[string]interface{}{}
+	for name, value := range given {
+		if _, declared := genSpec.Variables[name]; declared && value != nil {
+			provided[name] = true
+		}
+	}
+	parameters[providedParameterName] = provided
+}
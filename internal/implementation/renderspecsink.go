@@ -0,0 +1,37 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"io"
+)
+
+// renderSpecSink is where a freshly constructed render spec goes, returning the file results for the response
+type renderSpecSink func(ctx context.Context, targetDir *targetdir.TargetDirectory, renderSpec *api.RenderSpec, parameterGroups map[string]string) ([]api.FileResult, error)
+
+func (i *GeneratorImpl) renderSpecFileSink(renderSpecFile string) renderSpecSink {
+	return func(ctx context.Context, targetDir *targetdir.TargetDirectory, renderSpec *api.RenderSpec, parameterGroups map[string]string) ([]api.FileResult, error) {
+		targetFile, err := targetDir.WriteRenderSpec(ctx, renderSpec, parameterGroups, renderSpecFile)
+		if err != nil {
+			return nil, err
+		}
+		return []api.FileResult{i.successFileResult(ctx, targetFile)}, nil
+	}
+}
+
+// renderSpecWriterSink writes the render spec in the same format as a file, but no file is involved,
+// so there are no file results
+func (i *GeneratorImpl) renderSpecWriterSink(w io.Writer) renderSpecSink {
+	return func(ctx context.Context, targetDir *targetdir.TargetDirectory, renderSpec *api.RenderSpec, parameterGroups map[string]string) ([]api.FileResult, error) {
+		renderSpecYaml, err := targetDir.MarshalRenderSpec(ctx, renderSpec, parameterGroups)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(renderSpecYaml); err != nil {
+			return nil, fmt.Errorf("error writing render spec: %s", err.Error())
+		}
+		return []api.FileResult{}, nil
+	}
+}
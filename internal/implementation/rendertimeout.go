@@ -0,0 +1,27 @@
+package implementation
+
+import (
+	"context"
+	"time"
+)
+
+type renderTimeoutKey struct{}
+
+func withRenderTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, renderTimeoutKey{}, timeout)
+}
+
+// renderTimeout is the maximum time to spend rendering a single file, zero means no timeout
+func renderTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(renderTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// withFileDeadline returns a context that is done once the render timeout has passed, so everything evaluated
+// for one file, from its target path and condition to its contents, counts towards the same timeout
+func withFileDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := renderTimeout(ctx); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
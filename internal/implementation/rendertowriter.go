@@ -0,0 +1,61 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"io"
+	"path"
+)
+
+func (i *GeneratorImpl) RenderToWriter(ctx context.Context, request *api.Request, targetPath string, w io.Writer) *api.Response {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return i.errorResponseToplevel(ctx, err)
+	}
+
+	// target paths are only known after evaluating them, so all templates are rendered in memory, just like CheckUpToDate
+	ctx = withParameterSource(ctx, "render spec file "+targetDir.RenderSpecFilenameOrDefault(ctx, request.RenderSpecFile))
+	capturingDir := targetDir.WithCapturedWrites()
+	response := i.renderWithRenderSpec(ctx, request, genSpec, renderSpec, sourceDir, capturingDir)
+
+	targetPath = path.Clean(targetPath)
+	for _, f := range response.RenderedFiles {
+		if f.RelativeFilePath != targetPath {
+			continue
+		}
+		if len(f.Errors) > 0 {
+			return i.withWarnings(i.errorResponseRender(ctx, []api.FileResult{f}), response.Warnings)
+		}
+		if f.Deleted {
+			return i.withWarnings(i.errorResponseToplevel(ctx, fmt.Errorf("the file with target path '%s' is deleted, so there is no content to write", targetPath)), response.Warnings)
+		}
+		if f.Skipped {
+			return i.withWarnings(i.errorResponseToplevel(ctx, fmt.Errorf("the file with target path '%s' is skipped, so there is no content to write", targetPath)), response.Warnings)
+		}
+		if _, err := w.Write(capturingDir.Captured()[targetPath]); err != nil {
+			return i.withWarnings(i.errorResponseRender(ctx, []api.FileResult{i.errorFileResult(ctx, targetPath, err)}), response.Warnings)
+		}
+		return i.withWarnings(i.successResponse(ctx, []api.FileResult{f}), response.Warnings)
+	}
+	if !response.Success {
+		return response
+	}
+	return i.withWarnings(i.errorResponseToplevel(ctx, fmt.Errorf("the generator does not render a file with target path '%s'", targetPath)), response.Warnings)
+}
@@ -0,0 +1,162 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/retry"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// reproducible build mode applies to everything rendered during a request, so it travels with the context
+// rather than being passed down to every place a template is parsed
+type reproducibleBuildKey struct{}
+
+func withReproducibleBuild(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reproducibleBuildKey{}, true)
+}
+
+func isReproducibleBuild(ctx context.Context) bool {
+	reproducible, _ := ctx.Value(reproducibleBuildKey{}).(bool)
+	return reproducible
+}
+
+// the same goes for safe defaults mode
+type safeDefaultsKey struct{}
+
+func withSafeDefaults(ctx context.Context) context.Context {
+	return context.WithValue(ctx, safeDefaultsKey{}, true)
+}
+
+func isSafeDefaults(ctx context.Context) bool {
+	safe, _ := ctx.Value(safeDefaultsKey{}).(bool)
+	return safe
+}
+
+// and for safe functions mode
+type safeFunctionsKey struct{}
+
+func withSafeFunctions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, safeFunctionsKey{}, true)
+}
+
+func isSafeFunctions(ctx context.Context) bool {
+	safe, _ := ctx.Value(safeFunctionsKey{}).(bool)
+	return safe
+}
+
+// the seeded random source is shared by all templates of a request, so repeated renders draw the same results
+type seededRandomKey struct{}
+
+func withSeededRandom(ctx context.Context, seed int64) context.Context {
+	return context.WithValue(ctx, seededRandomKey{}, templatewrapper.NewSeededRandom(seed))
+}
+
+func seededRandom(ctx context.Context) *templatewrapper.SeededRandom {
+	source, _ := ctx.Value(seededRandomKey{}).(*templatewrapper.SeededRandom)
+	return source
+}
+
+// withRequestOptions records the options of the request that apply to everything done for it in the context
+func withRequestOptions(ctx context.Context, request *api.Request) context.Context {
+	if request.ReproducibleBuild {
+		ctx = withReproducibleBuild(ctx)
+	}
+	if request.SafeDefaults {
+		ctx = withSafeDefaults(ctx)
+	}
+	if request.SafeFunctions {
+		ctx = withSafeFunctions(ctx)
+	}
+	if request.Seed != 0 {
+		ctx = withSeededRandom(ctx, request.Seed)
+	}
+	if request.ReadAttempts > 1 {
+		ctx = retry.WithPolicy(ctx, retry.Policy{Attempts: request.ReadAttempts, Backoff: request.ReadRetryBackoff})
+	}
+	if request.DetectCaseCollisions {
+		ctx = withCaseCollisionDetection(ctx)
+	}
+	if request.LowercaseTargetPaths {
+		ctx = withLowercaseTargetPaths(ctx)
+	}
+	if request.CoerceStrings {
+		ctx = withCoerceStrings(ctx)
+	}
+	if request.RenderTimeout > 0 {
+		ctx = withRenderTimeout(ctx, request.RenderTimeout)
+	}
+	if request.MaxFileBytes > 0 {
+		ctx = withMaxFileBytes(ctx, request.MaxFileBytes)
+	}
+	if request.EnsureFinalNewline {
+		ctx = withEnsureFinalNewline(ctx)
+	}
+	if request.PreserveExistingMode {
+		ctx = withPreserveExistingMode(ctx)
+	}
+	if request.GitignoreAware {
+		ctx = withGitignoreAware(ctx)
+	}
+	if request.ReportConditionSkipped {
+		ctx = withReportConditionSkipped(ctx)
+	}
+	if request.WorkingDir != "" {
+		ctx = workingdir.WithWorkingDir(ctx, request.WorkingDir)
+	}
+	if request.ItemOffset > 0 || request.ItemLimit > 0 {
+		ctx = withItemRange(ctx, request.ItemOffset, request.ItemLimit)
+	}
+	return ctx
+}
+
+func (i *GeneratorImpl) templateFuncs(ctx context.Context) template.FuncMap {
+	if isSafeFunctions(ctx) {
+		funcs := templatewrapper.SafeFuncMap()
+		for name, f := range extraFuncs(ctx) {
+			funcs[name] = f
+		}
+		return funcs
+	}
+	funcs := templatewrapper.FuncMap(isReproducibleBuild(ctx))
+	if source := seededRandom(ctx); source != nil {
+		for name, f := range source.Funcs() {
+			funcs[name] = f
+		}
+	}
+	for name, f := range extraFuncs(ctx) {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// defaultValueFuncs are the functions available when evaluating default values
+func (i *GeneratorImpl) defaultValueFuncs(ctx context.Context) template.FuncMap {
+	if !isSafeDefaults(ctx) {
+		return i.templateFuncs(ctx)
+	}
+	funcs := templatewrapper.SafeDefaultsFuncMap()
+	for name, f := range extraFuncs(ctx) {
+		funcs[name] = f
+	}
+	return funcs
+}
+
+// sourceDateEpoch obtains a fixed timestamp from the SOURCE_DATE_EPOCH environment variable, as defined by
+// https://reproducible-builds.org/specs/source-date-epoch/, falling back to the start of the unix epoch.
+func sourceDateEpoch() (time.Time, error) {
+	value, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok || value == "" {
+		return time.Unix(0, 0), nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH '%s', must be a number of seconds", value)
+	}
+	return time.Unix(seconds, 0), nil
+}
@@ -0,0 +1,32 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"strings"
+)
+
+// checkRequiredTargetFiles makes sure the files listed in requires_target_files exist in the target directory,
+// so a generator that augments an existing project fails with a clear message instead of confusing output.
+func (i *GeneratorImpl) checkRequiredTargetFiles(ctx context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}, targetDir *targetdir.TargetDirectory) error {
+	var missing []string
+	for counter, required := range genSpec.RequiresTargetFiles {
+		requiredPath, err := i.renderString(ctx, parameters, fmt.Sprintf("__requiredtargetfile_%d", counter+1), required)
+		if err != nil {
+			return fmt.Errorf("error evaluating required target file from '%s': %s", required, err)
+		}
+		requiredPath, err = resolveInsideTargetDir("require", requiredPath)
+		if err != nil {
+			return err
+		}
+		if !targetDir.Exists(ctx, requiredPath) {
+			missing = append(missing, requiredPath)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("this generator augments an existing project, but the target directory is missing required files: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// durationPattern matches what time.ParseDuration accepts. The json schema format "duration" is no substitute,
+// because it stands for ISO 8601 durations such as "PT30S".
+const durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+func (i *GeneratorImpl) ExportParameterSchema(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, error) {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, name := range sortedVariableNames(genSpec) {
+		varSpec := genSpec.Variables[name]
+		properties[name] = variableSchema(&varSpec)
+		if !varSpec.HasDefault() {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"$schema":    jsonSchemaDraft,
+		"title":      generatorName,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	result, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return []byte{}, fmt.Errorf("error converting parameter schema for generator %s to json: %s", generatorName, err.Error())
+	}
+	return append(result, '\n'), nil
+}
+
+// variableSchema maps a variable to a json schema. The type is derived from the declared type of the variable,
+// or else from the default value, if there is one.
+func variableSchema(varSpec *api.VariableSpec) map[string]interface{} {
+	result := map[string]interface{}{}
+	if varSpec.Label != "" {
+		result["title"] = varSpec.Label
+	}
+	if varSpec.Description != "" {
+		result["description"] = varSpec.Description
+	}
+	if varSpec.Type == api.VariableTypeBytes {
+		result["contentEncoding"] = "base64"
+	} else if patterns := validationPatterns(*varSpec); len(patterns) == 1 {
+		result["pattern"] = patterns[0]
+	} else if len(patterns) > 1 {
+		combined := make([]interface{}, len(patterns))
+		for k, pattern := range patterns {
+			combined[k] = map[string]interface{}{"pattern": pattern}
+		}
+		if varSpec.ValidationMode == api.ValidationModeAny {
+			result["anyOf"] = combined
+		} else {
+			result["allOf"] = combined
+		}
+	}
+	if varSpec.Type == api.VariableTypeDuration {
+		addSchemaPattern(result, durationPattern)
+	}
+	if schemaType := declaredSchemaType(varSpec); schemaType != "" {
+		result["type"] = schemaType
+	}
+	if varSpec.HasDefault() {
+		if _, typed := result["type"]; !typed {
+			if schemaType := jsonSchemaType(varSpec.DefaultValue); schemaType != "" {
+				result["type"] = schemaType
+			}
+		}
+		// a default that is a template is evaluated during rendering, so it is not a meaningful default for a form
+		if str, ok := varSpec.DefaultValue.(string); !ok || !strings.Contains(str, "{{") {
+			result["default"] = jsonCompatible(varSpec.DefaultValue)
+		}
+	}
+	addStructureSchema(result, varSpec)
+	return result
+}
+
+// addStructureSchema maps item_pattern and required_keys. Like during validation, required keys apply to each item
+// of a list, or to the value itself if it is a map.
+func addStructureSchema(schema map[string]interface{}, varSpec *api.VariableSpec) {
+	items := map[string]interface{}{}
+	if varSpec.ItemPattern != "" {
+		// only lists can have an item pattern
+		schema["type"] = "array"
+		items["pattern"] = varSpec.ItemPattern
+	}
+	if len(varSpec.RequiredKeys) > 0 {
+		keys := requiredKeysSchema(varSpec.RequiredKeys)
+		switch schema["type"] {
+		case "array":
+			items = keys
+		case "object":
+			for k, v := range keys {
+				schema[k] = v
+			}
+		default:
+			schema["oneOf"] = []interface{}{keys, map[string]interface{}{"type": "array", "items": keys}}
+		}
+	}
+	if len(items) > 0 {
+		schema["items"] = items
+	}
+}
+
+// requiredKeysSchema describes a map with the given keys. Dotted paths such as "tls.cert" require keys in nested maps.
+func requiredKeysSchema(keys []string) map[string]interface{} {
+	required := []string{}
+	nested := map[string][]string{}
+	for _, key := range keys {
+		segments := strings.SplitN(key, ".", 2)
+		if _, seen := nested[segments[0]]; !seen {
+			required = append(required, segments[0])
+			nested[segments[0]] = []string{}
+		}
+		if len(segments) > 1 {
+			nested[segments[0]] = append(nested[segments[0]], segments[1])
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "required": required}
+	properties := map[string]interface{}{}
+	for name, nestedKeys := range nested {
+		if len(nestedKeys) > 0 {
+			properties[name] = requiredKeysSchema(nestedKeys)
+		}
+	}
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	return schema
+}
+
+func declaredSchemaType(varSpec *api.VariableSpec) string {
+	switch varSpec.Type {
+	case api.VariableTypeBytes, api.VariableTypeDuration:
+		return "string"
+	case api.VariableTypeList:
+		return "array"
+	case api.VariableTypeMap:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// addSchemaPattern adds a pattern the value must match in addition to the patterns already in schema
+func addSchemaPattern(schema map[string]interface{}, pattern string) {
+	_, hasPattern := schema["pattern"]
+	_, hasAnyOf := schema["anyOf"]
+	allOf, hasAllOf := schema["allOf"].([]interface{})
+	if !hasPattern && !hasAnyOf && !hasAllOf {
+		schema["pattern"] = pattern
+		return
+	}
+	if hasPattern {
+		allOf = append(allOf, map[string]interface{}{"pattern": schema["pattern"]})
+		delete(schema, "pattern")
+	}
+	schema["allOf"] = append(allOf, map[string]interface{}{"pattern": pattern})
+}
+
+func jsonSchemaType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[interface{}]interface{}, map[string]interface{}:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// jsonCompatible converts the maps produced by the yaml parser, which json cannot encode, to maps with string keys.
+func jsonCompatible(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			result[fmt.Sprintf("%v", k)] = jsonCompatible(v)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			result[k] = jsonCompatible(v)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, v := range typed {
+			result[i] = jsonCompatible(v)
+		}
+		return result
+	default:
+		return value
+	}
+}
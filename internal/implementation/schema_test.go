@@ -0,0 +1,18 @@
+package implementation
+
+import (
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// the duration pattern in the parameter schema must agree with time.ParseDuration
+
+func TestDurationPattern_MatchesParseDuration(t *testing.T) {
+	pattern := regexp.MustCompile(durationPattern)
+	for _, value := range []string{"0", "30s", "1h30m", "-1.5h", "+.5s", "300ms", "2us", "2µs", "1h2m3s4ms5us6ns", "", "30", "s", "1d", "1h 30m", "0s0", "PT30S"} {
+		_, err := time.ParseDuration(value)
+		require.Equal(t, err == nil, pattern.MatchString(value), value)
+	}
+}
@@ -0,0 +1,75 @@
+package implementation
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/api"
+	"reflect"
+)
+
+func (i *GeneratorImpl) DiffGeneratorSpecs(_ context.Context, oldSpec *api.GeneratorSpec, newSpec *api.GeneratorSpec) *api.GeneratorSpecDiff {
+	diff := &api.GeneratorSpecDiff{
+		AddedVariables:         []string{},
+		RemovedVariables:       []string{},
+		NewlyRequiredVariables: []string{},
+		ChangedVariables:       []api.VariableChange{},
+		AddedTemplates:         []api.TemplateSpec{},
+		RemovedTemplates:       []api.TemplateSpec{},
+		ChangedTemplates:       []api.TemplateChange{},
+	}
+
+	for _, name := range sortedVariableNames(newSpec) {
+		newVar := newSpec.Variables[name]
+		oldVar, existed := oldSpec.Variables[name]
+		if !existed {
+			diff.AddedVariables = append(diff.AddedVariables, name)
+		}
+		if !newVar.HasDefault() && (!existed || oldVar.HasDefault()) {
+			diff.NewlyRequiredVariables = append(diff.NewlyRequiredVariables, name)
+		}
+		if existed && !reflect.DeepEqual(oldVar, newVar) {
+			diff.ChangedVariables = append(diff.ChangedVariables, api.VariableChange{
+				Name:               name,
+				Old:                oldVar,
+				New:                newVar,
+				DefaultChanged:     !reflect.DeepEqual(oldVar.DefaultValue, newVar.DefaultValue),
+				PatternChanged:     !reflect.DeepEqual(validationPatterns(oldVar), validationPatterns(newVar)) || oldVar.ValidationMode != newVar.ValidationMode,
+				DescriptionChanged: oldVar.Description != newVar.Description,
+			})
+		}
+	}
+	for _, name := range sortedVariableNames(oldSpec) {
+		if _, ok := newSpec.Variables[name]; !ok {
+			diff.RemovedVariables = append(diff.RemovedVariables, name)
+		}
+	}
+
+	oldTemplates := templatesByKey(oldSpec)
+	newTemplates := templatesByKey(newSpec)
+	for _, newTpl := range newSpec.Templates {
+		oldTpl, existed := oldTemplates[templateKey(newTpl)]
+		if !existed {
+			diff.AddedTemplates = append(diff.AddedTemplates, newTpl)
+		} else if !reflect.DeepEqual(oldTpl, newTpl) {
+			diff.ChangedTemplates = append(diff.ChangedTemplates, api.TemplateChange{Old: oldTpl, New: newTpl})
+		}
+	}
+	for _, oldTpl := range oldSpec.Templates {
+		if _, ok := newTemplates[templateKey(oldTpl)]; !ok {
+			diff.RemovedTemplates = append(diff.RemovedTemplates, oldTpl)
+		}
+	}
+
+	return diff
+}
+
+func templateKey(tplSpec api.TemplateSpec) string {
+	return tplSpec.RelativeSourcePath + " -> " + tplSpec.RelativeTargetPath
+}
+
+func templatesByKey(genSpec *api.GeneratorSpec) map[string]api.TemplateSpec {
+	result := map[string]api.TemplateSpec{}
+	for _, tplSpec := range genSpec.Templates {
+		result[templateKey(tplSpec)] = tplSpec
+	}
+	return result
+}
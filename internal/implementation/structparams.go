@@ -0,0 +1,87 @@
+package implementation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// structToParameterMap converts a struct (or pointer to struct) into a parameter map.
+//
+// The variable name for each exported field is taken from its yaml tag, then its json tag, and finally
+// the field name itself. Unexported fields and fields tagged "-" are ignored.
+//
+// Fields that are nil pointers or interfaces, and zero valued fields tagged omitempty, are left out, so the
+// corresponding parameter is unset and gets its default, just like a key missing from a RenderWithValues map.
+// Other pointers are dereferenced.
+func structToParameterMap(parameters interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(parameters)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, fmt.Errorf("parameters must be a struct or a pointer to a struct, got nil %T", parameters)
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("parameters must be a struct or a pointer to a struct, got %T", parameters)
+	}
+
+	result := map[string]interface{}{}
+	t := v.Type()
+	for n := 0; n < t.NumField(); n++ {
+		field := t.Field(n)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		name := parameterNameForField(field)
+		if name == "-" {
+			continue
+		}
+		if isOmitEmpty(field) && v.Field(n).IsZero() {
+			continue
+		}
+		value, ok := dereference(v.Field(n))
+		if !ok {
+			continue
+		}
+		result[name] = value.Interface()
+	}
+	return result, nil
+}
+
+// dereference follows pointers and interfaces, ok is false if it runs into nil
+func dereference(value reflect.Value) (reflect.Value, bool) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
+
+func isOmitEmpty(field reflect.StructField) bool {
+	for _, tagKey := range []string{"yaml", "json"} {
+		if tag, ok := field.Tag.Lookup(tagKey); ok {
+			for _, option := range strings.Split(tag, ",")[1:] {
+				if option == "omitempty" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func parameterNameForField(field reflect.StructField) string {
+	for _, tagKey := range []string{"yaml", "json"} {
+		if tag, ok := field.Tag.Lookup(tagKey); ok {
+			name := strings.Split(tag, ",")[0]
+			if name != "" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,79 @@
+package implementation
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"regexp"
+	"strings"
+)
+
+// validateStructure checks item_pattern and required_keys of a structured variable, reporting the path of the
+// first offending value within it, e.g. "upstreams[2].host".
+func (i *GeneratorImpl) validateStructure(varName string, varSpec api.VariableSpec, val interface{}) error {
+	if varSpec.ItemPattern != "" {
+		items, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("value for parameter '%s' must be a list, because its declaration has an item pattern", varName)
+		}
+		for idx, item := range items {
+			matches, err := regexp.MatchString(varSpec.ItemPattern, fmt.Sprintf("%v", item))
+			if err != nil {
+				return fmt.Errorf("variable declaration %s has invalid item pattern (this is an error in the generator spec, not the render request): %s", varName, err.Error())
+			}
+			if !matches {
+				return fmt.Errorf("value for parameter '%s[%d]' does not match item pattern %s", varName, idx, varSpec.ItemPattern)
+			}
+		}
+	}
+
+	if len(varSpec.RequiredKeys) > 0 {
+		if items, ok := val.([]interface{}); ok {
+			for idx, item := range items {
+				if err := checkRequiredKeys(fmt.Sprintf("%s[%d]", varName, idx), item, varSpec.RequiredKeys); err != nil {
+					return err
+				}
+			}
+		} else if err := checkRequiredKeys(varName, val, varSpec.RequiredKeys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRequiredKeys checks that val is a map containing all keys. A key may be a dotted path such as "tls.cert"
+// to require keys in nested maps.
+func checkRequiredKeys(path string, val interface{}, keys []string) error {
+	for _, key := range keys {
+		current := val
+		currentPath := path
+		for _, segment := range strings.Split(key, ".") {
+			entries, ok := toStringKeyedMap(current)
+			if !ok {
+				return fmt.Errorf("value for parameter '%s' must be a map, because its declaration has required keys", currentPath)
+			}
+			currentPath = currentPath + "." + segment
+			value, ok := entries[segment]
+			if !ok || value == nil {
+				return fmt.Errorf("parameter '%s' is required but missing", currentPath)
+			}
+			current = value
+		}
+	}
+	return nil
+}
+
+// toStringKeyedMap accepts both the maps produced by the yaml parser and maps with string keys
+func toStringKeyedMap(val interface{}) (map[string]interface{}, bool) {
+	switch typed := val.(type) {
+	case map[string]interface{}:
+		return typed, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			result[fmt.Sprintf("%v", k)] = v
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
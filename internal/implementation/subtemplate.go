@@ -0,0 +1,111 @@
+package implementation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/glob"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// templateFuncsWithRender returns the functions available to a template that is rendered to a file.
+//
+// In addition to the usual functions, these include render, which executes another source file from the
+// generator directory with the given data and returns its output, so a file can be assembled from fragments.
+//
+// Paths starting with "./" or "../" are relative to currentDir, the directory of the source file that calls render,
+// all other paths are relative to the generator directory.
+//
+// renderChain lists the source files currently being rendered, outermost first, so cycles can be detected.
+func (i *GeneratorImpl) templateFuncsWithRender(ctx context.Context, sourceDir *generatordir.GeneratorDirectory, currentDir string, renderChain []string) template.FuncMap {
+	funcs := i.templateFuncs(ctx)
+	funcs["render"] = i.renderFunc(ctx, sourceDir, currentDir, renderChain)
+	return funcs
+}
+
+// contextRenderFuncs binds render to the context of each execution, see templatewrapper.WithContextFuncs, so
+// nested templates stop when the file they are rendered into is aborted, e.g. because of its timeout.
+func (i *GeneratorImpl) contextRenderFuncs(sourceDir *generatordir.GeneratorDirectory, currentDir string, renderChain []string) func(ctx context.Context) template.FuncMap {
+	return func(ctx context.Context) template.FuncMap {
+		return template.FuncMap{"render": i.renderFunc(ctx, sourceDir, currentDir, renderChain)}
+	}
+}
+
+// renderFunc executes nested templates with the same context and size limit as the file they are rendered into,
+// so a runaway loop in a fragment is stopped just like one in the template itself
+func (i *GeneratorImpl) renderFunc(ctx context.Context, sourceDir *generatordir.GeneratorDirectory, currentDir string, renderChain []string) func(includePath string, data interface{}) (string, error) {
+	return func(includePath string, data interface{}) (string, error) {
+		relativeSourcePath, err := resolveIncludePath(currentDir, includePath)
+		if err != nil {
+			return "", err
+		}
+		if glob.MatchesAny(relativeSourcePath, renderExcludes(ctx)) {
+			return "", fmt.Errorf("cannot render %s, it is excluded by the generator spec", includePath)
+		}
+		for _, source := range renderChain {
+			if source == relativeSourcePath {
+				return "", fmt.Errorf("recursive render of %s: %s -> %s", relativeSourcePath, strings.Join(renderChain, " -> "), relativeSourcePath)
+			}
+		}
+
+		templateContents, err := sourceDir.ReadFile(ctx, relativeSourcePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to load template %s: %s", relativeSourcePath, err)
+		}
+
+		nestedChain := append(append([]string{}, renderChain...), relativeSourcePath)
+		templateName := strings.ReplaceAll(relativeSourcePath, "/", "_")
+		tmplw, err := templatewrapper.New(false, templateContents, templateName, relativeSourcePath).
+			WithFuncs(i.templateFuncsWithRender(ctx, sourceDir, path.Dir(relativeSourcePath), nestedChain)).WithCache(cache.From(ctx)).Parse()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse template %s: %s", relativeSourcePath, err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmplw.WithMaxBytes(maxFileBytes(ctx)).WriteWithContext(ctx, &buf, templateName, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+// the excludes of the generator spec also apply to source files included with render, but the generator spec
+// is not available where templates are executed
+type renderExcludesKey struct{}
+
+func withRenderExcludes(ctx context.Context, genSpec *api.GeneratorSpec) context.Context {
+	return context.WithValue(ctx, renderExcludesKey{}, genSpec.Excludes)
+}
+
+func renderExcludes(ctx context.Context) []string {
+	excludes, _ := ctx.Value(renderExcludesKey{}).([]string)
+	return excludes
+}
+
+// resolveIncludePath turns a path given to render into a clean path relative to the generator directory,
+// so each source file has exactly one name, whichever file includes it.
+func resolveIncludePath(currentDir string, includePath string) (string, error) {
+	resolved := path.Clean(includePath)
+	if strings.HasPrefix(includePath, "./") || strings.HasPrefix(includePath, "../") {
+		resolved = path.Join(currentDir, includePath)
+	}
+	if path.IsAbs(resolved) || resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return "", fmt.Errorf("cannot render %s, it is outside the generator directory", includePath)
+	}
+	return resolved, nil
+}
+
+// templateSourceDir is the directory render resolves relative paths against for a template from the generator spec.
+// Inline and bundled templates have no single source file, so for them it is the generator directory.
+func templateSourceDir(tplSpec *api.TemplateSpec) string {
+	if tplSpec.RelativeSourcePath == "" {
+		return "."
+	}
+	return path.Dir(path.Clean(tplSpec.RelativeSourcePath))
+}
@@ -0,0 +1,42 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
+	"path/filepath"
+)
+
+const targetDirParameterName = "targetDir"
+
+type targetDirKey struct{}
+
+// withTargetDirIfRequested makes the absolute path of the target directory available to default values, if the
+// request asks for it, except in safe defaults mode, because it depends on the machine.
+func (i *GeneratorImpl) withTargetDirIfRequested(ctx context.Context, request *api.Request, genSpec *api.GeneratorSpec) (context.Context, error) {
+	if !request.IncludeTargetDir {
+		return ctx, nil
+	}
+	if _, ok := genSpec.Variables[targetDirParameterName]; ok {
+		return ctx, fmt.Errorf("variable name '%s' is reserved for the target directory, cannot include it", targetDirParameterName)
+	}
+	targetDir, err := filepath.Abs(workingdir.Resolve(ctx, request.TargetBaseDir))
+	if err != nil {
+		return ctx, fmt.Errorf("error determining absolute path of target directory %s: %s", request.TargetBaseDir, err)
+	}
+	targetDir = filepath.ToSlash(targetDir)
+
+	ctx = context.WithValue(ctx, targetDirKey{}, targetDir)
+	if !isSafeDefaults(ctx) {
+		ctx = withDefaultValueData(ctx, targetDirParameterName, targetDir)
+	}
+	return ctx, nil
+}
+
+// addTargetDir makes the target directory available to all templates as .targetDir, if it was requested
+func addTargetDir(ctx context.Context, parameters map[string]interface{}) {
+	if targetDir, ok := ctx.Value(targetDirKey{}).(string); ok {
+		parameters[targetDirParameterName] = targetDir
+	}
+}
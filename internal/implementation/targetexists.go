@@ -0,0 +1,36 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// conditionFuncs returns the functions available in the condition of a template.
+//
+// In addition to the usual functions, these include targetExists, which reports whether a file or directory exists
+// at the given path relative to the target directory, so e.g. a default config file is only generated if it is absent.
+func (i *GeneratorImpl) conditionFuncs(ctx context.Context, targetDir *targetdir.TargetDirectory) template.FuncMap {
+	funcs := i.templateFuncs(ctx)
+	funcs["targetExists"] = func(relativePath string) (bool, error) {
+		resolved, err := resolveInsideTargetDir("check", relativePath)
+		if err != nil {
+			return false, err
+		}
+		return targetDir.Exists(ctx, resolved), nil
+	}
+	return funcs
+}
+
+// resolveInsideTargetDir makes sure targetExists cannot be used to probe for files outside the target directory,
+// and delete templates cannot remove them. operation names what would have been done in the error message.
+func resolveInsideTargetDir(operation string, relativePath string) (string, error) {
+	resolved := path.Clean(relativePath)
+	if path.IsAbs(resolved) || resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return relativePath, fmt.Errorf("cannot %s %s, it is outside the target directory", operation, relativePath)
+	}
+	return resolved, nil
+}
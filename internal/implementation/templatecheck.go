@@ -0,0 +1,126 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/implementation/templatewrapper"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateText is a template file of the generator, or a field of its spec that is evaluated as a template
+type templateText struct {
+	// what it is, for error messages, e.g. "condition of template config.yaml.tmpl"
+	what string
+
+	// named as it is during rendering, so messages match
+	name     string
+	funcs    template.FuncMap
+	contents string
+
+	// the directory that paths given to render are relative to, for template files
+	dir string
+}
+
+func (t templateText) parse() (*template.Template, error) {
+	return template.New(t.name).Funcs(t.funcs).Parse(t.contents)
+}
+
+// validateTemplates parses every template of the generator, and every field that is evaluated as a template,
+// with the same functions that are available during rendering, so undefined functions and syntax errors are
+// reported for the whole generator at once, instead of one file at a time during rendering.
+//
+// Nothing is executed, so errors that depend on the parameters, such as a missing map key, are not found.
+// Templates that specify both with_items and with_entries are reported as well.
+func (i *GeneratorImpl) validateTemplates(ctx context.Context, genSpec *api.GeneratorSpec, sourceDir *generatordir.GeneratorDirectory) []error {
+	errs := []error{}
+	for _, tplSpec := range genSpec.Templates {
+		if len(tplSpec.WithItems) > 0 && len(tplSpec.WithEntries) > 0 {
+			errs = append(errs, fmt.Errorf("template %s must not specify both with_items and with_entries", templateSourceName(&tplSpec)))
+		}
+	}
+	i.forEachTemplateText(ctx, genSpec, sourceDir, func(text templateText) {
+		if _, err := text.parse(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s: %s", text.what, err))
+		}
+	}, func(err error) {
+		errs = append(errs, err)
+	})
+	return errs
+}
+
+// forEachTemplateText calls visit for every nonempty template text of the generator, in the order of the generator
+// spec, and fail for every template that cannot even be loaded.
+func (i *GeneratorImpl) forEachTemplateText(ctx context.Context, genSpec *api.GeneratorSpec, sourceDir *generatordir.GeneratorDirectory, visit func(text templateText), fail func(err error)) {
+	add := func(what string, templateName string, funcs template.FuncMap, contents string) {
+		if contents != "" {
+			visit(templateText{what: what, name: templateName, funcs: funcs, contents: contents})
+		}
+	}
+
+	for _, tplSpec := range genSpec.Templates {
+		if isExcludedTemplate(tplSpec, genSpec.Excludes) {
+			continue
+		}
+		tplSpec := &tplSpec
+		if isDeleteAction(tplSpec) {
+			templateName := deleteTemplateName(tplSpec)
+			if err := checkTemplateSource(tplSpec); err != nil {
+				fail(err)
+			}
+			add("target of delete template "+tplSpec.RelativeTargetPath, templateName+"_path", i.templateFuncs(ctx), tplSpec.RelativeTargetPath)
+			add("condition of delete template "+tplSpec.RelativeTargetPath, templateName+"_condition", i.conditionFuncs(ctx, nil), tplSpec.Condition)
+			continue
+		}
+		sourceName := templateSourceName(tplSpec)
+		templateContents, err := i.loadTemplate(ctx, tplSpec, sourceDir)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if tplSpec.FrontMatter {
+			frontMatter, body, err := templatewrapper.ParseFrontMatter(templateContents)
+			if err != nil {
+				fail(fmt.Errorf("failed to parse front matter of template %s: %s", sourceName, err))
+				continue
+			}
+			tplSpec = i.applyFrontMatter(tplSpec, frontMatter)
+			templateContents = body
+		}
+
+		templateName := strings.ReplaceAll(sourceName, "/", "_")
+		if !tplSpec.JustCopy {
+			visit(templateText{
+				what:     "template " + sourceName,
+				name:     templateName,
+				funcs:    i.templateFuncsWithRender(ctx, sourceDir, templateSourceDir(tplSpec), []string{sourceName}),
+				contents: string(templatewrapper.StripBOM(templateContents)),
+				dir:      templateSourceDir(tplSpec),
+			})
+		}
+		add("target of template "+sourceName, templateName+"_path", i.templateFuncs(ctx), tplSpec.RelativeTargetPath)
+		// the target directory is only needed to call targetExists, not to parse a condition that uses it
+		add("condition of template "+sourceName, templateName+"_condition", i.conditionFuncs(ctx, nil), tplSpec.Condition)
+		add("skip_if_target_exists of template "+sourceName, templateName+"_skipiftargetexists", i.templateFuncs(ctx), tplSpec.SkipIfTargetExists)
+	}
+
+	for counter, required := range genSpec.RequiresTargetFiles {
+		add(fmt.Sprintf("requires_target_files entry #%d", counter+1), fmt.Sprintf("__requiredtargetfile_%d", counter+1), i.templateFuncs(ctx), required)
+	}
+	groups := make([]string, 0, len(genSpec.GroupConditions))
+	for group := range genSpec.GroupConditions {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		add("condition of group "+group, "__groupcondition_"+group, i.templateFuncs(ctx), genSpec.GroupConditions[group])
+	}
+	for _, varName := range sortedVariableNames(genSpec) {
+		for counter, conditional := range genSpec.Variables[varName].DefaultWhen {
+			add(fmt.Sprintf("default_when condition #%d of variable %s", counter+1, varName), fmt.Sprintf("__defaultwhen_%s_%d", varName, counter+1), i.defaultValueFuncs(ctx), conditional.Condition)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package templatewrapper
+
+import "bytes"
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// StripBOM removes a leading UTF-8 byte order mark, as often found in files saved by Windows editors.
+func StripBOM(content []byte) []byte {
+	return bytes.TrimPrefix(content, utf8BOM)
+}
+
+// AddBOM prefixes content with a UTF-8 byte order mark, unless it already starts with one.
+func AddBOM(content []byte) []byte {
+	if bytes.HasPrefix(content, utf8BOM) {
+		return content
+	}
+	return append(append([]byte{}, utf8BOM...), content...)
+}
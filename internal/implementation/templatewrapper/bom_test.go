@@ -0,0 +1,25 @@
+package templatewrapper
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStripBOM(t *testing.T) {
+	require.Equal(t, "hello", string(StripBOM([]byte("\xEF\xBB\xBFhello"))))
+	require.Equal(t, "hello", string(StripBOM([]byte("hello"))))
+}
+
+func TestAddBOM(t *testing.T) {
+	require.Equal(t, "\xEF\xBB\xBFhello", string(AddBOM([]byte("hello"))))
+	require.Equal(t, "\xEF\xBB\xBFhello", string(AddBOM([]byte("\xEF\xBB\xBFhello"))))
+}
+
+func TestParse_ShouldIgnoreBOM(t *testing.T) {
+	tmplw, err := New(false, []byte("\xEF\xBB\xBF{{ .name }}"), "bom", "bom.tmpl").Parse()
+	require.Nil(t, err)
+	var buf bytes.Buffer
+	require.Nil(t, tmplw.Write(&buf, "bom", map[string]interface{}{"name": "world"}))
+	require.Equal(t, "world", buf.String())
+}
@@ -0,0 +1,63 @@
+package templatewrapper
+
+import (
+	"bytes"
+	"errors"
+	"gopkg.in/yaml.v2"
+)
+
+var errUnterminatedFrontMatter = errors.New("front matter is missing its closing '---' line")
+
+// FrontMatter is an optional yaml block at the top of a template file, between two lines of "---".
+//
+// Fields that are set override the corresponding fields of the TemplateSpec.
+type FrontMatter struct {
+	RelativeTargetPath string `yaml:"target"`
+	Condition          string `yaml:"condition"`
+	JustCopy           *bool  `yaml:"just_copy"`
+}
+
+// ParseFrontMatter splits a template into its front matter and its body.
+//
+// If the template does not start with a "---" line, the front matter is empty and the body is the whole template.
+// The delimiter lines may end in "\n" or "\r\n", the body is returned with its line endings unchanged.
+func ParseFrontMatter(templateContent []byte) (*FrontMatter, []byte, error) {
+	frontMatter := &FrontMatter{}
+	rest, ok := cutDelimiterLine(StripBOM(templateContent))
+	if !ok {
+		return frontMatter, templateContent, nil
+	}
+
+	var frontMatterYaml, body []byte
+	for lineStart := 0; ; {
+		if after, ok := cutDelimiterLine(rest[lineStart:]); ok {
+			frontMatterYaml = rest[:lineStart]
+			body = after
+			break
+		}
+		lineEnd := bytes.IndexByte(rest[lineStart:], '\n')
+		if lineEnd < 0 {
+			return frontMatter, templateContent, errUnterminatedFrontMatter
+		}
+		lineStart += lineEnd + 1
+	}
+
+	frontMatterYaml = bytes.ReplaceAll(frontMatterYaml, []byte("\r\n"), []byte("\n"))
+	if err := yaml.UnmarshalStrict(frontMatterYaml, frontMatter); err != nil {
+		return &FrontMatter{}, templateContent, err
+	}
+	return frontMatter, body, nil
+}
+
+// cutDelimiterLine returns what follows if content starts with a "---" line
+func cutDelimiterLine(content []byte) ([]byte, bool) {
+	for _, delimiter := range []string{"---\n", "---\r\n"} {
+		if bytes.HasPrefix(content, []byte(delimiter)) {
+			return content[len(delimiter):], true
+		}
+	}
+	if string(content) == "---" {
+		return []byte{}, true
+	}
+	return nil, false
+}
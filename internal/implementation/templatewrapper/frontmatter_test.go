@@ -0,0 +1,54 @@
+package templatewrapper
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseFrontMatter_None(t *testing.T) {
+	frontMatter, body, err := ParseFrontMatter([]byte("hello {{ .name }}\n---\n"))
+	require.Nil(t, err)
+	require.Equal(t, &FrontMatter{}, frontMatter)
+	require.Equal(t, "hello {{ .name }}\n---\n", string(body))
+}
+
+func TestParseFrontMatter_Full(t *testing.T) {
+	frontMatter, body, err := ParseFrontMatter([]byte("---\r\ntarget: 'out.txt'\r\ncondition: '{{ .flag }}'\r\njust_copy: true\r\n---\r\nbody\r\n"))
+	require.Nil(t, err)
+	justCopy := true
+	require.Equal(t, &FrontMatter{RelativeTargetPath: "out.txt", Condition: "{{ .flag }}", JustCopy: &justCopy}, frontMatter)
+	require.Equal(t, "body\r\n", string(body))
+}
+
+func TestParseFrontMatter_ShouldKeepLineEndingsOfBody(t *testing.T) {
+	frontMatter, body, err := ParseFrontMatter([]byte("---\r\ntarget: out.bat\r\n---\r\n@echo off\r\nset x=1\nrem mixed\r\n"))
+	require.Nil(t, err)
+	require.Equal(t, "out.bat", frontMatter.RelativeTargetPath)
+	require.Equal(t, "@echo off\r\nset x=1\nrem mixed\r\n", string(body))
+}
+
+func TestParseFrontMatter_Empty(t *testing.T) {
+	frontMatter, body, err := ParseFrontMatter([]byte("---\n---\nbody\n"))
+	require.Nil(t, err)
+	require.Equal(t, &FrontMatter{}, frontMatter)
+	require.Equal(t, "body\n", string(body))
+}
+
+func TestParseFrontMatter_NoBody(t *testing.T) {
+	frontMatter, body, err := ParseFrontMatter([]byte("---\ntarget: out.txt\n---"))
+	require.Nil(t, err)
+	require.Equal(t, "out.txt", frontMatter.RelativeTargetPath)
+	require.Empty(t, body)
+}
+
+func TestParseFrontMatter_Unterminated(t *testing.T) {
+	_, _, err := ParseFrontMatter([]byte("---\ntarget: out.txt\n"))
+	require.NotNil(t, err)
+	require.Equal(t, "front matter is missing its closing '---' line", err.Error())
+}
+
+func TestParseFrontMatter_UnknownKey(t *testing.T) {
+	_, _, err := ParseFrontMatter([]byte("---\nsomething: weird\n---\n"))
+	require.NotNil(t, err)
+	require.Equal(t, "yaml: unmarshal errors:\n  line 1: field something not found in type templatewrapper.FrontMatter", err.Error())
+}
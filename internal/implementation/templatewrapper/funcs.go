@@ -0,0 +1,81 @@
+package templatewrapper
+
+import (
+	"fmt"
+	"github.com/Masterminds/sprig"
+	"sort"
+	"text/template"
+)
+
+// sprig functions whose result differs between runs
+var nondeterministicFuncs = []string{
+	"now", "ago",
+	"randAlphaNum", "randAlpha", "randAscii", "randNumeric", "shuffle", "uuidv4",
+	"genPrivateKey", "genCA", "genSelfSignedCert", "genSignedCert", "encryptAES",
+}
+
+// sprig functions whose result depends on the host
+var hostDependentFuncs = []string{"env", "expandenv", "getHostByName"}
+
+// FuncMap returns the functions available in templates.
+//
+// If reproducible is set, functions with nondeterministic results fail with an error when called,
+// and keys and values return their results in sorted key order.
+func FuncMap(reproducible bool) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	if reproducible {
+		for _, name := range nondeterministicFuncs {
+			funcs[name] = unavailableIn(name, "reproducible build mode")
+		}
+		funcs["keys"] = sortedKeys
+		funcs["values"] = valuesInKeyOrder
+	}
+	return funcs
+}
+
+// SafeDefaultsFuncMap returns the functions available in default value templates in safe defaults mode.
+//
+// These are the same as for FuncMap(true), except that all functions whose result depends on the host
+// or differs between runs fail with an error when called, so defaults cannot depend on host state.
+func SafeDefaultsFuncMap() template.FuncMap {
+	return restrictedFuncMap("default values in safe defaults mode")
+}
+
+// SafeFuncMap returns the functions available in safe functions mode. They are restricted like in
+// SafeDefaultsFuncMap, so templates from untrusted input cannot read the environment of the host.
+func SafeFuncMap() template.FuncMap {
+	return restrictedFuncMap("safe functions mode")
+}
+
+func restrictedFuncMap(mode string) template.FuncMap {
+	funcs := FuncMap(true)
+	for _, name := range append(append([]string{}, nondeterministicFuncs...), hostDependentFuncs...) {
+		funcs[name] = unavailableIn(name, mode)
+	}
+	return funcs
+}
+
+func unavailableIn(name string, mode string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("function %s is not available in %s", name, mode)
+	}
+}
+
+func sortedKeys(dicts ...map[string]interface{}) []string {
+	keys := []string{}
+	for _, dict := range dicts {
+		for k := range dict {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func valuesInKeyOrder(dict map[string]interface{}) []interface{} {
+	values := []interface{}{}
+	for _, k := range sortedKeys(dict) {
+		values = append(values, dict[k])
+	}
+	return values
+}
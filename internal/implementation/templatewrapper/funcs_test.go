@@ -0,0 +1,65 @@
+package templatewrapper
+
+import (
+	"bytes"
+	"github.com/Masterminds/sprig"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"text/template"
+)
+
+// sprig functions whose result only depends on their arguments. Together with nondeterministicFuncs and
+// hostDependentFuncs, this must list every sprig function, so functions added by a sprig upgrade get classified.
+var deterministicFuncs = []string{
+	"abbrev", "abbrevboth", "add", "add1", "adler32sum", "append", "atoi", "b32dec", "b32enc", "b64dec", "b64enc",
+	"base", "biggest", "buildCustomCert", "camelcase", "cat", "ceil", "clean", "coalesce", "compact", "concat",
+	"contains", "date", "dateInZone", "dateModify", "date_in_zone", "date_modify", "decryptAES", "deepCopy",
+	"deepEqual", "default", "derivePassword", "dict", "dir", "div", "empty", "ext", "fail", "first", "float64",
+	"floor", "has", "hasKey", "hasPrefix", "hasSuffix", "hello", "htmlDate", "htmlDateInZone", "indent", "initial",
+	"initials", "int", "int64", "isAbs", "join", "kebabcase", "keys", "kindIs", "kindOf", "last", "list", "lower",
+	"max", "merge", "mergeOverwrite", "min", "mod", "mul", "nindent", "nospace", "omit", "pick", "pluck", "plural",
+	"prepend", "push", "quote", "regexFind", "regexFindAll", "regexMatch", "regexReplaceAll",
+	"regexReplaceAllLiteral", "regexSplit", "repeat", "replace", "rest", "reverse", "round", "semver",
+	"semverCompare", "set", "sha1sum", "sha256sum", "slice", "snakecase", "sortAlpha", "split", "splitList",
+	"splitn", "squote", "sub", "substr", "swapcase", "ternary", "title", "toDate", "toDecimal", "toJson",
+	"toPrettyJson", "toString", "toStrings", "trim", "trimAll", "trimPrefix", "trimSuffix", "trimall", "trunc",
+	"tuple", "typeIs", "typeIsLike", "typeOf", "uniq", "unixEpoch", "unset", "until", "untilStep", "untitle",
+	"upper", "urlJoin", "urlParse", "values", "without", "wrap", "wrapWith",
+}
+
+func TestFuncMap_ShouldClassifyEverySprigFunction(t *testing.T) {
+	classified := map[string]bool{}
+	for _, names := range [][]string{nondeterministicFuncs, hostDependentFuncs, deterministicFuncs} {
+		for _, name := range names {
+			classified[name] = true
+		}
+	}
+	for name := range sprig.TxtFuncMap() {
+		require.True(t, classified[name], "sprig function %s is not classified as deterministic or not", name)
+	}
+}
+
+func TestFuncMap_ShouldDisableNondeterministicFunctionsInReproducibleMode(t *testing.T) {
+	funcs := FuncMap(true)
+	for _, name := range nondeterministicFuncs {
+		_, err := funcs[name].(func(...interface{}) (interface{}, error))()
+		require.NotNil(t, err, name)
+		require.Equal(t, "function "+name+" is not available in reproducible build mode", err.Error())
+	}
+}
+
+func TestFuncMap_ShouldNotEncryptInReproducibleMode(t *testing.T) {
+	tmpl := template.Must(template.New("encrypt").Funcs(FuncMap(true)).Parse(`{{ encryptAES "secretkey" "plaintext" }}`))
+	err := tmpl.Execute(&bytes.Buffer{}, nil)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "function encryptAES is not available in reproducible build mode")
+}
+
+func TestSafeFuncMap_ShouldDisableHostDependentFunctions(t *testing.T) {
+	funcs := SafeFuncMap()
+	for _, name := range hostDependentFuncs {
+		_, err := funcs[name].(func(...interface{}) (interface{}, error))()
+		require.NotNil(t, err, name)
+		require.Equal(t, "function "+name+" is not available in safe functions mode", err.Error())
+	}
+}
@@ -0,0 +1,27 @@
+package templatewrapper
+
+import (
+	"context"
+	"reflect"
+	"text/template"
+)
+
+// interruptible wraps each function so it panics with the error of ctx once ctx is done, instead of being called.
+// text/template reports the panic as an execution error, so a template that keeps calling functions without
+// producing output, e.g. in a long loop, still stops soon after its execution was given up.
+func interruptible(ctx context.Context, funcs template.FuncMap) template.FuncMap {
+	wrapped := make(template.FuncMap, len(funcs))
+	for name, f := range funcs {
+		fn := reflect.ValueOf(f)
+		wrapped[name] = reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+			if err := ctx.Err(); err != nil {
+				panic(err)
+			}
+			if fn.Type().IsVariadic() {
+				return fn.CallSlice(args)
+			}
+			return fn.Call(args)
+		}).Interface()
+	}
+	return wrapped
+}
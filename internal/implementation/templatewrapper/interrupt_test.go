@@ -0,0 +1,30 @@
+package templatewrapper
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWriteWithContext_ShouldStopAbortedExecutionAtNextFunctionCall(t *testing.T) {
+	var calls int64
+	funcs := FuncMap(false)
+	funcs["count"] = func() string {
+		atomic.AddInt64(&calls, 1)
+		return ""
+	}
+	tmplw, err := New(false, []byte("{{ range until 100000 }}{{ range until 100000 }}{{ $x := count }}{{ end }}{{ end }}"), "loop", "loop.tmpl").WithFuncs(funcs).Parse()
+	require.Nil(t, err)
+
+	err = tmplw.WithTimeout(20*time.Millisecond).WriteWithContext(context.TODO(), ioutil.Discard, "loop", nil)
+	require.EqualError(t, err, "aborted executing template loop.tmpl: context deadline exceeded")
+
+	// the loop produces no output, so only the function calls can stop it
+	time.Sleep(20 * time.Millisecond)
+	stopped := atomic.LoadInt64(&calls)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, stopped, atomic.LoadInt64(&calls))
+}
@@ -0,0 +1,31 @@
+package templatewrapper
+
+import (
+	"fmt"
+	"io"
+)
+
+// OutputLimitError is returned by Write and WriteWithContext when the output exceeds the limit set with WithMaxBytes.
+type OutputLimitError struct {
+	Limit int64
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("rendered output exceeds limit of %d bytes", e.Limit)
+}
+
+// limitedWriter fails as soon as more than remaining bytes are written, so template execution stops early
+// instead of producing a runaway file in memory
+type limitedWriter struct {
+	w         io.Writer
+	limit     int64
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, &OutputLimitError{Limit: l.limit}
+	}
+	l.remaining -= int64(len(p))
+	return l.w.Write(p)
+}
@@ -0,0 +1,78 @@
+package templatewrapper
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"text/template"
+)
+
+const (
+	alphaChars   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numericChars = "0123456789"
+)
+
+// SeededRandom is a deterministic replacement for the randomness behind sprig's random functions.
+// It is safe for concurrent use.
+type SeededRandom struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewSeededRandom returns a SeededRandom that produces the same sequence of results for the same seed.
+func NewSeededRandom(seed int64) *SeededRandom {
+	return &SeededRandom{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Funcs returns replacements for the sprig functions randAlphaNum, randAlpha, randAscii, randNumeric,
+// shuffle and uuidv4, which draw from this source instead of a cryptographic random source.
+func (s *SeededRandom) Funcs() template.FuncMap {
+	return template.FuncMap{
+		"randAlphaNum": func(count int) string { return s.randomString(count, alphaChars+numericChars) },
+		"randAlpha":    func(count int) string { return s.randomString(count, alphaChars) },
+		"randAscii":    func(count int) string { return s.randomString(count, asciiChars()) },
+		"randNumeric":  func(count int) string { return s.randomString(count, numericChars) },
+		"shuffle":      s.shuffle,
+		"uuidv4":       s.uuidv4,
+	}
+}
+
+func (s *SeededRandom) randomString(count int, chars string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]byte, count)
+	for k := range result {
+		result[k] = chars[s.rnd.Intn(len(chars))]
+	}
+	return string(result)
+}
+
+func (s *SeededRandom) shuffle(str string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runes := []rune(str)
+	s.rnd.Shuffle(len(runes), func(a int, b int) {
+		runes[a], runes[b] = runes[b], runes[a]
+	})
+	return string(runes)
+}
+
+// uuidv4 formats random bytes as a version 4 uuid, like sprig does
+func (s *SeededRandom) uuidv4() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := make([]byte, 16)
+	_, _ = s.rnd.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// asciiChars are the printable ascii characters, which randAscii chooses from
+func asciiChars() string {
+	chars := make([]byte, 0, 126-32+1)
+	for c := byte(32); c <= 126; c++ {
+		chars = append(chars, c)
+	}
+	return string(chars)
+}
@@ -1,9 +1,13 @@
 package templatewrapper
 
 import (
-	"github.com/Masterminds/sprig"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
 	"io"
 	"text/template"
+	"time"
 )
 
 type TemplateWrapper struct {
@@ -12,6 +16,11 @@ type TemplateWrapper struct {
 	templateName    string
 	templatePath    string
 	tmpl            *template.Template
+	timeout         time.Duration
+	maxBytes        int64
+	funcs           template.FuncMap
+	contextFuncs    func(ctx context.Context) template.FuncMap
+	cache           *cache.Cache
 }
 
 // New allocates a new templateWrapper with the given name.
@@ -25,7 +34,20 @@ func New(isRawFile bool, templateContent []byte, templateName string, templatePa
 	return t
 }
 
-func (i *TemplateWrapper) Write(wr io.Writer, name string, data interface{}) error {
+// Write executes the template, or just copies it for raw files.
+//
+// A panic during template execution (e.g. in a template function) is recovered and returned as an error,
+// so one bad template cannot crash the process.
+func (i *TemplateWrapper) Write(wr io.Writer, name string, data interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while executing template %s: %v", i.templatePath, r)
+		}
+	}()
+
+	if i.maxBytes > 0 {
+		wr = &limitedWriter{w: wr, limit: i.maxBytes, remaining: i.maxBytes}
+	}
 	if i.isRawFile {
 		_, err := wr.Write(i.templateContent)
 		return err
@@ -34,9 +56,144 @@ func (i *TemplateWrapper) Write(wr io.Writer, name string, data interface{}) err
 	}
 }
 
+// WithFuncs sets the functions available to the template, which must happen before parsing.
+// Defaults to FuncMap(false).
+func (i *TemplateWrapper) WithFuncs(funcs template.FuncMap) *TemplateWrapper {
+	i.funcs = funcs
+	return i
+}
+
+// WithCache makes Parse reuse a template parsed before from the same content with functions of the same names.
+// A nil cache, the default, means templates are always parsed.
+func (i *TemplateWrapper) WithCache(c *cache.Cache) *TemplateWrapper {
+	i.cache = c
+	return i
+}
+
+// WithTimeout sets the maximum time WriteWithContext may take. Zero means no timeout.
+func (i *TemplateWrapper) WithTimeout(timeout time.Duration) *TemplateWrapper {
+	i.timeout = timeout
+	return i
+}
+
+// WithMaxBytes sets the maximum number of bytes Write and WriteWithContext may produce. If the output gets larger,
+// execution stops and an *OutputLimitError is returned. Zero means no limit.
+func (i *TemplateWrapper) WithMaxBytes(maxBytes int64) *TemplateWrapper {
+	i.maxBytes = maxBytes
+	return i
+}
+
+// WithContextFuncs sets functions that need the context of an execution, such as functions that execute other
+// templates, which must stop when the execution is aborted. WriteWithContext replaces the functions of the same
+// names with the ones returned for its context, which includes the timeout. They must also be set with WithFuncs,
+// so the template can be parsed.
+func (i *TemplateWrapper) WithContextFuncs(contextFuncs func(ctx context.Context) template.FuncMap) *TemplateWrapper {
+	i.contextFuncs = contextFuncs
+	return i
+}
+
+// WriteWithContext is like Write, but gives up when the context is done or the timeout has passed,
+// and returns an error instead.
+//
+// Template execution cannot be interrupted from the outside, so it is run in a separate goroutine. That goroutine
+// stops at the next attempt to write output or to call a template function after giving up. Data must not be
+// modified by the caller until the goroutine has finished, so pass a copy if in doubt.
+func (i *TemplateWrapper) WriteWithContext(ctx context.Context, wr io.Writer, name string, data interface{}) error {
+	if i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		// context can never be cancelled, no need for a goroutine
+		return i.Write(wr, name, data)
+	}
+	bound, err := i.boundTo(ctx)
+	if err != nil {
+		return err
+	}
+
+	buf := &contextWriter{ctx: ctx}
+	done := make(chan error, 1)
+	go func() {
+		done <- bound.Write(buf, name, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		_, err = wr.Write(buf.buf.Bytes())
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("aborted executing template %s: %w", i.templatePath, ctx.Err())
+	}
+}
+
+// boundTo returns a copy of the wrapper whose template functions fail once ctx is done, see interruptible, and
+// whose context functions are bound to ctx. The parsed template may be shared, so it is copied before the
+// functions are replaced.
+func (i *TemplateWrapper) boundTo(ctx context.Context) (*TemplateWrapper, error) {
+	if i.tmpl == nil {
+		return i, nil
+	}
+	funcs := template.FuncMap{}
+	if i.funcs == nil {
+		funcs = FuncMap(false)
+	}
+	for name, f := range i.funcs {
+		funcs[name] = f
+	}
+	if i.contextFuncs != nil {
+		for name, f := range i.contextFuncs(ctx) {
+			funcs[name] = f
+		}
+	}
+	tmpl, err := i.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	bound := *i
+	bound.tmpl = tmpl.Funcs(interruptible(ctx, funcs))
+	return &bound, nil
+}
+
+// contextWriter buffers output, but fails as soon as its context is done, so template execution stops early.
+type contextWriter struct {
+	ctx context.Context
+	buf bytes.Buffer
+}
+
+func (w *contextWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.buf.Write(p)
+}
+
+// Parse parses the template, unless it is a raw file. A leading UTF-8 byte order mark is ignored.
 func (i *TemplateWrapper) Parse() (*TemplateWrapper, error) {
 	if !i.isRawFile && i.tmpl == nil {
-		tmpl, err := template.New(i.templateName).Funcs(sprig.TxtFuncMap()).Parse(string(i.templateContent))
+		funcs := i.funcs
+		if funcs == nil {
+			funcs = FuncMap(false)
+		}
+		cacheKey := i.templateName + "\x00" + i.templatePath
+		if i.cache != nil {
+			if tmpl, ok := i.cache.Template(cacheKey, i.templateContent, funcs); ok {
+				i.tmpl = tmpl
+				return i, nil
+			}
+		}
+		tmpl, err := template.New(i.templateName).Funcs(funcs).Parse(string(StripBOM(i.templateContent)))
+		if err == nil && i.cache != nil {
+			i.cache.PutTemplate(cacheKey, i.templateContent, funcs, tmpl)
+			// the cached template itself must not be executed
+			if tmpl, err = tmpl.Clone(); err != nil {
+				return i, err
+			}
+		}
 		i.tmpl = tmpl
 		return i, err
 	}
@@ -0,0 +1,234 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"path"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+func (i *GeneratorImpl) FindUnusedVariables(ctx context.Context, request *api.Request) *api.UnusedVariablesResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return &api.UnusedVariablesResponse{Errors: []error{err}}
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return &api.UnusedVariablesResponse{Errors: []error{err}}
+	}
+
+	refs := &variableReferences{names: map[string]bool{}, rendered: map[string]bool{}}
+	errs := []error{}
+	i.forEachTemplateText(ctx, genSpec, sourceDir, func(text templateText) {
+		tmpl, err := text.parse()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %s: %s", text.what, err))
+			return
+		}
+		if err := i.collectReferences(ctx, refs, tmpl, text.dir, sourceDir); err != nil {
+			errs = append(errs, err)
+		}
+	}, func(err error) {
+		errs = append(errs, err)
+	})
+	if len(errs) > 0 {
+		return &api.UnusedVariablesResponse{Errors: errs}
+	}
+
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, renderSpec.Parameters)
+	result := &api.UnusedVariablesResponse{
+		Success:         true,
+		UnusedVariables: []string{},
+		SetInRenderSpec: []string{},
+		Warnings:        warnings,
+	}
+	if refs.all {
+		return result
+	}
+	for _, name := range sortedVariableNames(genSpec) {
+		if refs.names[name] {
+			continue
+		}
+		result.UnusedVariables = append(result.UnusedVariables, name)
+		if _, ok := parameters[name]; ok {
+			result.SetInRenderSpec = append(result.SetInRenderSpec, name)
+		}
+	}
+	return result
+}
+
+// variableReferences collects the top level names that templates refer to.
+//
+// The analysis errs on the side of finding too many references, so no variable is reported unused that is
+// actually used. For example, fields inside range and with are counted too, although they usually refer to
+// the current element instead, and passing the whole data on to a function counts as referring to everything.
+type variableReferences struct {
+	names map[string]bool
+
+	// set if a template uses the data as a whole, e.g. {{ toYaml . }}, so any variable may be used
+	all bool
+
+	// source files of templates included with render that were analyzed already, to stop at cycles
+	rendered map[string]bool
+
+	// literal paths given to render, relative to the template that calls it, collected while walking
+	renderPaths []string
+}
+
+// collectReferences adds the references of all templates defined in tmpl, and of the source files they include
+// with render, as long as the path is given literally.
+func (i *GeneratorImpl) collectReferences(ctx context.Context, refs *variableReferences, tmpl *template.Template, dir string, sourceDir *generatordir.GeneratorDirectory) error {
+	refs.renderPaths = nil
+	for _, defined := range tmpl.Templates() {
+		if defined.Tree != nil {
+			refs.walk(defined.Tree.Root, true)
+		}
+	}
+
+	includePaths := refs.renderPaths
+	for _, includePath := range includePaths {
+		relativeSourcePath, err := resolveIncludePath(dir, includePath)
+		if err != nil {
+			return err
+		}
+		if refs.rendered[relativeSourcePath] {
+			continue
+		}
+		refs.rendered[relativeSourcePath] = true
+
+		templateContents, err := sourceDir.ReadFile(ctx, relativeSourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to load template %s: %s", relativeSourcePath, err)
+		}
+		text := templateText{
+			name:     strings.ReplaceAll(relativeSourcePath, "/", "_"),
+			funcs:    i.templateFuncsWithRender(ctx, sourceDir, path.Dir(relativeSourcePath), nil),
+			contents: string(templateContents),
+		}
+		included, err := text.parse()
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %s", relativeSourcePath, err)
+		}
+		if err := i.collectReferences(ctx, refs, included, path.Dir(relativeSourcePath), sourceDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk visits a node of a parse tree. dotIsRoot tells whether dot is the data the template was executed with,
+// rather than the value of an enclosing range or with.
+func (r *variableReferences) walk(node parse.Node, dotIsRoot bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			r.walk(child, dotIsRoot)
+		}
+	case *parse.ActionNode:
+		r.walkPipe(n.Pipe, dotIsRoot)
+	case *parse.IfNode:
+		r.walkPipe(n.Pipe, dotIsRoot)
+		r.walk(n.List, dotIsRoot)
+		r.walk(n.ElseList, dotIsRoot)
+	case *parse.RangeNode:
+		r.walkPipe(n.Pipe, dotIsRoot)
+		r.walk(n.List, false)
+		r.walk(n.ElseList, dotIsRoot)
+	case *parse.WithNode:
+		r.walkPipe(n.Pipe, dotIsRoot)
+		r.walk(n.List, false)
+		r.walk(n.ElseList, dotIsRoot)
+	case *parse.TemplateNode:
+		// defined templates are analyzed on their own, as if executed with the data as a whole
+		if n.Pipe != nil && !(len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 && isRootData(n.Pipe.Cmds[0].Args[0], dotIsRoot)) {
+			r.walkPipe(n.Pipe, dotIsRoot)
+		}
+	}
+}
+
+func (r *variableReferences) walkPipe(pipe *parse.PipeNode, dotIsRoot bool) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		r.walkCommand(cmd, dotIsRoot)
+	}
+}
+
+func (r *variableReferences) walkCommand(cmd *parse.CommandNode, dotIsRoot bool) {
+	args := cmd.Args
+	if len(args) > 0 {
+		if ident, ok := args[0].(*parse.IdentifierNode); ok {
+			switch ident.Ident {
+			case "render":
+				// the included file is analyzed on its own, as if executed with the data as a whole
+				if len(args) == 3 {
+					if includePath, ok := args[1].(*parse.StringNode); ok {
+						r.renderPaths = append(r.renderPaths, includePath.Text)
+						if isRootData(args[2], dotIsRoot) {
+							return
+						}
+					}
+				}
+			case "index", "get", "hasKey":
+				// a lookup by a literal name, e.g. {{ index . "some-name" }}
+				if len(args) >= 3 && isRootData(args[1], dotIsRoot) {
+					if name, ok := args[2].(*parse.StringNode); ok {
+						r.names[name.Text] = true
+						args = args[3:]
+					}
+				}
+			}
+		}
+	}
+	for _, arg := range args {
+		r.walkArg(arg, dotIsRoot)
+	}
+}
+
+func (r *variableReferences) walkArg(arg parse.Node, dotIsRoot bool) {
+	switch n := arg.(type) {
+	case *parse.FieldNode:
+		r.names[n.Ident[0]] = true
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			if len(n.Ident) > 1 {
+				r.names[n.Ident[1]] = true
+			} else {
+				r.all = true
+			}
+		}
+	case *parse.DotNode:
+		if dotIsRoot {
+			r.all = true
+		}
+	case *parse.ChainNode:
+		r.walkArg(n.Node, dotIsRoot)
+	case *parse.PipeNode:
+		r.walkPipe(n, dotIsRoot)
+	}
+}
+
+// isRootData is true for an argument that is the data the template was executed with, that is, . or $
+func isRootData(arg parse.Node, dotIsRoot bool) bool {
+	switch n := arg.(type) {
+	case *parse.DotNode:
+		return dotIsRoot
+	case *parse.VariableNode:
+		return len(n.Ident) == 1 && n.Ident[0] == "$"
+	}
+	return false
+}
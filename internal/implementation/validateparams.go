@@ -0,0 +1,83 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"sort"
+)
+
+func (i *GeneratorImpl) ValidateParameters(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, options api.ParameterValidationOptions) *api.ParameterValidationResponse {
+	sourceDir := generatordir.Instance(ctx, sourceBaseDir)
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, generatorName)
+	if err != nil {
+		return &api.ParameterValidationResponse{Errors: []error{err}}
+	}
+
+	return i.validateParameters(ctx, genSpec, parameters, options)
+}
+
+func (i *GeneratorImpl) ValidateRenderSpec(ctx context.Context, request *api.Request) *api.ParameterValidationResponse {
+	ctx = withRequestOptions(ctx, request)
+	sourceDir := generatordir.Instance(ctx, request.SourceBaseDir)
+	targetDir := targetdir.Instance(ctx, request.TargetBaseDir)
+
+	renderSpec, err := targetDir.ObtainRenderSpec(ctx, request.RenderSpecFile)
+	if err != nil {
+		return &api.ParameterValidationResponse{Errors: []error{err}}
+	}
+
+	genSpec, err := sourceDir.ObtainGeneratorSpec(ctx, renderSpec.GeneratorName)
+	if err != nil {
+		return &api.ParameterValidationResponse{Errors: []error{err}}
+	}
+
+	renderSpec, err = i.applyOverrides(ctx, request, genSpec, renderSpec)
+	if err != nil {
+		return &api.ParameterValidationResponse{Errors: []error{err}}
+	}
+
+	return i.validateParameters(ctx, genSpec, renderSpec.Parameters, api.ParameterValidationOptions{})
+}
+
+func (i *GeneratorImpl) validateParameters(ctx context.Context, genSpec *api.GeneratorSpec, parameters map[string]interface{}, options api.ParameterValidationOptions) *api.ParameterValidationResponse {
+	parameters, warnings := i.resolveParameterAliases(ctx, genSpec, parameters)
+	result := &api.ParameterValidationResponse{
+		InvalidParameters: []api.InvalidParameter{},
+		MissingParameters: []string{},
+		Warnings:          warnings,
+	}
+
+	// unlike rendering, we want all problems at once, so each parameter is checked on its own
+	for _, name := range sortedParameterNames(parameters) {
+		if _, ok := genSpec.Variables[name]; !ok {
+			result.InvalidParameters = append(result.InvalidParameters, api.InvalidParameter{
+				Name:  name,
+				Error: fmt.Errorf("parameter '%s' is not allowed according to generator spec", name),
+			})
+		}
+	}
+	for _, name := range sortedVariableNames(genSpec) {
+		varSpec := genSpec.Variables[name]
+		val := parameters[name]
+		if val == nil {
+			if !varSpec.HasDefault() {
+				result.MissingParameters = append(result.MissingParameters, name)
+			}
+			continue
+		}
+		if _, err := i.validateParameterValue(ctx, name, varSpec, val); err != nil {
+			result.InvalidParameters = append(result.InvalidParameters, api.InvalidParameter{Name: name, Error: err})
+		}
+	}
+	// unknown parameters were added first
+	sort.Slice(result.InvalidParameters, func(a, b int) bool {
+		return result.InvalidParameters[a].Name < result.InvalidParameters[b].Name
+	})
+
+	result.Success = len(result.InvalidParameters) == 0 && (options.AllowIncomplete || len(result.MissingParameters) == 0)
+	return result
+}
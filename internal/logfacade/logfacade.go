@@ -5,6 +5,7 @@ import (
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/internal/implementation"
+	"io"
 )
 
 type GeneratorLogfacade struct {
@@ -20,6 +21,20 @@ func (i *GeneratorLogfacade) FindGeneratorNames(ctx context.Context, sourceBaseD
 	return result, err
 }
 
+func (i *GeneratorLogfacade) CatalogGenerators(ctx context.Context, sourceBaseDir string) *api.GeneratorCatalog {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering CatalogGenerators sourceBaseDir=%s", sourceBaseDir)
+	result := i.Wrapped.CatalogGenerators(ctx, sourceBaseDir)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Print("error in CatalogGenerators")
+	}
+	for _, g := range result.Generators {
+		if len(g.Errors) > 0 {
+			aulogging.Logger.Ctx(ctx).Warn().WithErr(g.Errors[0]).Printf("error in CatalogGenerators for generator %s", g.Name)
+		}
+	}
+	return result
+}
+
 func (i *GeneratorLogfacade) ObtainGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) (*api.GeneratorSpec, error) {
 	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ObtainGeneratorSpec sourceBaseDir=%s generatorName=%s", sourceBaseDir, generatorName)
 	result, err := i.Wrapped.ObtainGeneratorSpec(ctx, sourceBaseDir, generatorName)
@@ -29,6 +44,74 @@ func (i *GeneratorLogfacade) ObtainGeneratorSpec(ctx context.Context, sourceBase
 	return result, err
 }
 
+func (i *GeneratorLogfacade) FindGeneratorNamesWithOptions(ctx context.Context, sourceBaseDir string, options api.DiscoveryOptions) ([]string, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering FindGeneratorNamesWithOptions sourceBaseDir=%s prefix=%s extension=%s", sourceBaseDir, options.Prefix, options.Extension)
+	result, err := i.Wrapped.FindGeneratorNamesWithOptions(ctx, sourceBaseDir, options)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in FindGeneratorNamesWithOptions")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) ObtainGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.DiscoveryOptions) (*api.GeneratorSpec, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ObtainGeneratorSpecWithOptions sourceBaseDir=%s generatorName=%s prefix=%s extension=%s", sourceBaseDir, generatorName, options.Prefix, options.Extension)
+	result, err := i.Wrapped.ObtainGeneratorSpecWithOptions(ctx, sourceBaseDir, generatorName, options)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in ObtainGeneratorSpecWithOptions")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) ReadGeneratorSpecRaw(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, string, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ReadGeneratorSpecRaw sourceBaseDir=%s generatorName=%s", sourceBaseDir, generatorName)
+	result, filePath, err := i.Wrapped.ReadGeneratorSpecRaw(ctx, sourceBaseDir, generatorName)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in ReadGeneratorSpecRaw")
+	}
+	return result, filePath, err
+}
+
+func (i *GeneratorLogfacade) ValidateGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) []error {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ValidateGeneratorSpec sourceBaseDir=%s generatorName=%s", sourceBaseDir, generatorName)
+	result := i.Wrapped.ValidateGeneratorSpec(ctx, sourceBaseDir, generatorName)
+	if len(result) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result[0]).Printf("%d error(s) in ValidateGeneratorSpec: first error was %s", len(result), result[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) ValidateGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.SpecValidationOptions) *api.SpecValidationResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ValidateGeneratorSpecWithOptions sourceBaseDir=%s generatorName=%s strict=%t", sourceBaseDir, generatorName, options.Strict)
+	result := i.Wrapped.ValidateGeneratorSpecWithOptions(ctx, sourceBaseDir, generatorName, options)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in ValidateGeneratorSpecWithOptions: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) DiffGeneratorSpecs(ctx context.Context, oldSpec *api.GeneratorSpec, newSpec *api.GeneratorSpec) *api.GeneratorSpecDiff {
+	aulogging.Logger.Ctx(ctx).Debug().Print("entering DiffGeneratorSpecs")
+	return i.Wrapped.DiffGeneratorSpecs(ctx, oldSpec, newSpec)
+}
+
+func (i *GeneratorLogfacade) ExportParameterSchema(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ExportParameterSchema sourceBaseDir=%s generatorName=%s", sourceBaseDir, generatorName)
+	result, err := i.Wrapped.ExportParameterSchema(ctx, sourceBaseDir, generatorName)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in ExportParameterSchema")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) ValidateParameters(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, options api.ParameterValidationOptions) *api.ParameterValidationResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ValidateParameters sourceBaseDir=%s generatorName=%s allowIncomplete=%t", sourceBaseDir, generatorName, options.AllowIncomplete)
+	result := i.Wrapped.ValidateParameters(ctx, sourceBaseDir, generatorName, parameters, options)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in ValidateParameters: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
 func (i *GeneratorLogfacade) WriteRenderSpecWithDefaults(ctx context.Context, request *api.Request, generatorName string) *api.Response {
 	aulogging.Logger.Ctx(ctx).Debug().Printf("entering WriteRenderSpecWithDefaults sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile, generatorName)
 	result := i.Wrapped.WriteRenderSpecWithDefaults(ctx, request, generatorName)
@@ -38,6 +121,15 @@ func (i *GeneratorLogfacade) WriteRenderSpecWithDefaults(ctx context.Context, re
 	return result
 }
 
+func (i *GeneratorLogfacade) WriteRenderSpecWithDefaultsTo(ctx context.Context, request *api.Request, generatorName string, w io.Writer) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering WriteRenderSpecWithDefaultsTo sourceBaseDir=%s generatorName=%s", request.SourceBaseDir, generatorName)
+	result := i.Wrapped.WriteRenderSpecWithDefaultsTo(ctx, request, generatorName, w)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in WriteRenderSpecWithDefaultsTo: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
 func (i *GeneratorLogfacade) WriteRenderSpecWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
 	aulogging.Logger.Ctx(ctx).Debug().Printf("entering WriteRenderSpecWithValues sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile, generatorName)
 	result := i.Wrapped.WriteRenderSpecWithValues(ctx, request, generatorName, parameters)
@@ -47,14 +139,75 @@ func (i *GeneratorLogfacade) WriteRenderSpecWithValues(ctx context.Context, requ
 	return result
 }
 
+func (i *GeneratorLogfacade) WriteRenderSpecWithValuesTo(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}, w io.Writer) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering WriteRenderSpecWithValuesTo sourceBaseDir=%s generatorName=%s", request.SourceBaseDir, generatorName)
+	result := i.Wrapped.WriteRenderSpecWithValuesTo(ctx, request, generatorName, parameters, w)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in WriteRenderSpecWithValuesTo: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) NormalizeRenderSpec(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering NormalizeRenderSpec sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile, generatorName)
+	result := i.Wrapped.NormalizeRenderSpec(ctx, request, generatorName)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in NormalizeRenderSpec: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
 func (i *GeneratorLogfacade) Render(ctx context.Context, request *api.Request) *api.Response {
 	aulogging.Logger.Ctx(ctx).Debug().Printf("entering Render sourceBaseDir=%s targetBaseDir=%s renderspec=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
 	result := i.Wrapped.Render(ctx, request)
+	i.logRenderResult(ctx, "Render", result)
+	return result
+}
+
+func (i *GeneratorLogfacade) RenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering RenderWithValues sourceBaseDir=%s targetBaseDir=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, generatorName)
+	result := i.Wrapped.RenderWithValues(ctx, request, generatorName, parameters)
+	i.logRenderResult(ctx, "RenderWithValues", result)
+	return result
+}
+
+func (i *GeneratorLogfacade) RenderWithStruct(ctx context.Context, request *api.Request, generatorName string, parameters interface{}) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering RenderWithStruct sourceBaseDir=%s targetBaseDir=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, generatorName)
+	result := i.Wrapped.RenderWithStruct(ctx, request, generatorName, parameters)
+	i.logRenderResult(ctx, "RenderWithStruct", result)
+	return result
+}
+
+func (i *GeneratorLogfacade) ParseParameterArgs(ctx context.Context, args []string) (map[string]interface{}, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ParseParameterArgs with %d argument(s)", len(args))
+	result, err := i.Wrapped.ParseParameterArgs(ctx, args)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in ParseParameterArgs")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) LoadParametersFromEnvFile(ctx context.Context, path string) (map[string]interface{}, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering LoadParametersFromEnvFile path=%s", path)
+	result, err := i.Wrapped.LoadParametersFromEnvFile(ctx, path)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in LoadParametersFromEnvFile")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) logRenderResult(ctx context.Context, operation string, result *api.Response) {
 	if len(result.Errors) > 0 || !result.Success {
-		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d top level error(s) in Render: first error was %s", len(result.Errors), result.Errors[0].Error())
+		if len(result.Errors) > 0 {
+			aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d top level error(s) in %s: first error was %s", len(result.Errors), operation, result.Errors[0].Error())
+		} else {
+			aulogging.Logger.Ctx(ctx).Warn().Printf("errors in %s, see individual files", operation)
+		}
 		for _, f := range result.RenderedFiles {
-			if len(f.Errors) > 0 || !f.Success {
-				aulogging.Logger.Ctx(ctx).Warn().Printf("%s %s %d errors, first is:", "ERR", f.RelativeFilePath, len(f.Errors), f.Errors[0].Error())
+			if len(f.Errors) > 0 {
+				aulogging.Logger.Ctx(ctx).Warn().Printf("%s %s %d error(s), first is: %s", "ERR", f.RelativeFilePath, len(f.Errors), f.Errors[0].Error())
+			} else if !f.Success {
+				aulogging.Logger.Ctx(ctx).Warn().Printf("%s %s", "ERR", f.RelativeFilePath)
 			} else {
 				aulogging.Logger.Ctx(ctx).Info().Printf("%s %s", "OK", f.RelativeFilePath)
 			}
@@ -65,5 +218,102 @@ func (i *GeneratorLogfacade) Render(ctx context.Context, request *api.Request) *
 			aulogging.Logger.Ctx(ctx).Debug().Printf("%s %s", "OK", f.RelativeFilePath)
 		}
 	}
+}
+
+func (i *GeneratorLogfacade) RenderExpression(ctx context.Context, request *api.Request, generatorName string, expression string) (string, error) {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering RenderExpression sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile, generatorName)
+	result, err := i.Wrapped.RenderExpression(ctx, request, generatorName, expression)
+	if err != nil {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(err).Print("error in RenderExpression")
+	}
+	return result, err
+}
+
+func (i *GeneratorLogfacade) BatchRender(ctx context.Context, requests []*api.Request) *api.BatchResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering BatchRender with %d requests", len(requests))
+	result := i.Wrapped.BatchRender(ctx, requests)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d top level error(s) in BatchRender: first error was %s", len(result.Errors), result.Errors[0].Error())
+	} else if !result.Success {
+		aulogging.Logger.Ctx(ctx).Warn().Print("errors in BatchRender, see individual responses")
+	} else {
+		aulogging.Logger.Ctx(ctx).Info().Printf("successfully rendered %d requests", len(result.Responses))
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) CheckUpToDate(ctx context.Context, request *api.Request) *api.CheckResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering CheckUpToDate sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
+	result := i.Wrapped.CheckUpToDate(ctx, request)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in CheckUpToDate: first error was %s", len(result.Errors), result.Errors[0].Error())
+	} else if len(result.StaleFiles) > 0 {
+		aulogging.Logger.Ctx(ctx).Info().Printf("%d file(s) out of date", len(result.StaleFiles))
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) RenderToWriter(ctx context.Context, request *api.Request, targetPath string, w io.Writer) *api.Response {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering RenderToWriter sourceBaseDir=%s targetBaseDir=%s renderspec=%s targetPath=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile, targetPath)
+	result := i.Wrapped.RenderToWriter(ctx, request, targetPath, w)
+	i.logRenderResult(ctx, "RenderToWriter", result)
+	return result
+}
+
+func (i *GeneratorLogfacade) PlanRender(ctx context.Context, request *api.Request) *api.PlanResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering PlanRender sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
+	result := i.Wrapped.PlanRender(ctx, request)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in PlanRender: first error was %s", len(result.Errors), result.Errors[0].Error())
+	} else if !result.Success {
+		aulogging.Logger.Ctx(ctx).Warn().Print("errors evaluating target paths in PlanRender, see individual files")
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) PlanRenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.PlanResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering PlanRenderWithValues sourceBaseDir=%s targetBaseDir=%s generatorName=%s", request.SourceBaseDir, request.TargetBaseDir, generatorName)
+	result := i.Wrapped.PlanRenderWithValues(ctx, request, generatorName, parameters)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in PlanRenderWithValues: first error was %s", len(result.Errors), result.Errors[0].Error())
+	} else if !result.Success {
+		aulogging.Logger.Ctx(ctx).Warn().Print("errors evaluating target paths in PlanRenderWithValues, see individual files")
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) ValidateRenderSpec(ctx context.Context, request *api.Request) *api.ParameterValidationResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ValidateRenderSpec sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
+	result := i.Wrapped.ValidateRenderSpec(ctx, request)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in ValidateRenderSpec: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) ResolveDefaults(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.DefaultResolutionResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering ResolveDefaults sourceBaseDir=%s generatorName=%s", request.SourceBaseDir, generatorName)
+	result := i.Wrapped.ResolveDefaults(ctx, request, generatorName, parameters)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in ResolveDefaults: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) CompareRenderSpecToDefaults(ctx context.Context, request *api.Request) *api.DefaultComparisonResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering CompareRenderSpecToDefaults sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
+	result := i.Wrapped.CompareRenderSpecToDefaults(ctx, request)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in CompareRenderSpecToDefaults: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
+	return result
+}
+
+func (i *GeneratorLogfacade) FindUnusedVariables(ctx context.Context, request *api.Request) *api.UnusedVariablesResponse {
+	aulogging.Logger.Ctx(ctx).Debug().Printf("entering FindUnusedVariables sourceBaseDir=%s targetBaseDir=%s renderSpecFile=%s", request.SourceBaseDir, request.TargetBaseDir, request.RenderSpecFile)
+	result := i.Wrapped.FindUnusedVariables(ctx, request)
+	if len(result.Errors) > 0 {
+		aulogging.Logger.Ctx(ctx).Warn().WithErr(result.Errors[0]).Printf("%d error(s) in FindUnusedVariables: first error was %s", len(result.Errors), result.Errors[0].Error())
+	}
 	return result
 }
@@ -0,0 +1,26 @@
+package logfacade
+
+import (
+	"context"
+	aulogging "github.com/StephanHCB/go-autumn-logging"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// failures without errors must be logged without panicking
+
+func TestLogRenderResult_ShouldNotPanicWithoutErrors(t *testing.T) {
+	aulogging.SetupNoLoggerForTesting()
+	facade := &GeneratorLogfacade{}
+	result := &api.Response{
+		RenderedFiles: []api.FileResult{
+			{Success: false, RelativeFilePath: "failed.txt"},
+			{Success: true, RelativeFilePath: "ok.txt"},
+		},
+	}
+
+	require.NotPanics(t, func() {
+		facade.logRenderResult(context.TODO(), "Render", result)
+	})
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/mundobaton/go-generator-lib/api"
+	"os"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Cache keeps parsed generator specs and templates across requests, for long-lived processes that render
+// the same generators over and over. It is safe for concurrent use.
+type Cache struct {
+	mu        sync.Mutex
+	specs     map[string]specEntry
+	templates map[string]templateEntry
+}
+
+// FileStamp identifies the version of a file a cache entry was made from
+type FileStamp struct {
+	Path    string
+	ModTime time.Time
+	Size    int64
+}
+
+type specEntry struct {
+	spec   *api.GeneratorSpec
+	stamps []FileStamp
+}
+
+type templateEntry struct {
+	hash [sha256.Size]byte
+	tmpl *template.Template
+}
+
+func New() *Cache {
+	return &Cache{
+		specs:     map[string]specEntry{},
+		templates: map[string]templateEntry{},
+	}
+}
+
+// Clear removes all entries, e.g. after deploying new generator versions.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.specs = map[string]specEntry{}
+	c.templates = map[string]templateEntry{}
+}
+
+type cacheKey struct{}
+
+// WithCache returns a context that makes read operations use the given cache.
+func WithCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, c)
+}
+
+// From obtains the cache from the context, or nil if there is none.
+func From(ctx context.Context) *Cache {
+	c, _ := ctx.Value(cacheKey{}).(*Cache)
+	return c
+}
+
+// Stamp records the current modification time and size of a file.
+func Stamp(path string) (FileStamp, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return FileStamp{}, err
+	}
+	return FileStamp{Path: path, ModTime: fileInfo.ModTime(), Size: fileInfo.Size()}, nil
+}
+
+// Spec returns a copy of the generator spec cached under key, if none of the files it was made from have changed.
+func (c *Cache) Spec(key string) (*api.GeneratorSpec, bool) {
+	c.mu.Lock()
+	entry, ok := c.specs[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	for _, stamp := range entry.stamps {
+		current, err := Stamp(stamp.Path)
+		if err != nil || !current.ModTime.Equal(stamp.ModTime) || current.Size != stamp.Size {
+			return nil, false
+		}
+	}
+	return copySpec(entry.spec), true
+}
+
+// PutSpec caches a copy of spec under key, made from the files recorded in stamps.
+func (c *Cache) PutSpec(key string, spec *api.GeneratorSpec, stamps []FileStamp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.specs[key] = specEntry{spec: copySpec(spec), stamps: stamps}
+}
+
+// Template returns a copy of the parsed template cached under key, if it was parsed from the same content
+// with functions of the same names. The functions of the copy are replaced with funcs.
+//
+// Parsed templates are never executed themselves, only copies, so a cached template can be shared by
+// concurrent requests with different function implementations.
+func (c *Cache) Template(key string, content []byte, funcs template.FuncMap) (*template.Template, bool) {
+	c.mu.Lock()
+	entry, ok := c.templates[key]
+	c.mu.Unlock()
+	if !ok || entry.hash != templateHash(content, funcs) {
+		return nil, false
+	}
+	tmpl, err := entry.tmpl.Clone()
+	if err != nil {
+		return nil, false
+	}
+	return tmpl.Funcs(funcs), true
+}
+
+// PutTemplate caches a template parsed from content with funcs under key, replacing any older version.
+// The template must not be executed afterwards, use a copy obtained from Template instead.
+func (c *Cache) PutTemplate(key string, content []byte, funcs template.FuncMap, tmpl *template.Template) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.templates[key] = templateEntry{hash: templateHash(content, funcs), tmpl: tmpl}
+}
+
+// templateHash covers the names of the functions, because a template that parsed with one set of functions
+// may fail to parse with another
+func templateHash(content []byte, funcs template.FuncMap) [sha256.Size]byte {
+	names := make([]string, 0, len(funcs))
+	for name := range funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	_, _ = h.Write(content)
+	for _, name := range names {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(name))
+	}
+	var result [sha256.Size]byte
+	copy(result[:], h.Sum(nil))
+	return result
+}
+
+// copySpec copies the spec and its top level slices and maps, so callers can modify them. Nested values,
+// such as structured defaults, are shared.
+func copySpec(spec *api.GeneratorSpec) *api.GeneratorSpec {
+	result := *spec
+	if spec.Templates != nil {
+		result.Templates = make([]api.TemplateSpec, len(spec.Templates))
+		copy(result.Templates, spec.Templates)
+	}
+	if spec.IncludeVariables != nil {
+		result.IncludeVariables = make([]string, len(spec.IncludeVariables))
+		copy(result.IncludeVariables, spec.IncludeVariables)
+	}
+	if spec.Excludes != nil {
+		result.Excludes = make([]string, len(spec.Excludes))
+		copy(result.Excludes, spec.Excludes)
+	}
+	if spec.Variables != nil {
+		result.Variables = make(map[string]api.VariableSpec, len(spec.Variables))
+		for k, v := range spec.Variables {
+			result.Variables[k] = v
+		}
+	}
+	if spec.GroupConditions != nil {
+		result.GroupConditions = make(map[string]string, len(spec.GroupConditions))
+		for k, v := range spec.GroupConditions {
+			result.GroupConditions[k] = v
+		}
+	}
+	if spec.Profiles != nil {
+		result.Profiles = make(map[string]api.ProfileSpec, len(spec.Profiles))
+		for k, v := range spec.Profiles {
+			result.Profiles[k] = v
+		}
+	}
+	if spec.PatternDefs != nil {
+		result.PatternDefs = make(map[string]string, len(spec.PatternDefs))
+		for k, v := range spec.PatternDefs {
+			result.PatternDefs[k] = v
+		}
+	}
+	if spec.Detect != nil {
+		result.Detect = make(map[string]string, len(spec.Detect))
+		for k, v := range spec.Detect {
+			result.Detect[k] = v
+		}
+	}
+	return &result
+}
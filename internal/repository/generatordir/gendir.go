@@ -4,26 +4,74 @@ import (
 	"context"
 	"fmt"
 	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
+	"github.com/mundobaton/go-generator-lib/internal/repository/glob"
+	"github.com/mundobaton/go-generator-lib/internal/repository/retry"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
 	"path"
 	"regexp"
 	"sort"
-	"strings"
 )
 
 type GeneratorDirectory struct {
 	baseDir string
+	// as given, for error messages
+	originalBaseDir string
+	prefix          string
+	extension       string
 }
 
-func Instance(_ context.Context, baseDir string) *GeneratorDirectory {
-	return &GeneratorDirectory{baseDir: baseDir}
+// IgnoreFilename is the name of an optional file in the generator directory that lists generator spec files
+// FindGeneratorNames leaves out, e.g. drafts, as gitignore-like glob patterns, one per line.
+const IgnoreFilename = ".genignore"
+
+type discoveryOptionsKey struct{}
+
+// WithDiscoveryOptions returns a context that makes every directory instance created with it name generator spec
+// files according to options.
+func WithDiscoveryOptions(ctx context.Context, options api.DiscoveryOptions) context.Context {
+	return context.WithValue(ctx, discoveryOptionsKey{}, options)
+}
+
+// DiscoveryOptionsFrom returns the discovery options in the context, if any.
+func DiscoveryOptionsFrom(ctx context.Context) (api.DiscoveryOptions, bool) {
+	options, ok := ctx.Value(discoveryOptionsKey{}).(api.DiscoveryOptions)
+	return options, ok
+}
+
+// Instance creates a GeneratorDirectory. A single trailing slash (as added by tab completion) is removed from baseDir.
+// A relative baseDir is resolved against the working directory in the context, if there is one, and generator
+// spec files are named according to the discovery options in the context, if there are any.
+func Instance(ctx context.Context, baseDir string) *GeneratorDirectory {
+	options, _ := DiscoveryOptionsFrom(ctx)
+	return InstanceWithDiscoveryOptions(ctx, baseDir, options)
+}
+
+// InstanceWithDiscoveryOptions is like Instance, but generator spec files are named according to options,
+// whatever the context says.
+func InstanceWithDiscoveryOptions(ctx context.Context, baseDir string, options api.DiscoveryOptions) *GeneratorDirectory {
+	resolved := workingdir.Resolve(ctx, baseDir)
+	d := &GeneratorDirectory{
+		baseDir:         workingdir.TrimTrailingSlash(resolved),
+		originalBaseDir: resolved,
+		prefix:          options.Prefix,
+		extension:       options.Extension,
+	}
+	if d.prefix == "" && !options.NoPrefix {
+		d.prefix = api.DefaultGeneratorSpecPrefix
+	}
+	if d.extension == "" {
+		d.extension = api.DefaultGeneratorSpecExtension
+	}
+	return d
 }
 
 func (d *GeneratorDirectory) CheckValid(_ context.Context) error {
-	if strings.HasSuffix(d.baseDir, "/") || strings.HasSuffix(d.baseDir, "\\") {
-		return fmt.Errorf("invalid generator directory: baseDir %s must not contain trailing slash", d.baseDir)
+	if workingdir.HasTrailingSlash(d.baseDir) {
+		return fmt.Errorf("invalid generator directory: baseDir %s must not contain more than one trailing slash", d.originalBaseDir)
 	}
 	fileInfo, err := os.Stat(d.baseDir)
 	if err == nil {
@@ -41,16 +89,22 @@ func (d *GeneratorDirectory) FindGeneratorNames(ctx context.Context) ([]string,
 		return []string{}, err
 	}
 
-	files, err := ioutil.ReadDir(d.baseDir)
+	var files []os.FileInfo
+	err := retry.Do(ctx, func() error {
+		var err error
+		files, err = ioutil.ReadDir(d.baseDir)
+		return err
+	})
 	if err != nil {
 		// not sure this is even reachable given we check for file stats in CheckValid
 		return []string{}, fmt.Errorf("error reading generator directory: %s", err.Error())
 	}
 
-	regex, _ := regexp.Compile("^generator-(.*).yaml$")
+	regex, _ := regexp.Compile("^" + regexp.QuoteMeta(d.prefix) + "(.+)" + regexp.QuoteMeta(d.extension) + "$")
+	ignored := glob.ReadPatternFile(path.Join(d.baseDir, IgnoreFilename))
 	result := []string{}
 	for _, f := range files {
-		if f.Mode().IsRegular() {
+		if f.Mode().IsRegular() && !glob.MatchesAny(f.Name(), ignored) {
 			if matchInfo := regex.FindStringSubmatch(f.Name()); matchInfo != nil {
 				result = append(result, matchInfo[1])
 			}
@@ -62,24 +116,72 @@ func (d *GeneratorDirectory) FindGeneratorNames(ctx context.Context) ([]string,
 	return result, nil
 }
 
+// ObtainGeneratorSpec reads and parses the generator spec. If the context has a cache, the parsed spec is
+// reused until the spec file or one of its shared variables files changes.
 func (d *GeneratorDirectory) ObtainGeneratorSpec(ctx context.Context, generatorName string) (*api.GeneratorSpec, error) {
-	if err := d.CheckValid(ctx); err != nil {
-		return &api.GeneratorSpec{}, err
+	specCache := cache.From(ctx)
+	specPath := path.Join(d.baseDir, d.generatorSpecFilename(generatorName))
+	if specCache != nil {
+		if generatorSpec, ok := specCache.Spec(specPath); ok {
+			return generatorSpec, nil
+		}
 	}
 
-	fileName := "generator-" + generatorName + ".yaml"
-	generatorSpecYaml, err := d.ReadFile(ctx, fileName)
+	// files are stamped before reading them, so a change while reading invalidates the cache entry
+	stamps := d.stamps(specCache, specPath)
+	generatorSpecYaml, _, err := d.ReadGeneratorSpecRaw(ctx, generatorName)
 	if err != nil {
-		return &api.GeneratorSpec{}, fmt.Errorf("error reading generator spec file %s: %s", fileName, err.Error())
+		return &api.GeneratorSpec{}, err
 	}
 
 	generatorSpec, err := d.parseGenSpec(ctx, generatorSpecYaml)
 	if err != nil {
-		return &api.GeneratorSpec{}, fmt.Errorf("error parsing generator spec from file %s: %s", fileName, err.Error())
+		return &api.GeneratorSpec{}, fmt.Errorf("error parsing generator spec from file %s: %s", d.generatorSpecFilename(generatorName), err.Error())
+	}
+
+	for _, fileName := range generatorSpec.IncludeVariables {
+		stamps = append(stamps, d.stamps(specCache, path.Join(d.baseDir, fileName))...)
+	}
+	if err := d.mergeSharedVariables(ctx, generatorSpec); err != nil {
+		return &api.GeneratorSpec{}, fmt.Errorf("error including shared variables in generator spec from file %s: %s", d.generatorSpecFilename(generatorName), err.Error())
+	}
+	if err := resolvePatternRefs(generatorSpec); err != nil {
+		return &api.GeneratorSpec{}, fmt.Errorf("error resolving pattern references in generator spec from file %s: %s", d.generatorSpecFilename(generatorName), err.Error())
+	}
+
+	if specCache != nil && len(stamps) == 1+len(generatorSpec.IncludeVariables) {
+		specCache.PutSpec(specPath, generatorSpec, stamps)
 	}
 	return generatorSpec, nil
 }
 
+// stamps returns the stamp of a file for caching, or none if there is no cache or the file cannot be stamped
+func (d *GeneratorDirectory) stamps(specCache *cache.Cache, filePath string) []cache.FileStamp {
+	if specCache == nil {
+		return nil
+	}
+	stamp, err := cache.Stamp(filePath)
+	if err != nil {
+		return nil
+	}
+	return []cache.FileStamp{stamp}
+}
+
+// ReadGeneratorSpecRaw reads the generator spec file without parsing it, returning its contents and its path.
+func (d *GeneratorDirectory) ReadGeneratorSpecRaw(ctx context.Context, generatorName string) ([]byte, string, error) {
+	fileName := d.generatorSpecFilename(generatorName)
+	filePath := path.Join(d.baseDir, fileName)
+	if err := d.CheckValid(ctx); err != nil {
+		return []byte{}, filePath, err
+	}
+
+	generatorSpecYaml, err := d.ReadFile(ctx, fileName)
+	if err != nil {
+		return []byte{}, filePath, fmt.Errorf("error reading generator spec file %s: %s", fileName, err.Error())
+	}
+	return generatorSpecYaml, filePath, nil
+}
+
 // --- public low level methods ---
 
 func (d *GeneratorDirectory) ReadFile(ctx context.Context, relativePath string) ([]byte, error) {
@@ -87,7 +189,7 @@ func (d *GeneratorDirectory) ReadFile(ctx context.Context, relativePath string)
 		return []byte{}, err
 	}
 
-	bytes, err := ioutil.ReadFile(path.Join(d.baseDir, relativePath))
+	bytes, err := retry.ReadFile(ctx, path.Join(d.baseDir, relativePath))
 	if err != nil {
 		return []byte{}, err
 	}
@@ -97,11 +199,61 @@ func (d *GeneratorDirectory) ReadFile(ctx context.Context, relativePath string)
 
 // --- helper methods ---
 
+func (d *GeneratorDirectory) generatorSpecFilename(generatorName string) string {
+	return d.prefix + generatorName + d.extension
+}
+
+// sharedVariablesFile is the format of the files listed in include_variables
+type sharedVariablesFile struct {
+	Variables map[string]api.VariableSpec `yaml:"variables"`
+}
+
+// mergeSharedVariables adds the variables from the files listed in include_variables, without overwriting
+// the variables the generator spec declares itself.
+func (d *GeneratorDirectory) mergeSharedVariables(ctx context.Context, spec *api.GeneratorSpec) error {
+	if len(spec.IncludeVariables) == 0 {
+		return nil
+	}
+
+	ownVariables := spec.Variables
+	spec.Variables = map[string]api.VariableSpec{}
+	for _, fileName := range spec.IncludeVariables {
+		sharedYaml, err := d.ReadFile(ctx, fileName)
+		if err != nil {
+			return fmt.Errorf("error reading shared variables file %s: %s", fileName, err.Error())
+		}
+		shared := &sharedVariablesFile{}
+		if err := yaml.UnmarshalStrict(sharedYaml, shared); err != nil {
+			return fmt.Errorf("error parsing shared variables file %s: %s", fileName, err.Error())
+		}
+		normalizeVariables(shared.Variables)
+		for k, v := range shared.Variables {
+			spec.Variables[k] = v
+		}
+	}
+	for k, v := range ownVariables {
+		spec.Variables[k] = v
+	}
+	return nil
+}
+
+// specVersionOnly is used to check the spec version before anything else, because a newer spec may well contain
+// fields this version of the library does not know, and then the version is the more helpful error
+type specVersionOnly struct {
+	SpecVersion int `yaml:"spec_version"`
+}
+
 func (d *GeneratorDirectory) parseGenSpec(_ context.Context, specYaml []byte) (*api.GeneratorSpec, error) {
+	version := &specVersionOnly{}
+	if err := yaml.Unmarshal(specYaml, version); err == nil && version.SpecVersion > api.CurrentGeneratorSpecVersion {
+		return &api.GeneratorSpec{}, fmt.Errorf("generator requires spec version %d, but this version of the library supports up to version %d, please upgrade", version.SpecVersion, api.CurrentGeneratorSpecVersion)
+	}
+
 	spec := &api.GeneratorSpec{}
 	err := yaml.UnmarshalStrict(specYaml, spec)
 	if err != nil {
 		return &api.GeneratorSpec{}, err
 	}
+	normalizeGenSpec(spec)
 	return spec, nil
 }
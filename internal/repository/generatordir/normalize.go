@@ -0,0 +1,55 @@
+package generatordir
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+)
+
+// normalizeGenSpec converts the maps the yaml parser produces for structured values, which have interface{} keys,
+// to maps with string keys, recursively, so they can be encoded as json, and templates can index them by string key.
+func normalizeGenSpec(spec *api.GeneratorSpec) {
+	for i := range spec.Templates {
+		for k, item := range spec.Templates[i].WithItems {
+			spec.Templates[i].WithItems[k] = NormalizeYamlValue(item)
+		}
+		for k, entry := range spec.Templates[i].WithEntries {
+			spec.Templates[i].WithEntries[k] = NormalizeYamlValue(entry)
+		}
+	}
+	normalizeVariables(spec.Variables)
+	for name, profile := range spec.Profiles {
+		for k, value := range profile.Defaults {
+			profile.Defaults[k] = NormalizeYamlValue(value)
+		}
+		spec.Profiles[name] = profile
+	}
+}
+
+func normalizeVariables(variables map[string]api.VariableSpec) {
+	for name, varSpec := range variables {
+		varSpec.DefaultValue = NormalizeYamlValue(varSpec.DefaultValue)
+		for k := range varSpec.DefaultWhen {
+			varSpec.DefaultWhen[k].Value = NormalizeYamlValue(varSpec.DefaultWhen[k].Value)
+		}
+		variables[name] = varSpec
+	}
+}
+
+// NormalizeYamlValue converts the maps in a value parsed by the yaml parser to maps with string keys, recursively.
+func NormalizeYamlValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			result[fmt.Sprintf("%v", k)] = NormalizeYamlValue(v)
+		}
+		return result
+	case []interface{}:
+		for k, v := range typed {
+			typed[k] = NormalizeYamlValue(v)
+		}
+		return typed
+	default:
+		return value
+	}
+}
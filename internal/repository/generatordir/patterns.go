@@ -0,0 +1,44 @@
+package generatordir
+
+import (
+	"fmt"
+	"github.com/mundobaton/go-generator-lib/api"
+	"sort"
+	"strings"
+)
+
+// resolvePatternRefs sets the validation pattern of every variable that references one of the pattern_defs,
+// so nothing else needs to know about references. Shared variables are included, so they must be merged first.
+func resolvePatternRefs(spec *api.GeneratorSpec) error {
+	names := make([]string, 0, len(spec.Variables))
+	for name := range spec.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		varSpec := spec.Variables[name]
+		if varSpec.ValidationPatternRef == "" {
+			continue
+		}
+		if varSpec.ValidationPattern != "" {
+			return fmt.Errorf("variable %s sets both pattern and pattern_ref, please use only one", name)
+		}
+		pattern, ok := spec.PatternDefs[varSpec.ValidationPatternRef]
+		if !ok {
+			return fmt.Errorf("variable %s references undefined pattern '%s', defined patterns are: [%s]", name, varSpec.ValidationPatternRef, strings.Join(sortedPatternNames(spec), ", "))
+		}
+		varSpec.ValidationPattern = pattern
+		spec.Variables[name] = varSpec
+	}
+	return nil
+}
+
+func sortedPatternNames(spec *api.GeneratorSpec) []string {
+	names := make([]string, 0, len(spec.PatternDefs))
+	for name := range spec.PatternDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
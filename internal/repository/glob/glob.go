@@ -0,0 +1,77 @@
+package glob
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+)
+
+// MatchesAny checks a relative path against a list of gitignore-like glob patterns, such as the excludes
+// of a generator spec or the lines of a .gitignore file.
+//
+// Patterns without a slash match any path segment, patterns containing a slash are matched against
+// leading segments of the path, and a trailing slash means the pattern only matches directories.
+func MatchesAny(relativePath string, patterns []string) bool {
+	segments := strings.Split(strings.Trim(strings.ReplaceAll(relativePath, "\\", "/"), "/"), "/")
+	for _, pattern := range patterns {
+		if matchesPattern(segments, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPattern(segments []string, pattern string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+
+	// a directory pattern must not match the last segment, which is the file itself
+	candidates := len(segments)
+	if dirOnly {
+		candidates--
+	}
+
+	for n := 0; n < candidates; n++ {
+		var candidate string
+		if anchored {
+			candidate = strings.Join(segments[:n+1], "/")
+		} else {
+			candidate = segments[n]
+		}
+		if matched, err := path.Match(pattern, candidate); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadPatternFile returns the patterns in a file in .gitignore format, one per line, without comments
+// and empty lines. A missing or unreadable file has no patterns.
+func ReadPatternFile(filename string) []string {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// an escaped leading # is literal
+		if strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
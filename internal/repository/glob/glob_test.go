@@ -0,0 +1,40 @@
+package glob
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatchesAny_SegmentPattern(t *testing.T) {
+	excludes := []string{"*.bak", "node_modules"}
+	require.True(t, MatchesAny("notes.bak", excludes))
+	require.True(t, MatchesAny("src/notes.bak", excludes))
+	require.True(t, MatchesAny("web/node_modules/lib/index.js", excludes))
+	require.False(t, MatchesAny("src/notes.bak.tmpl", excludes))
+	require.False(t, MatchesAny("src/main.go.tmpl", excludes))
+}
+
+func TestMatchesAny_DirectoryPattern(t *testing.T) {
+	excludes := []string{"build/"}
+	require.True(t, MatchesAny("build/main.tmpl", excludes))
+	require.True(t, MatchesAny("sub/build/main.tmpl", excludes))
+	require.False(t, MatchesAny("build", excludes))
+	require.False(t, MatchesAny("src/build.tmpl", excludes))
+}
+
+func TestMatchesAny_AnchoredPattern(t *testing.T) {
+	excludes := []string{"/src/*.tmpl", "docs/internal/"}
+	require.True(t, MatchesAny("src/main.tmpl", excludes))
+	require.True(t, MatchesAny("src/main.tmpl/inner", excludes))
+	require.False(t, MatchesAny("other/src/main.tmpl", excludes))
+	require.True(t, MatchesAny("docs/internal/secret.md", excludes))
+	require.False(t, MatchesAny("docs/internal", excludes))
+}
+
+func TestMatchesAny_WindowsSeparators(t *testing.T) {
+	require.True(t, MatchesAny("build\\main.tmpl", []string{"build/"}))
+}
+
+func TestMatchesAny_InvalidPatternNeverMatches(t *testing.T) {
+	require.False(t, MatchesAny("main.tmpl", []string{"[", "/", ""}))
+}
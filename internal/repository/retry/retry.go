@@ -0,0 +1,71 @@
+package retry
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Policy controls how often failed read operations are attempted.
+type Policy struct {
+	// the total number of attempts, values below 1 mean 1, that is, no retries
+	Attempts int
+
+	// the time to wait before the second attempt, doubled for each further attempt
+	Backoff time.Duration
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a context that makes read operations use the given policy.
+func WithPolicy(ctx context.Context, policy Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// PolicyFrom obtains the policy from the context. Without one, each operation is attempted once.
+func PolicyFrom(ctx context.Context) Policy {
+	policy, _ := ctx.Value(policyKey{}).(Policy)
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+	return policy
+}
+
+// Do runs op until it succeeds, the attempts of the policy in the context are used up, or the context is done.
+//
+// Errors that cannot be transient, such as a file that does not exist, are returned right away.
+// Otherwise, the error of the last attempt is returned.
+func Do(ctx context.Context, op func() error) error {
+	policy := PolicyFrom(ctx)
+	backoff := policy.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = op()
+		if err == nil || attempt >= policy.Attempts || os.IsNotExist(err) || os.IsPermission(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// ReadFile is like ioutil.ReadFile, but retried according to the policy in the context.
+func ReadFile(ctx context.Context, filename string) ([]byte, error) {
+	return ReadFileWith(ctx, filename, ioutil.ReadFile)
+}
+
+// ReadFileWith is like ReadFile, but uses the given function to read the file.
+func ReadFileWith(ctx context.Context, filename string, readFile func(string) ([]byte, error)) ([]byte, error) {
+	var contents []byte
+	err := Do(ctx, func() error {
+		var err error
+		contents, err = readFile(filename)
+		return err
+	})
+	return contents, err
+}
@@ -0,0 +1,55 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+// these tests add coverage for some internal error conditions only
+
+func flakyReader(failures int, err error) (func(string) ([]byte, error), *int) {
+	calls := 0
+	return func(string) ([]byte, error) {
+		calls++
+		if calls <= failures {
+			return nil, err
+		}
+		return []byte("contents"), nil
+	}, &calls
+}
+
+func TestReadFileWith_SucceedsOnSecondAttempt(t *testing.T) {
+	readFile, calls := flakyReader(1, errors.New("transient"))
+	ctx := WithPolicy(context.TODO(), Policy{Attempts: 2})
+	actual, err := ReadFileWith(ctx, "some-file", readFile)
+	require.Nil(t, err)
+	require.Equal(t, "contents", string(actual))
+	require.Equal(t, 2, *calls)
+}
+
+func TestReadFileWith_NoRetryByDefault(t *testing.T) {
+	readFile, calls := flakyReader(1, errors.New("transient"))
+	_, err := ReadFileWith(context.TODO(), "some-file", readFile)
+	require.NotNil(t, err, "unexpected nil error")
+	require.Equal(t, "transient", err.Error())
+	require.Equal(t, 1, *calls)
+}
+
+func TestReadFileWith_NotExistIsNotRetried(t *testing.T) {
+	readFile, calls := flakyReader(1, os.ErrNotExist)
+	ctx := WithPolicy(context.TODO(), Policy{Attempts: 3})
+	_, err := ReadFileWith(ctx, "some-file", readFile)
+	require.True(t, os.IsNotExist(err))
+	require.Equal(t, 1, *calls)
+}
+
+func TestReadFileWith_GivesUpAfterAttempts(t *testing.T) {
+	readFile, calls := flakyReader(5, errors.New("transient"))
+	ctx := WithPolicy(context.TODO(), Policy{Attempts: 3})
+	_, err := ReadFileWith(ctx, "some-file", readFile)
+	require.NotNil(t, err, "unexpected nil error")
+	require.Equal(t, 3, *calls)
+}
@@ -0,0 +1,61 @@
+package targetdir
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/internal/repository/glob"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsGitignored finds out whether git would ignore a file at the given path relative to the target directory.
+//
+// The .gitignore files of the directories from the repository root (the closest directory containing .git,
+// or the file system root) down to the directory of the file are taken into account, with the usual precedence:
+// deeper files take precedence over files further up, and later lines over earlier ones. Patterns starting
+// with ! re-include files. Patterns are matched like the excludes of a generator spec.
+func (d *TargetDirectory) IsGitignored(ctx context.Context, relativePath string) bool {
+	baseDir, err := filepath.Abs(d.baseDir)
+	if err != nil {
+		return false
+	}
+	filePath := filepath.Join(baseDir, filepath.FromSlash(relativePath))
+
+	directories := []string{}
+	for directory := filepath.Dir(filePath); ; {
+		directories = append(directories, directory)
+		if _, err := os.Stat(filepath.Join(directory, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			break
+		}
+		directory = parent
+	}
+
+	ignored := false
+	for k := len(directories) - 1; k >= 0; k-- {
+		relativeToDirectory, err := filepath.Rel(directories[k], filePath)
+		if err != nil {
+			continue
+		}
+		for _, pattern := range readGitignore(directories[k]) {
+			negated := strings.HasPrefix(pattern, "!")
+			if negated || strings.HasPrefix(pattern, "\\!") {
+				// an escaped leading ! is literal
+				pattern = pattern[1:]
+			}
+			if glob.MatchesAny(filepath.ToSlash(relativeToDirectory), []string{pattern}) {
+				ignored = !negated
+			}
+		}
+	}
+	return ignored
+}
+
+// readGitignore returns the patterns in the .gitignore file of directory, see glob.ReadPatternFile.
+// Negated patterns keep their leading !.
+func readGitignore(directory string) []string {
+	return glob.ReadPatternFile(filepath.Join(directory, ".gitignore"))
+}
@@ -1,29 +1,52 @@
 package targetdir
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	aulogging "github.com/StephanHCB/go-autumn-logging"
 	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/retry"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 type TargetDirectory struct {
 	baseDir string
+	// as given, for error messages
+	originalBaseDir string
+
+	// if set, WriteFile stores files here instead of writing them to disk
+	captured map[string][]byte
 }
 
+// Instance creates a TargetDirectory. A single trailing slash (as added by tab completion) is removed from baseDir.
+// A relative baseDir is resolved against the working directory in the context, if there is one.
 func Instance(ctx context.Context, baseDir string) *TargetDirectory {
-	return &TargetDirectory{baseDir: baseDir}
+	resolved := workingdir.Resolve(ctx, baseDir)
+	return &TargetDirectory{baseDir: workingdir.TrimTrailingSlash(resolved), originalBaseDir: resolved}
+}
+
+// WithCapturedWrites returns a TargetDirectory for the same baseDir that keeps written files in memory
+// instead of writing them to disk. Reading still happens from disk.
+func (d *TargetDirectory) WithCapturedWrites() *TargetDirectory {
+	return &TargetDirectory{baseDir: d.baseDir, originalBaseDir: d.originalBaseDir, captured: map[string][]byte{}}
+}
+
+// Captured returns the contents of the files written so far, by relative path, if writes are captured.
+func (d *TargetDirectory) Captured() map[string][]byte {
+	return d.captured
 }
 
 func (d *TargetDirectory) CheckValid(ctx context.Context) error {
-	if strings.HasSuffix(d.baseDir, "/") || strings.HasSuffix(d.baseDir, "\\") {
-		return fmt.Errorf("error invalid target directory: baseDir %s must not contain trailing slash", d.baseDir)
+	if workingdir.HasTrailingSlash(d.baseDir) {
+		return fmt.Errorf("error invalid target directory: baseDir %s must not contain more than one trailing slash", d.originalBaseDir)
 	}
 	fileInfo, err := os.Stat(d.baseDir)
 	if err == nil {
@@ -50,13 +73,16 @@ func (d *TargetDirectory) ObtainRenderSpec(ctx context.Context, renderSpecFilena
 	return renderSpec, nil
 }
 
-func (d *TargetDirectory) WriteRenderSpec(ctx context.Context, renderSpec *api.RenderSpec, renderSpecFilenameOrEmptyString string) (string, error) {
+// WriteRenderSpec writes the render spec to a file in the target directory.
+//
+// If parameterGroups assigns any parameter (by name) to a group, the parameters are written in commented sections,
+// one per group in alphabetical order, followed by a section for all ungrouped parameters.
+func (d *TargetDirectory) WriteRenderSpec(ctx context.Context, renderSpec *api.RenderSpec, parameterGroups map[string]string, renderSpecFilenameOrEmptyString string) (string, error) {
 	targetFile := d.RenderSpecFilenameOrDefaultForGenerator(ctx, renderSpecFilenameOrEmptyString, renderSpec.GeneratorName)
 
-	renderSpecYaml, err := d.renderRenderSpec(ctx, renderSpec)
+	renderSpecYaml, err := d.MarshalRenderSpec(ctx, renderSpec, parameterGroups)
 	if err != nil {
-		// unreachable with current feature set as far as I'm aware
-		return targetFile, fmt.Errorf("error preparing render spec: %s", err.Error())
+		return targetFile, err
 	}
 
 	err = d.WriteFile(ctx, targetFile, renderSpecYaml)
@@ -66,6 +92,20 @@ func (d *TargetDirectory) WriteRenderSpec(ctx context.Context, renderSpec *api.R
 	return targetFile, nil
 }
 
+// MarshalRenderSpec serializes the render spec exactly as WriteRenderSpec would write it, for callers that
+// want the contents rather than a file.
+func (d *TargetDirectory) MarshalRenderSpec(ctx context.Context, renderSpec *api.RenderSpec, parameterGroups map[string]string) ([]byte, error) {
+	versionedRenderSpec := *renderSpec
+	versionedRenderSpec.Version = api.CurrentRenderSpecVersion
+
+	renderSpecYaml, err := d.renderRenderSpec(ctx, &versionedRenderSpec, parameterGroups)
+	if err != nil {
+		// unreachable with current feature set as far as I'm aware
+		return nil, fmt.Errorf("error preparing render spec: %s", err.Error())
+	}
+	return renderSpecYaml, nil
+}
+
 // --- low level methods, public so they can be used in tests ---
 
 func (d *TargetDirectory) RenderSpecFilenameOrDefault(ctx context.Context, renderSpecFilename string) string {
@@ -86,7 +126,7 @@ func (d *TargetDirectory) ReadFile(ctx context.Context, relativePath string) ([]
 		return []byte{}, err
 	}
 
-	bytes, err := ioutil.ReadFile(path.Join(d.baseDir, relativePath))
+	bytes, err := retry.ReadFile(ctx, path.Join(d.baseDir, relativePath))
 	if err != nil {
 		return []byte{}, err
 	}
@@ -94,16 +134,132 @@ func (d *TargetDirectory) ReadFile(ctx context.Context, relativePath string) ([]
 	return bytes, nil
 }
 
+// Exists checks whether a file or directory exists at the given path relative to the target directory.
+func (d *TargetDirectory) Exists(ctx context.Context, relativePath string) bool {
+	if err := d.CheckValid(ctx); err != nil {
+		return false
+	}
+
+	_, err := os.Stat(path.Join(d.baseDir, relativePath))
+	return err == nil
+}
+
 func (d *TargetDirectory) WriteFile(ctx context.Context, relativePath string, contents []byte) error {
+	return d.WriteFileWithMode(ctx, relativePath, contents, 0, true)
+}
+
+// WriteFileWithMode writes a file like WriteFile, then sets its permissions to mode.
+//
+// If mode is 0, new files are created with mode 0644 (before umask). If preserveExistingMode is set, or mode is 0,
+// a file that already exists keeps its permissions.
+func (d *TargetDirectory) WriteFileWithMode(ctx context.Context, relativePath string, contents []byte, mode os.FileMode, preserveExistingMode bool) error {
 	if err := d.CheckValid(ctx); err != nil {
 		return err
 	}
 
+	if d.captured != nil {
+		d.captured[relativePath] = contents
+		return nil
+	}
+
 	if err := d.createDirectoriesForFile(ctx, relativePath); err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(path.Join(d.baseDir, relativePath), contents, 0644)
+	filePath := path.Join(d.baseDir, relativePath)
+	_, err := os.Stat(filePath)
+	existed := err == nil
+
+	if err := ioutil.WriteFile(filePath, contents, 0644); err != nil {
+		return err
+	}
+
+	if mode == 0 || (existed && preserveExistingMode) {
+		return nil
+	}
+	// the mode given to WriteFile is reduced by the umask, and not applied to existing files at all
+	return os.Chmod(filePath, mode)
+}
+
+// DeleteFile removes the file at the given relative path, if there is one, and reports whether it existed.
+// Directories are never removed. If writes are captured, nothing is removed from disk, but a file captured
+// earlier is dropped, and whether a file exists is still reported.
+func (d *TargetDirectory) DeleteFile(ctx context.Context, relativePath string) (bool, error) {
+	if err := d.CheckValid(ctx); err != nil {
+		return false, err
+	}
+
+	_, wasCaptured := d.captured[relativePath]
+	delete(d.captured, relativePath)
+
+	filePath := path.Join(d.baseDir, relativePath)
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return wasCaptured, nil
+		}
+		return false, err
+	}
+	if info.IsDir() {
+		return false, fmt.Errorf("cannot delete %s, it is a directory", relativePath)
+	}
+	if d.captured != nil {
+		return true, nil
+	}
+	if err := os.Remove(filePath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckWritable finds out whether WriteFile could write the file at the given relative path, without writing it.
+//
+// An existing file is opened for writing without truncating it. Otherwise, a probe file is created and removed
+// again in the closest existing parent directory, because missing directories would be created there.
+// Always succeeds if writes are captured.
+func (d *TargetDirectory) CheckWritable(ctx context.Context, relativePath string) error {
+	if err := d.CheckValid(ctx); err != nil {
+		return err
+	}
+
+	if d.captured != nil {
+		return nil
+	}
+
+	filePath := path.Join(d.baseDir, relativePath)
+	if fileInfo, err := os.Stat(filePath); err == nil {
+		if fileInfo.IsDir() {
+			return fmt.Errorf("cannot write %s, a directory is in the way", relativePath)
+		}
+		file, err := os.OpenFile(filePath, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("cannot write %s: %s", relativePath, err.Error())
+		}
+		return file.Close()
+	}
+
+	directoryPath := filepath.Dir(filePath)
+	for {
+		fileInfo, err := os.Stat(directoryPath)
+		if err == nil {
+			if !fileInfo.IsDir() {
+				return fmt.Errorf("cannot create path up to %s, something is in the way", strings.ReplaceAll(filepath.Dir(filePath), "\\", "/"))
+			}
+			break
+		}
+		parent := filepath.Dir(directoryPath)
+		if parent == directoryPath {
+			return fmt.Errorf("cannot write %s: %s", relativePath, err.Error())
+		}
+		directoryPath = parent
+	}
+
+	probe, err := ioutil.TempFile(directoryPath, ".write-probe-")
+	if err != nil {
+		return fmt.Errorf("cannot write %s, directory %s is not writable: %s", relativePath, strings.ReplaceAll(directoryPath, "\\", "/"), err.Error())
+	}
+	_ = probe.Close()
+	return os.Remove(probe.Name())
 }
 
 func (d *TargetDirectory) createDirectoriesForFile(ctx context.Context, relativePathForFile string) error {
@@ -132,9 +288,64 @@ func (d *TargetDirectory) parseRenderSpec(ctx context.Context, specYaml []byte)
 	if err != nil {
 		return &api.RenderSpec{}, err
 	}
+	if spec.Version == 0 {
+		// written before render specs were versioned
+		spec.Version = 1
+	}
+	if spec.Version < 0 || spec.Version > api.CurrentRenderSpecVersion {
+		return &api.RenderSpec{}, fmt.Errorf("unsupported format version %d, this version of the library supports up to version %d, please upgrade", spec.Version, api.CurrentRenderSpecVersion)
+	}
 	return spec, nil
 }
 
-func (d *TargetDirectory) renderRenderSpec(ctx context.Context, renderSpec *api.RenderSpec) ([]byte, error) {
-	return yaml.Marshal(renderSpec)
+func (d *TargetDirectory) renderRenderSpec(ctx context.Context, renderSpec *api.RenderSpec, parameterGroups map[string]string) ([]byte, error) {
+	if len(parameterGroups) == 0 || len(renderSpec.Parameters) == 0 {
+		return yaml.Marshal(renderSpec)
+	}
+
+	// yaml.v2 cannot write comments, so we assemble the parameters section by section
+	sections := map[string][]string{}
+	for k := range renderSpec.Parameters {
+		group := parameterGroups[k]
+		sections[group] = append(sections[group], k)
+	}
+	groupNames := []string{}
+	for g := range sections {
+		if g != "" {
+			groupNames = append(groupNames, g)
+		}
+	}
+	sort.Strings(groupNames)
+	if _, ok := sections[""]; ok {
+		groupNames = append(groupNames, "")
+	}
+
+	header, err := yaml.Marshal(&api.RenderSpec{Version: renderSpec.Version, GeneratorName: renderSpec.GeneratorName})
+	if err != nil {
+		return nil, err
+	}
+	// the header ends with "parameters: {}\n", replace that with an open section
+	buf := bytes.NewBufferString(strings.TrimSuffix(string(header), "parameters: {}\n") + "parameters:\n")
+	for _, g := range groupNames {
+		title := g
+		if title == "" {
+			title = "other parameters"
+		}
+		buf.WriteString("  # --- " + title + " ---\n")
+
+		names := sections[g]
+		sort.Strings(names)
+		for _, name := range names {
+			entryYaml, err := yaml.Marshal(map[string]interface{}{name: renderSpec.Parameters[name]})
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range strings.SplitAfter(string(entryYaml), "\n") {
+				if line != "" {
+					buf.WriteString("  " + line)
+				}
+			}
+		}
+	}
+	return buf.Bytes(), nil
 }
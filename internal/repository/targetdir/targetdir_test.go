@@ -8,18 +8,32 @@ import (
 
 // these tests add coverage for some internal error conditions only
 
-func TestCheckValid_TrailingSlash(t *testing.T) {
-	cut := Instance(context.TODO(), "./has-a-slash/")
+func TestCheckValid_SingleTrailingSlashIsRemoved(t *testing.T) {
+	cut := Instance(context.TODO(), "./")
+	require.Nil(t, cut.CheckValid(context.TODO()))
+	require.Equal(t, ".", cut.baseDir)
+}
+
+func TestCheckValid_TrailingSlashes(t *testing.T) {
+	cut := Instance(context.TODO(), "./has-slashes//")
+	actualErr := cut.CheckValid(context.TODO())
+	expected := "error invalid target directory: baseDir ./has-slashes// must not contain more than one trailing slash"
+	require.NotNil(t, actualErr, "unexpected nil error")
+	require.Equal(t, expected, actualErr.Error())
+}
+
+func TestCheckValid_TrailingBackslashes(t *testing.T) {
+	cut := Instance(context.TODO(), "./has-backslashes\\\\")
 	actualErr := cut.CheckValid(context.TODO())
-	expected := "error invalid target directory: baseDir ./has-a-slash/ must not contain trailing slash"
+	expected := "error invalid target directory: baseDir ./has-backslashes\\\\ must not contain more than one trailing slash"
 	require.NotNil(t, actualErr, "unexpected nil error")
 	require.Equal(t, expected, actualErr.Error())
 }
 
-func TestCheckValid_TrailingBackslash(t *testing.T) {
-	cut := Instance(context.TODO(), "./has-a-backslash\\")
+func TestCheckValid_MixedTrailingSlashes(t *testing.T) {
+	cut := Instance(context.TODO(), "./has-mixed\\/")
 	actualErr := cut.CheckValid(context.TODO())
-	expected := "error invalid target directory: baseDir ./has-a-backslash\\ must not contain trailing slash"
+	expected := "error invalid target directory: baseDir ./has-mixed\\/ must not contain more than one trailing slash"
 	require.NotNil(t, actualErr, "unexpected nil error")
 	require.Equal(t, expected, actualErr.Error())
 }
@@ -0,0 +1,44 @@
+package workingdir
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type workingDirKey struct{}
+
+// WithWorkingDir returns a context that makes relative base directories resolve against workingDir instead of
+// the working directory of the process, for every directory instance created with it.
+func WithWorkingDir(ctx context.Context, workingDir string) context.Context {
+	return context.WithValue(ctx, workingDirKey{}, workingDir)
+}
+
+// Resolve joins the working directory from the context with baseDir, see Join.
+func Resolve(ctx context.Context, baseDir string) string {
+	workingDir, _ := ctx.Value(workingDirKey{}).(string)
+	return Join(workingDir, baseDir)
+}
+
+// Join resolves a relative baseDir against workingDir. Absolute and empty base directories, and any base
+// directory if workingDir is empty, are returned unchanged, so they are still validated as given.
+func Join(workingDir string, baseDir string) string {
+	if workingDir == "" || baseDir == "" || filepath.IsAbs(baseDir) || path.IsAbs(baseDir) {
+		return baseDir
+	}
+	return path.Join(workingDir, baseDir)
+}
+
+// TrimTrailingSlash removes a single trailing slash (as added by tab completion) from baseDir.
+func TrimTrailingSlash(baseDir string) string {
+	if HasTrailingSlash(baseDir) {
+		return baseDir[:len(baseDir)-1]
+	}
+	return baseDir
+}
+
+// HasTrailingSlash is true if baseDir ends in a slash or backslash, not counting a root directory "/".
+func HasTrailingSlash(baseDir string) bool {
+	return len(baseDir) > 1 && (strings.HasSuffix(baseDir, "/") || strings.HasSuffix(baseDir, "\\"))
+}
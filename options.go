@@ -0,0 +1,101 @@
+package generatorlib
+
+import (
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/configured"
+	"github.com/mundobaton/go-generator-lib/internal/implementation"
+	"github.com/mundobaton/go-generator-lib/internal/logfacade"
+	"text/template"
+)
+
+// Option configures a generator instance created with New.
+type Option func(*instanceConfig)
+
+type instanceConfig struct {
+	options implementation.InstanceOptions
+	logging bool
+}
+
+// New creates a generator instance, for dependency injection and tests, or to configure behavior for some
+// callers without touching the package level functions, which use Instance.
+//
+// The options apply to every call made through the instance. What the caller puts into the context of a call,
+// e.g. with WithExtraFuncs or WithCache, takes precedence.
+func New(opts ...Option) api.Api {
+	config := &instanceConfig{logging: true}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	var result api.Api
+	if config.logging {
+		result = &logfacade.GeneratorLogfacade{Wrapped: &implementation.GeneratorImpl{}}
+	} else {
+		result = &implementation.GeneratorImpl{}
+	}
+	if len(opts) > 0 {
+		options := config.options
+		result = &configured.Generator{Wrapped: result, Configure: options.Apply}
+	}
+	return result
+}
+
+// ExtraFuncs makes funcs available to all templates rendered by the instance, like WithExtraFuncs.
+func ExtraFuncs(funcs template.FuncMap) Option {
+	return func(config *instanceConfig) {
+		if config.options.ExtraFuncs == nil {
+			config.options.ExtraFuncs = template.FuncMap{}
+		}
+		for name, f := range funcs {
+			config.options.ExtraFuncs[name] = f
+		}
+	}
+}
+
+// PostProcessors makes processors available to all templates rendered by the instance, like WithPostProcessors.
+func PostProcessors(processors map[string]api.PostProcessor) Option {
+	return func(config *instanceConfig) {
+		if config.options.PostProcessors == nil {
+			config.options.PostProcessors = map[string]api.PostProcessor{}
+		}
+		for name, p := range processors {
+			config.options.PostProcessors[name] = p
+		}
+	}
+}
+
+// UseCache makes the instance keep parsed generator specs and templates in c, like WithCache.
+func UseCache(c *Cache) Option {
+	return func(config *instanceConfig) {
+		config.options.Cache = c
+	}
+}
+
+// SafeDefaults evaluates default values in safe defaults mode for every call, as if Request.SafeDefaults was set.
+func SafeDefaults() Option {
+	return func(config *instanceConfig) {
+		config.options.SafeDefaults = true
+	}
+}
+
+// ReproducibleBuild renders in reproducible build mode for every call, as if Request.ReproducibleBuild was set.
+func ReproducibleBuild() Option {
+	return func(config *instanceConfig) {
+		config.options.ReproducibleBuild = true
+	}
+}
+
+// Discovery makes the instance name generator spec files according to options, like WithDiscoveryOptions.
+func Discovery(options api.DiscoveryOptions) Option {
+	return func(config *instanceConfig) {
+		config.options.Discovery = &options
+	}
+}
+
+// WithoutLogging leaves out the debug and warning log messages of the instance. Logging itself is configured
+// globally through go-autumn-logging.
+func WithoutLogging() Option {
+	return func(config *instanceConfig) {
+		config.logging = false
+	}
+}
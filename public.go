@@ -4,31 +4,181 @@ import (
 	"context"
 	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/internal/implementation"
-	"github.com/mundobaton/go-generator-lib/internal/logfacade"
+	"github.com/mundobaton/go-generator-lib/internal/repository/cache"
+	"github.com/mundobaton/go-generator-lib/internal/repository/generatordir"
+	"io"
+	"text/template"
 )
 
+// Instance is the default generator instance, which the package level functions delegate to. Use New to create
+// instances with their own configuration.
 var Instance api.Api
 
 func init() {
-	Instance = &logfacade.GeneratorLogfacade{Wrapped: &implementation.GeneratorImpl{}}
+	Instance = New()
+}
+
+// WithExtraFuncs returns a context that makes funcs available to all templates rendered with it,
+// in addition to the sprig functions. Functions of the same name replace the sprig ones.
+//
+// Pass the returned context to any of the functions that render templates. Calling WithExtraFuncs again
+// on the returned context adds to the functions already present.
+func WithExtraFuncs(ctx context.Context, funcs template.FuncMap) context.Context {
+	return implementation.WithExtraFuncs(ctx, funcs)
+}
+
+// WithPostProcessors returns a context that makes processors available to all templates rendered with it,
+// in addition to the built-in ones. Templates refer to them by name in post_process.
+//
+// Calling WithPostProcessors again on the returned context adds to the post processors already present.
+func WithPostProcessors(ctx context.Context, processors map[string]api.PostProcessor) context.Context {
+	return implementation.WithPostProcessors(ctx, processors)
+}
+
+// Cache keeps parsed generator specs and templates across requests, for long-lived processes such as servers
+// that render the same generators over and over. A spec is reused until its file, or one of its shared variables
+// files, changes modification time or size. A template is reused as long as its content is unchanged.
+// Call Clear to drop all entries. A Cache is safe for concurrent use.
+type Cache = cache.Cache
+
+// NewCache creates an empty Cache, see WithCache.
+func NewCache() *Cache {
+	return cache.New()
+}
+
+// WithCache returns a context that makes all api functions called with it use the given cache.
+// Without a cache, which is the default, every call reads and parses generator specs and templates again.
+func WithCache(ctx context.Context, c *Cache) context.Context {
+	return cache.WithCache(ctx, c)
+}
+
+// WithDiscoveryOptions returns a context that makes all api functions called with it name generator spec files
+// according to options, e.g. Render, Plan or ExportParameterSchema. FindGeneratorNamesWithOptions and
+// ObtainGeneratorSpecWithOptions use the options they are given instead.
+func WithDiscoveryOptions(ctx context.Context, options api.DiscoveryOptions) context.Context {
+	return generatordir.WithDiscoveryOptions(ctx, options)
 }
 
 func FindGeneratorNames(ctx context.Context, sourceBaseDir string) ([]string, error) {
 	return Instance.FindGeneratorNames(ctx, sourceBaseDir)
 }
 
+func CatalogGenerators(ctx context.Context, sourceBaseDir string) *api.GeneratorCatalog {
+	return Instance.CatalogGenerators(ctx, sourceBaseDir)
+}
+
 func ObtainGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) (*api.GeneratorSpec, error) {
 	return Instance.ObtainGeneratorSpec(ctx, sourceBaseDir, generatorName)
 }
 
+func FindGeneratorNamesWithOptions(ctx context.Context, sourceBaseDir string, options api.DiscoveryOptions) ([]string, error) {
+	return Instance.FindGeneratorNamesWithOptions(ctx, sourceBaseDir, options)
+}
+
+func ObtainGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.DiscoveryOptions) (*api.GeneratorSpec, error) {
+	return Instance.ObtainGeneratorSpecWithOptions(ctx, sourceBaseDir, generatorName, options)
+}
+
+func ReadGeneratorSpecRaw(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, string, error) {
+	return Instance.ReadGeneratorSpecRaw(ctx, sourceBaseDir, generatorName)
+}
+
+func ValidateGeneratorSpec(ctx context.Context, sourceBaseDir string, generatorName string) []error {
+	return Instance.ValidateGeneratorSpec(ctx, sourceBaseDir, generatorName)
+}
+
+func ValidateGeneratorSpecWithOptions(ctx context.Context, sourceBaseDir string, generatorName string, options api.SpecValidationOptions) *api.SpecValidationResponse {
+	return Instance.ValidateGeneratorSpecWithOptions(ctx, sourceBaseDir, generatorName, options)
+}
+
+func DiffGeneratorSpecs(ctx context.Context, oldSpec *api.GeneratorSpec, newSpec *api.GeneratorSpec) *api.GeneratorSpecDiff {
+	return Instance.DiffGeneratorSpecs(ctx, oldSpec, newSpec)
+}
+
+func ExportParameterSchema(ctx context.Context, sourceBaseDir string, generatorName string) ([]byte, error) {
+	return Instance.ExportParameterSchema(ctx, sourceBaseDir, generatorName)
+}
+
+func ValidateParameters(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, options api.ParameterValidationOptions) *api.ParameterValidationResponse {
+	return Instance.ValidateParameters(ctx, sourceBaseDir, generatorName, parameters, options)
+}
+
 func WriteRenderSpecWithDefaults(ctx context.Context, request *api.Request, generatorName string) *api.Response {
 	return Instance.WriteRenderSpecWithDefaults(ctx, request, generatorName)
 }
 
+func WriteRenderSpecWithDefaultsTo(ctx context.Context, request *api.Request, generatorName string, w io.Writer) *api.Response {
+	return Instance.WriteRenderSpecWithDefaultsTo(ctx, request, generatorName, w)
+}
+
 func WriteRenderSpecWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
 	return Instance.WriteRenderSpecWithValues(ctx, request, generatorName, parameters)
 }
 
+func WriteRenderSpecWithValuesTo(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}, w io.Writer) *api.Response {
+	return Instance.WriteRenderSpecWithValuesTo(ctx, request, generatorName, parameters, w)
+}
+
+func NormalizeRenderSpec(ctx context.Context, request *api.Request, generatorName string) *api.Response {
+	return Instance.NormalizeRenderSpec(ctx, request, generatorName)
+}
+
 func Render(ctx context.Context, request *api.Request) *api.Response {
 	return Instance.Render(ctx, request)
 }
+
+func RenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.Response {
+	return Instance.RenderWithValues(ctx, request, generatorName, parameters)
+}
+
+func RenderWithStruct(ctx context.Context, request *api.Request, generatorName string, parameters interface{}) *api.Response {
+	return Instance.RenderWithStruct(ctx, request, generatorName, parameters)
+}
+
+func ParseParameterArgs(ctx context.Context, args []string) (map[string]interface{}, error) {
+	return Instance.ParseParameterArgs(ctx, args)
+}
+
+func LoadParametersFromEnvFile(ctx context.Context, path string) (map[string]interface{}, error) {
+	return Instance.LoadParametersFromEnvFile(ctx, path)
+}
+
+func RenderExpression(ctx context.Context, request *api.Request, generatorName string, expression string) (string, error) {
+	return Instance.RenderExpression(ctx, request, generatorName, expression)
+}
+
+func BatchRender(ctx context.Context, requests []*api.Request) *api.BatchResponse {
+	return Instance.BatchRender(ctx, requests)
+}
+
+func CheckUpToDate(ctx context.Context, request *api.Request) *api.CheckResponse {
+	return Instance.CheckUpToDate(ctx, request)
+}
+
+func RenderToWriter(ctx context.Context, request *api.Request, targetPath string, w io.Writer) *api.Response {
+	return Instance.RenderToWriter(ctx, request, targetPath, w)
+}
+
+func PlanRender(ctx context.Context, request *api.Request) *api.PlanResponse {
+	return Instance.PlanRender(ctx, request)
+}
+
+func PlanRenderWithValues(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.PlanResponse {
+	return Instance.PlanRenderWithValues(ctx, request, generatorName, parameters)
+}
+
+func ValidateRenderSpec(ctx context.Context, request *api.Request) *api.ParameterValidationResponse {
+	return Instance.ValidateRenderSpec(ctx, request)
+}
+
+func ResolveDefaults(ctx context.Context, request *api.Request, generatorName string, parameters map[string]interface{}) *api.DefaultResolutionResponse {
+	return Instance.ResolveDefaults(ctx, request, generatorName, parameters)
+}
+
+func CompareRenderSpecToDefaults(ctx context.Context, request *api.Request) *api.DefaultComparisonResponse {
+	return Instance.CompareRenderSpecToDefaults(ctx, request)
+}
+
+func FindUnusedVariables(ctx context.Context, request *api.Request) *api.UnusedVariablesResponse {
+	return Instance.FindUnusedVariables(ctx, request)
+}
@@ -0,0 +1,107 @@
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+)
+
+// helpers for generator authors who want to start a new generator from an existing one
+
+// NewGeneratorFrom writes a skeleton generator called newGeneratorName into targetBaseDir, which may be the same
+// directory as sourceBaseDir.
+//
+// The skeleton consists of a generator spec "generator-<newGeneratorName>.yaml" that declares the same variables
+// as the existing generator fromGeneratorName in sourceBaseDir, and a single sample template
+// "<newGeneratorName>/sample.txt.tmpl" that shows how to use each of them.
+//
+// Existing files are never overwritten, if the new generator spec already exists, an error is returned.
+func NewGeneratorFrom(ctx context.Context, sourceBaseDir string, fromGeneratorName string, targetBaseDir string, newGeneratorName string) error {
+	if newGeneratorName == "" || strings.ContainsAny(newGeneratorName, "/\\") {
+		return fmt.Errorf("invalid generator name '%s', must be nonempty and must not contain slashes", newGeneratorName)
+	}
+
+	fromSpec, err := generatorlib.ObtainGeneratorSpec(ctx, sourceBaseDir, fromGeneratorName)
+	if err != nil {
+		return err
+	}
+
+	targetDir := targetdir.Instance(ctx, targetBaseDir)
+	specFilename := "generator-" + newGeneratorName + ".yaml"
+	if targetDir.Exists(ctx, specFilename) {
+		return fmt.Errorf("generator spec file %s already exists, will not overwrite it", specFilename)
+	}
+
+	variableNames := make([]string, 0, len(fromSpec.Variables))
+	for name := range fromSpec.Variables {
+		variableNames = append(variableNames, name)
+	}
+	sort.Strings(variableNames)
+
+	templatePath := newGeneratorName + "/sample.txt.tmpl"
+	specYaml, err := skeletonSpec(fromSpec, variableNames, templatePath)
+	if err != nil {
+		return fmt.Errorf("error converting generator spec %s to yaml: %s", specFilename, err.Error())
+	}
+
+	if err := targetDir.WriteFile(ctx, templatePath, sampleTemplate(newGeneratorName, variableNames)); err != nil {
+		return err
+	}
+	return targetDir.WriteFile(ctx, specFilename, specYaml)
+}
+
+// skeletonSpec produces the yaml for the new generator spec, leaving out fields that are not set
+func skeletonSpec(fromSpec *api.GeneratorSpec, variableNames []string, templatePath string) ([]byte, error) {
+	variables := yaml.MapSlice{}
+	for _, name := range variableNames {
+		varSpec := fromSpec.Variables[name]
+		declaration := yaml.MapSlice{}
+		declaration = appendIfSet(declaration, "label", varSpec.Label)
+		declaration = appendIfSet(declaration, "description", varSpec.Description)
+		declaration = appendIfSet(declaration, "group", varSpec.Group)
+		declaration = appendIfSet(declaration, "pattern", varSpec.ValidationPattern)
+		if len(varSpec.ValidationPatterns) > 0 {
+			declaration = append(declaration, yaml.MapItem{Key: "patterns", Value: varSpec.ValidationPatterns})
+		}
+		declaration = appendIfSet(declaration, "pattern_mode", varSpec.ValidationMode)
+		declaration = appendIfSet(declaration, "item_pattern", varSpec.ItemPattern)
+		if len(varSpec.RequiredKeys) > 0 {
+			declaration = append(declaration, yaml.MapItem{Key: "required_keys", Value: varSpec.RequiredKeys})
+		}
+		if varSpec.HasDefault() {
+			declaration = append(declaration, yaml.MapItem{Key: "default", Value: varSpec.DefaultValue})
+		}
+		declaration = appendIfSet(declaration, "transform", varSpec.Transform)
+		variables = append(variables, yaml.MapItem{Key: name, Value: declaration})
+	}
+
+	spec := yaml.MapSlice{
+		{Key: "templates", Value: []yaml.MapSlice{{
+			{Key: "source", Value: templatePath},
+			{Key: "target", Value: "sample.txt"},
+		}}},
+		{Key: "variables", Value: variables},
+	}
+	return yaml.Marshal(spec)
+}
+
+func appendIfSet(declaration yaml.MapSlice, key string, value string) yaml.MapSlice {
+	if value == "" {
+		return declaration
+	}
+	return append(declaration, yaml.MapItem{Key: key, Value: value})
+}
+
+func sampleTemplate(newGeneratorName string, variableNames []string) []byte {
+	var sb strings.Builder
+	sb.WriteString("{{- /* sample template for generator " + newGeneratorName + ", replace it with your own */ -}}\n")
+	for _, name := range variableNames {
+		sb.WriteString(name + ": {{ ." + name + " }}\n")
+	}
+	return []byte(sb.String())
+}
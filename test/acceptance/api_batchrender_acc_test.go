@@ -0,0 +1,99 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestBatchRender_ShouldRenderAllRequestsInOrder(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/batch-render-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("valid render spec files for generators main and items in the same target directory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte(`generator: main
+parameters:
+  serviceName: 'temp-service'
+`)))
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-items.yaml", []byte(`generator: items
+parameters: {}
+`)))
+
+	docs.When("BatchRender is invoked with both requests")
+	requests := []*api.Request{
+		{
+			SourceBaseDir: sourcedirpath,
+			TargetBaseDir: targetdirpath,
+		},
+		{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  targetdirpath,
+			RenderSpecFile: "generated-items.yaml",
+		},
+	}
+	actualResponse := generatorlib.BatchRender(context.TODO(), requests)
+
+	docs.Then("both requests are rendered and reported in order")
+	require.True(t, actualResponse.Success)
+	require.Empty(t, actualResponse.Errors)
+	require.Equal(t, 2, len(actualResponse.Responses))
+	require.True(t, actualResponse.Responses[0].Success)
+	require.Equal(t, "sub/sub.go.txt", actualResponse.Responses[0].RenderedFiles[0].RelativeFilePath)
+	require.Equal(t, "main.go.txt", actualResponse.Responses[0].RenderedFiles[1].RelativeFilePath)
+	require.True(t, actualResponse.Responses[1].Success)
+	require.Equal(t, 3, len(actualResponse.Responses[1].RenderedFiles))
+	require.Equal(t, "first.txt", actualResponse.Responses[1].RenderedFiles[0].RelativeFilePath)
+
+	_, err := dir.ReadFile(context.TODO(), "main.go.txt")
+	require.Nil(t, err)
+	_, err = dir.ReadFile(context.TODO(), "third.txt")
+	require.Nil(t, err)
+}
+
+func TestBatchRender_ShouldContinueAndCollectErrors(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/batch-render-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator items, but none for generator main")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-items.yaml", []byte(`generator: items
+parameters: {}
+`)))
+
+	docs.When("BatchRender is invoked with the failing request first")
+	requests := []*api.Request{
+		{
+			SourceBaseDir: sourcedirpath,
+			TargetBaseDir: targetdirpath,
+		},
+		{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  targetdirpath,
+			RenderSpecFile: "generated-items.yaml",
+		},
+	}
+	actualResponse := generatorlib.BatchRender(context.TODO(), requests)
+
+	docs.Then("the second request is still rendered and the error of the first is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.Responses))
+	require.False(t, actualResponse.Responses[0].Success)
+	require.True(t, actualResponse.Responses[1].Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Contains(t, actualResponse.Errors[0].Error(), "request #1: error reading render spec file generated-main.yaml in target directory ../output/batch-render-2: ")
+
+	_, err := dir.ReadFile(context.TODO(), "first.txt")
+	require.Nil(t, err)
+}
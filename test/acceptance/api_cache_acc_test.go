@@ -0,0 +1,115 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCache_ShouldPickUpChangedFiles(t *testing.T) {
+	docs.Given("a generator source directory that can be modified, and a valid target directory")
+	sourcedirpath := "../output/cache-1/generator"
+	targetdirpath := "../output/cache-1/target"
+	require.Nil(t, os.RemoveAll("../output/cache-1"))
+	require.Nil(t, os.MkdirAll(sourcedirpath, 0755))
+	require.Nil(t, os.MkdirAll(targetdirpath, 0755))
+	writeSpec := func(defaultMessage string) {
+		spec := "templates:\n  - source: 'greeting.txt.tmpl'\n    target: 'greeting.txt'\nvariables:\n  message:\n    description: 'The greeting.'\n    default: '" + defaultMessage + "'\n"
+		require.Nil(t, ioutil.WriteFile(sourcedirpath+"/generator-cached.yaml", []byte(spec), 0644))
+	}
+	writeTemplate := func(contents string) {
+		require.Nil(t, ioutil.WriteFile(sourcedirpath+"/greeting.txt.tmpl", []byte(contents), 0644))
+	}
+	writeSpec("hello")
+	writeTemplate("{{ .message }} v1\n")
+
+	docs.Given("a valid render spec file for generator cached")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-cached.yaml", []byte("generator: cached\n")))
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-cached.yaml",
+	}
+	renderAndRead := func(ctx context.Context) string {
+		actualResponse := generatorlib.Render(ctx, request)
+		require.True(t, actualResponse.Success, actualResponse.Errors)
+		actual, err := dir.ReadFile(context.TODO(), "greeting.txt")
+		require.Nil(t, err)
+		return string(actual)
+	}
+
+	docs.Given("a context with a cache")
+	ctx := generatorlib.WithCache(context.TODO(), generatorlib.NewCache())
+
+	docs.When("Render is invoked twice with the cache")
+	docs.Then("both render runs produce the same output")
+	require.Equal(t, "hello v1\n", renderAndRead(ctx))
+	require.Equal(t, "hello v1\n", renderAndRead(ctx))
+
+	docs.When("the template is changed and Render is invoked again")
+	writeTemplate("{{ .message }} version 2\n")
+
+	docs.Then("the changed template is used")
+	require.Equal(t, "hello version 2\n", renderAndRead(ctx))
+
+	docs.When("the generator spec is changed and Render is invoked again")
+	writeSpec("good morning")
+
+	docs.Then("the changed generator spec is used")
+	require.Equal(t, "good morning version 2\n", renderAndRead(ctx))
+}
+
+func TestCache_ShouldReturnIndependentSpecs(t *testing.T) {
+	docs.Given("a valid generator source directory and a context with a cache")
+	sourcedir := "../resources/valid-generator-simple"
+	ctx := generatorlib.WithCache(context.TODO(), generatorlib.NewCache())
+
+	docs.When("ObtainGeneratorSpec is invoked, and the caller modifies the result")
+	first, err := generatorlib.ObtainGeneratorSpec(ctx, sourcedir, "main")
+	require.Nil(t, err)
+	delete(first.Variables, "serviceName")
+	first.Templates[0].RelativeTargetPath = "modified"
+
+	docs.Then("a second invocation returns the unmodified spec")
+	second, err := generatorlib.ObtainGeneratorSpec(ctx, sourcedir, "main")
+	require.Nil(t, err)
+	uncached, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, "main")
+	require.Nil(t, err)
+	require.Equal(t, uncached, second)
+}
+
+func _benchmarkCheckUpToDate(b *testing.B, ctx context.Context) {
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/cache-2"
+	require.Nil(b, os.RemoveAll(targetdirpath))
+	require.Nil(b, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(b, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\n")))
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	require.True(b, generatorlib.Render(context.TODO(), request).Success)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if !generatorlib.CheckUpToDate(ctx, request).Success {
+			b.Fatal("rendered target is not up to date")
+		}
+	}
+}
+
+func BenchmarkCheckUpToDate_WithoutCache(b *testing.B) {
+	_benchmarkCheckUpToDate(b, context.TODO())
+}
+
+func BenchmarkCheckUpToDate_WithCache(b *testing.B) {
+	_benchmarkCheckUpToDate(b, generatorlib.WithCache(context.TODO(), generatorlib.NewCache()))
+}
@@ -0,0 +1,84 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCatalogGenerators_ShouldListAllGenerators(t *testing.T) {
+	docs.Given("a valid generator source directory with several generators")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.When("CatalogGenerators is invoked")
+	actual := generatorlib.CatalogGenerators(context.TODO(), sourcedir)
+
+	docs.Then("it is successful and lists every generator in name order")
+	require.True(t, actual.Success)
+	require.Empty(t, actual.Errors)
+	names := []string{}
+	for _, g := range actual.Generators {
+		names = append(names, g.Name)
+		require.Empty(t, g.Errors, g.Name)
+		require.NotNil(t, g.Spec, g.Name)
+	}
+	require.Equal(t, []string{"docker", "emptydefaults", "entries", "items", "justcopy", "main", "templatevars"}, names)
+
+	docs.Then("each generator comes with its variables and which template produces which target")
+	main := actual.Generators[5]
+	expectedSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, "main")
+	require.Nil(t, err)
+	require.Equal(t, expectedSpec, main.Spec)
+	require.Equal(t, []api.CatalogOutput{
+		{Source: "src/sub/sub.go.tmpl", Target: "sub/sub.go.txt"},
+		{Source: "src/main.go.tmpl", Target: "main.go.txt"},
+	}, main.Outputs)
+	require.Equal(t, []api.CatalogOutput{
+		{Source: "item.txt.tmpl", Target: "{{ .item.file }}.txt", Iterates: true, Conditional: true},
+	}, actual.Generators[3].Outputs)
+}
+
+func TestCatalogGenerators_ShouldCollectErrorsPerGenerator(t *testing.T) {
+	docs.Given("a generator source directory where some generator specs cannot be read")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.When("CatalogGenerators is invoked")
+	actual := generatorlib.CatalogGenerators(context.TODO(), sourcedir)
+
+	docs.Then("it is not successful, but still lists every generator")
+	require.False(t, actual.Success)
+	require.Empty(t, actual.Errors)
+	expectedNames, err := generatorlib.FindGeneratorNames(context.TODO(), sourcedir)
+	require.Nil(t, err)
+	require.Equal(t, len(expectedNames), len(actual.Generators))
+
+	docs.Then("the broken generators have errors and no spec, the others are listed normally")
+	broken := map[string]string{}
+	brokenNames := []string{}
+	for i, g := range actual.Generators {
+		require.Equal(t, expectedNames[i], g.Name)
+		if g.Spec == nil {
+			require.Equal(t, 1, len(g.Errors), g.Name)
+			broken[g.Name] = g.Errors[0].Error()
+			brokenNames = append(brokenNames, g.Name)
+		} else {
+			require.Empty(t, g.Errors, g.Name)
+		}
+	}
+	require.Equal(t, []string{"defaultemptyconflict", "duplicatekey", "futureversion", "missinginclude", "undefinedpatternref", "unknownkey"}, brokenNames)
+	require.Equal(t, "error parsing generator spec from file generator-futureversion.yaml: generator requires spec version 999999, but this version of the library supports up to version 1, please upgrade", broken["futureversion"])
+}
+
+func TestCatalogGenerators_ShouldComplainMissingDirectory(t *testing.T) {
+	docs.When("CatalogGenerators is invoked for a source directory that does not exist")
+	actual := generatorlib.CatalogGenerators(context.TODO(), "../resources/notthere")
+
+	docs.Then("a top level error is reported")
+	require.False(t, actual.Success)
+	require.Empty(t, actual.Generators)
+	require.Equal(t, 1, len(actual.Errors))
+	require.Equal(t, "invalid generator directory: baseDir ../resources/notthere does not exist", actual.Errors[0].Error())
+}
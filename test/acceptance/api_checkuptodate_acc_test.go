@@ -0,0 +1,97 @@
+package acceptance
+
+import (
+	"context"
+	"fmt"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func _testCheckUpToDate_renderedTarget(t *testing.T, testcase uint) (*api.Request, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a target directory that has been rendered")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/check-up-to-date-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\n")))
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	require.True(t, generatorlib.Render(context.TODO(), request).Success)
+	return request, dir
+}
+
+func TestCheckUpToDate_ShouldSucceedIfUpToDate(t *testing.T) {
+	request, _ := _testCheckUpToDate_renderedTarget(t, 1)
+
+	docs.When("CheckUpToDate is invoked")
+	actualResponse := generatorlib.CheckUpToDate(context.TODO(), request)
+
+	docs.Then("no stale files are reported")
+	require.Equal(t, &api.CheckResponse{Success: true}, actualResponse)
+}
+
+func TestCheckUpToDate_ShouldReportChangedFile(t *testing.T) {
+	request, dir := _testCheckUpToDate_renderedTarget(t, 2)
+
+	docs.Given("a rendered file has been modified by hand")
+	modified := "imagine a European wildcat\n\nthen look up something else\n"
+	require.Nil(t, dir.WriteFile(context.TODO(), "main.txt", []byte(modified)))
+
+	docs.When("CheckUpToDate is invoked")
+	actualResponse := generatorlib.CheckUpToDate(context.TODO(), request)
+
+	docs.Then("the file is reported as stale with a diff, and is left unchanged")
+	expectedResponse := &api.CheckResponse{
+		Success: false,
+		StaleFiles: []api.StaleFile{
+			{
+				RelativeFilePath: "main.txt",
+				Diff: ` imagine a European wildcat
+ 
+-then look up something else
++then look up something in a list: two
++
++then look up something in a structure in a list: [sub 1 sub 2]
++(value is itself a list)
+`,
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	actual, err := dir.ReadFile(context.TODO(), "main.txt")
+	require.Nil(t, err)
+	require.Equal(t, modified, string(actual))
+}
+
+func TestCheckUpToDate_ShouldReportMissingFile(t *testing.T) {
+	request, _ := _testCheckUpToDate_renderedTarget(t, 3)
+
+	docs.Given("a rendered file has been deleted")
+	require.Nil(t, os.Remove("../output/check-up-to-date-3/main.txt"))
+
+	docs.When("CheckUpToDate is invoked")
+	actualResponse := generatorlib.CheckUpToDate(context.TODO(), request)
+
+	docs.Then("the file is reported as missing, and not written")
+	expectedResponse := &api.CheckResponse{
+		Success: false,
+		StaleFiles: []api.StaleFile{
+			{
+				RelativeFilePath: "main.txt",
+				Missing:          true,
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	_, err := os.Stat("../output/check-up-to-date-3/main.txt")
+	require.True(t, os.IsNotExist(err))
+}
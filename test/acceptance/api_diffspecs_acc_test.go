@@ -0,0 +1,74 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiffGeneratorSpecs_ShouldReportNoChangesForIdenticalSpecs(t *testing.T) {
+	docs.Given("the same valid generator spec twice")
+	oldSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "main")
+	require.Nil(t, err)
+	newSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "main")
+	require.Nil(t, err)
+
+	docs.When("DiffGeneratorSpecs is invoked")
+	actual := generatorlib.DiffGeneratorSpecs(context.TODO(), oldSpec, newSpec)
+
+	docs.Then("the diff is empty")
+	require.True(t, actual.IsEmpty())
+}
+
+func TestDiffGeneratorSpecs_ShouldReportChanges(t *testing.T) {
+	docs.Given("two different valid generator specs")
+	oldSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "emptydefaults")
+	require.Nil(t, err)
+	newSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "main")
+	require.Nil(t, err)
+
+	docs.Given("a variable in the new spec that changed compared to the old spec")
+	newSpec.Variables["emptyStringDefault"] = api.VariableSpec{
+		Description:       "A variable with an empty string as default.",
+		ValidationPattern: "^.*$",
+	}
+
+	docs.When("DiffGeneratorSpecs is invoked")
+	actual := generatorlib.DiffGeneratorSpecs(context.TODO(), oldSpec, newSpec)
+
+	docs.Then("all differences are reported")
+	require.False(t, actual.IsEmpty())
+	require.Equal(t, []string{"helloMessage", "serviceName", "serviceUrl"}, actual.AddedVariables)
+	require.Equal(t, []string{"missingDefault"}, actual.RemovedVariables)
+	require.Equal(t, []string{"emptyStringDefault", "serviceName"}, actual.NewlyRequiredVariables)
+	require.Equal(t, 1, len(actual.ChangedVariables))
+	require.Equal(t, "emptyStringDefault", actual.ChangedVariables[0].Name)
+	require.True(t, actual.ChangedVariables[0].DefaultChanged)
+	require.True(t, actual.ChangedVariables[0].PatternChanged)
+	require.False(t, actual.ChangedVariables[0].DescriptionChanged)
+	require.Equal(t, newSpec.Templates, actual.AddedTemplates)
+	require.Equal(t, oldSpec.Templates, actual.RemovedTemplates)
+	require.Empty(t, actual.ChangedTemplates)
+}
+
+func TestDiffGeneratorSpecs_ShouldReportChangedTemplates(t *testing.T) {
+	docs.Given("a valid generator spec and a copy with a changed template condition")
+	oldSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "items")
+	require.Nil(t, err)
+	newSpec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), "../resources/valid-generator-simple", "items")
+	require.Nil(t, err)
+	newSpec.Templates[0].Condition = ""
+
+	docs.When("DiffGeneratorSpecs is invoked")
+	actual := generatorlib.DiffGeneratorSpecs(context.TODO(), oldSpec, newSpec)
+
+	docs.Then("the template change is reported")
+	require.Empty(t, actual.AddedTemplates)
+	require.Empty(t, actual.RemovedTemplates)
+	require.Equal(t, 1, len(actual.ChangedTemplates))
+	require.Equal(t, `{{ if eq .item.file "fourth" }}false{{ end }}`, actual.ChangedTemplates[0].Old.Condition)
+	require.Equal(t, "", actual.ChangedTemplates[0].New.Condition)
+}
@@ -0,0 +1,269 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExportParameterSchema_ShouldMapVariables(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a generator with a required variable with label and pattern, and a variable with a default")
+	name := "labels"
+
+	docs.When("ExportParameterSchema is invoked")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, name)
+
+	docs.Then("a json schema describing the variables is returned")
+	require.Nil(t, err)
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "helloMessage": {
+      "default": "hello world",
+      "description": "A message to be inserted in the code.",
+      "type": "string"
+    },
+    "serviceName": {
+      "description": "The name of the service to be rendered, lowercase letters and dashes only.",
+      "pattern": "^[a-z-]+$",
+      "title": "Service name"
+    }
+  },
+  "required": [
+    "serviceName"
+  ],
+  "title": "labels",
+  "type": "object"
+}
+`
+	require.Equal(t, expected, string(actual))
+}
+
+func TestExportParameterSchema_ShouldMapStructuredDefaults(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a generator with structured default values")
+	name := "main"
+
+	docs.When("ExportParameterSchema is invoked")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, name)
+
+	docs.Then("lists become arrays and maps become objects")
+	require.Nil(t, err)
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "helloMessage": {
+      "default": "hello world",
+      "description": "A message to be inserted in the code.",
+      "type": "string"
+    },
+    "structureList": {
+      "default": [
+        "one",
+        "two",
+        {
+          "three": [
+            "sub 1",
+            "sub 2"
+          ]
+        }
+      ],
+      "description": "A structured parameter that is a list at top level",
+      "type": "array"
+    },
+    "structureMap": {
+      "default": {
+        "commonName": "European wildcat",
+        "species": "felis silvestris"
+      },
+      "description": "A structured parameter that is a map at top level",
+      "type": "object"
+    }
+  },
+  "required": [],
+  "title": "main",
+  "type": "object"
+}
+`
+	require.Equal(t, expected, string(actual))
+}
+
+func TestExportParameterSchema_ShouldComplainMissingGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.When("ExportParameterSchema is invoked for a generator that does not exist")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, "doesnotexist")
+
+	docs.Then("an appropriate error is returned")
+	require.Empty(t, actual)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "error reading generator spec file generator-doesnotexist.yaml: ")
+}
+
+func TestExportParameterSchema_ShouldMapDeclaredTypes(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a generator with list and map variables without defaults")
+	name := "schematypes"
+
+	docs.When("ExportParameterSchema is invoked")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, name)
+
+	docs.Then("the types are taken from the declarations")
+	require.Nil(t, err)
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "labels": {
+      "description": "The labels to apply, there is no default either.",
+      "type": "object"
+    },
+    "tags": {
+      "description": "The tags to apply, there is no default.",
+      "type": "array"
+    }
+  },
+  "required": [
+    "labels",
+    "tags"
+  ],
+  "title": "schematypes",
+  "type": "object"
+}
+`
+	require.Equal(t, expected, string(actual))
+}
+
+func TestExportParameterSchema_ShouldMapDurations(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a generator with a duration variable")
+	name := "duration"
+
+	docs.When("ExportParameterSchema is invoked")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, name)
+
+	docs.Then("the duration is a string with a pattern that only admits durations")
+	require.Nil(t, err)
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "timeout": {
+      "default": "30s",
+      "description": "How long to wait for the service to become ready.",
+      "pattern": "^[-+]?(0|(([0-9]+(\\.[0-9]*)?|\\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$",
+      "type": "string"
+    }
+  },
+  "required": [],
+  "title": "duration",
+  "type": "object"
+}
+`
+	require.Equal(t, expected, string(actual))
+}
+
+func TestExportParameterSchema_ShouldMapStructureRules(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a generator with item patterns and required keys, some of them nested")
+	name := "nested"
+
+	docs.When("ExportParameterSchema is invoked")
+	actual, err := generatorlib.ExportParameterSchema(context.TODO(), sourcedir, name)
+
+	docs.Then("item patterns apply to the items, and required keys to the map, or to each item of a list")
+	require.Nil(t, err)
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "database": {
+      "description": "The database connection.",
+      "oneOf": [
+        {
+          "properties": {
+            "credentials": {
+              "required": [
+                "user"
+              ],
+              "type": "object"
+            }
+          },
+          "required": [
+            "url",
+            "credentials"
+          ],
+          "type": "object"
+        },
+        {
+          "items": {
+            "properties": {
+              "credentials": {
+                "required": [
+                  "user"
+                ],
+                "type": "object"
+              }
+            },
+            "required": [
+              "url",
+              "credentials"
+            ],
+            "type": "object"
+          },
+          "type": "array"
+        }
+      ]
+    },
+    "hostnames": {
+      "default": [],
+      "description": "Additional host names, lowercase letters and dots only.",
+      "items": {
+        "pattern": "^[a-z.]+$"
+      },
+      "type": "array"
+    },
+    "upstreams": {
+      "description": "The upstream servers, each with a host and a port.",
+      "oneOf": [
+        {
+          "required": [
+            "host",
+            "port"
+          ],
+          "type": "object"
+        },
+        {
+          "items": {
+            "required": [
+              "host",
+              "port"
+            ],
+            "type": "object"
+          },
+          "type": "array"
+        }
+      ]
+    }
+  },
+  "required": [
+    "database",
+    "upstreams"
+  ],
+  "title": "nested",
+  "type": "object"
+}
+`
+	require.Equal(t, expected, string(actual))
+}
@@ -0,0 +1,40 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestFindUnusedVariables_ShouldReportVariablesNoTemplateRefersTo(t *testing.T) {
+	docs.Given("a generator whose variables are used in a target path, a condition, a range, an index lookup and an included template, except for two")
+	targetdirpath := "../output/unused-variables-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec that sets one of the unused variables")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-unused.yaml", []byte(
+		"generator: unused\nparameters:\n  serviceName: my-service\n  owner: team-a\n  legacyFlag: true\n")))
+
+	docs.When("FindUnusedVariables is invoked")
+	request := &api.Request{
+		SourceBaseDir:  "../resources/valid-generator-structured",
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-unused.yaml",
+	}
+	actualResponse := generatorlib.FindUnusedVariables(context.TODO(), request)
+
+	docs.Then("exactly the unused variables are reported, and those among them that the render spec sets")
+	expectedResponse := &api.UnusedVariablesResponse{
+		Success:         true,
+		UnusedVariables: []string{"legacyFlag", "oldSetting"},
+		SetInRenderSpec: []string{"legacyFlag"},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
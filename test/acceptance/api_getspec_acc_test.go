@@ -2,6 +2,7 @@ package acceptance
 
 import (
 	"context"
+	"encoding/json"
 	generatorlib "github.com/mundobaton/go-generator-lib"
 	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/docs"
@@ -70,7 +71,7 @@ func TestObtainGeneratorSpec_ShouldReturnCorrectStructuredSpec(t *testing.T) {
 				DefaultValue: []interface{}{
 					"one",
 					"two",
-					map[interface{}]interface{}{
+					map[string]interface{}{
 						"three": []interface{}{
 							"sub 1",
 							"sub 2",
@@ -80,7 +81,7 @@ func TestObtainGeneratorSpec_ShouldReturnCorrectStructuredSpec(t *testing.T) {
 			},
 			"structureMap": {
 				Description: "A structured parameter that is a map at top level",
-				DefaultValue: map[interface{}]interface{}{
+				DefaultValue: map[string]interface{}{
 					"species":    "felis silvestris",
 					"commonName": "European wildcat",
 				},
@@ -91,6 +92,32 @@ func TestObtainGeneratorSpec_ShouldReturnCorrectStructuredSpec(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestObtainGeneratorSpec_ShouldReturnLabels(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a valid generator name for a spec where some variables have a label")
+	name := "labels"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("the labels are returned separately from the descriptions")
+	expected := map[string]api.VariableSpec{
+		"serviceName": {
+			Label:             "Service name",
+			Description:       "The name of the service to be rendered, lowercase letters and dashes only.",
+			ValidationPattern: "^[a-z-]+$",
+		},
+		"helloMessage": {
+			Description:  "A message to be inserted in the code.",
+			DefaultValue: "hello world",
+		},
+	}
+	require.Nil(t, err)
+	require.Equal(t, expected, actual.Variables)
+}
+
 func TestObtainGeneratorSpec_ShouldFailOnMissingGeneratorFile(t *testing.T) {
 	docs.Given("a valid generator source directory")
 	sourcedir := "../resources/valid-generator-simple"
@@ -142,9 +169,60 @@ func TestObtainGeneratorSpec_ShouldFailOnGeneratorYamlWithUnknownKeys(t *testing
 	require.Equal(t, expectedErr, err.Error())
 }
 
-func TestObtainGeneratorSpec_ShouldFailOnGeneratorDirWithTrailingSlash(t *testing.T) {
+func TestObtainGeneratorSpec_ShouldDistinguishEmptyFromMissingDefault(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a valid generator name whose spec has empty, null and missing defaults")
+	name := "nulldefaults"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("only default_empty makes the empty string the default, null and missing defaults make a variable required")
+	require.Nil(t, err)
+	emptyDefault := actual.Variables["emptyDefault"]
+	nullDefault := actual.Variables["nullDefault"]
+	missingDefault := actual.Variables["missingDefault"]
+	require.Equal(t, "", emptyDefault.DefaultValue)
+	require.True(t, emptyDefault.HasDefault())
+	require.Nil(t, nullDefault.DefaultValue)
+	require.False(t, nullDefault.HasDefault())
+	require.Nil(t, missingDefault.DefaultValue)
+	require.False(t, missingDefault.HasDefault())
+}
+
+func TestObtainGeneratorSpec_ShouldFailOnDefaultEmptyWithDefault(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a valid generator name whose spec has a variable with both a default and default_empty")
+	name := "defaultemptyconflict"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	_, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("an appropriate error is returned")
+	require.NotNil(t, err)
+	require.Equal(t, "error parsing generator spec from file generator-defaultemptyconflict.yaml: default_empty cannot be combined with the default not empty", err.Error())
+}
+
+func TestObtainGeneratorSpec_ShouldAcceptGeneratorDirWithTrailingSlash(t *testing.T) {
+	docs.Given("a valid generator source directory given with a trailing slash")
+	sourcedir := "../resources/valid-generator-simple/"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, "main")
+
+	docs.Then("the trailing slash is ignored and the spec is returned")
+	require.Nil(t, err)
+	require.NotNil(t, actual)
+	require.Equal(t, 2, len(actual.Templates))
+}
+
+func TestObtainGeneratorSpec_ShouldFailOnGeneratorDirWithTrailingSlashes(t *testing.T) {
 	docs.Given("an invalid generator source directory")
-	sourcedir := "../resources/invalid-generator-specs/"
+	sourcedir := "../resources/invalid-generator-specs//"
 
 	docs.When("ObtainGeneratorSpec is invoked")
 	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, "doesnotmatter")
@@ -152,6 +230,188 @@ func TestObtainGeneratorSpec_ShouldFailOnGeneratorDirWithTrailingSlash(t *testin
 	docs.Then("an appropriate error is returned")
 	require.Equal(t, &api.GeneratorSpec{}, actual)
 	require.NotNil(t, err)
-	expectedErr := "invalid generator directory: baseDir ../resources/invalid-generator-specs/ must not contain trailing slash"
+	expectedErr := "invalid generator directory: baseDir ../resources/invalid-generator-specs// must not contain more than one trailing slash"
 	require.Equal(t, expectedErr, err.Error())
 }
+
+func TestReadGeneratorSpecRaw_ShouldReturnFileContentsAndPath(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.Given("a valid generator name")
+	name := "docker"
+
+	docs.When("ReadGeneratorSpecRaw is invoked")
+	actual, actualPath, err := generatorlib.ReadGeneratorSpecRaw(context.TODO(), sourcedir, name)
+
+	docs.Then("the unparsed file contents and the file path are returned")
+	expected := `templates:
+  - source: 'Dockerfile.tmpl'
+    target: 'Dockerfile'
+variables:
+  serviceName:
+    description: 'The name of the service to be rendered'
+    pattern: '[a-zA-Z]+'
+`
+	require.Nil(t, err)
+	require.Equal(t, expected, toUnix(string(actual)))
+	require.Equal(t, "../resources/valid-generator-simple/generator-docker.yaml", actualPath)
+}
+
+func TestReadGeneratorSpecRaw_ShouldNotParse(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name with a syntactically invalid spec")
+	name := "duplicatekey"
+
+	docs.When("ReadGeneratorSpecRaw is invoked")
+	actual, _, err := generatorlib.ReadGeneratorSpecRaw(context.TODO(), sourcedir, name)
+
+	docs.Then("the file contents are returned without complaint")
+	require.Nil(t, err)
+	require.Contains(t, string(actual), "identical:")
+}
+
+func TestReadGeneratorSpecRaw_ShouldFailOnMissingGeneratorFile(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.When("ReadGeneratorSpecRaw is invoked with an invalid generator name")
+	actual, _, err := generatorlib.ReadGeneratorSpecRaw(context.TODO(), sourcedir, "notthere")
+
+	docs.Then("an appropriate error is returned")
+	require.Empty(t, actual)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "error reading generator spec file generator-notthere.yaml: open ../resources/valid-generator-simple/generator-notthere.yaml: ")
+}
+
+func TestObtainGeneratorSpecWithOptions_ShouldUseCustomPrefixAndExtension(t *testing.T) {
+	docs.Given("a generator spec following a different naming convention")
+	sourcedir := "../resources/valid-generator-structured"
+	options := api.DiscoveryOptions{Prefix: "blueprint.", Extension: ".yml"}
+
+	docs.When("ObtainGeneratorSpecWithOptions is invoked with a matching prefix and extension")
+	actual, err := generatorlib.ObtainGeneratorSpecWithOptions(context.TODO(), sourcedir, "custom", options)
+
+	docs.Then("the spec is read from the correctly named file")
+	require.Nil(t, err)
+	require.Equal(t, "hello custom world", actual.Variables["helloMessage"].DefaultValue)
+}
+
+func TestObtainGeneratorSpecWithOptions_ShouldReportCustomFilename(t *testing.T) {
+	docs.Given("a generator source directory without a generator spec for the given name")
+	sourcedir := "../resources/valid-generator-structured"
+	options := api.DiscoveryOptions{Prefix: "blueprint."}
+
+	docs.When("ObtainGeneratorSpecWithOptions is invoked with a custom prefix only")
+	actual, err := generatorlib.ObtainGeneratorSpecWithOptions(context.TODO(), sourcedir, "custom", options)
+
+	docs.Then("an error mentioning the file name built from the prefix and the default extension is returned")
+	require.Equal(t, &api.GeneratorSpec{}, actual)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "error reading generator spec file blueprint.custom.yaml: ")
+}
+
+func TestObtainGeneratorSpec_ShouldMergeSharedVariables(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a valid generator name for a spec that includes two shared variables files and overrides one variable")
+	name := "shared"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("the shared variables are merged, later files and the generator's own variables taking precedence")
+	require.Nil(t, err)
+	expected := map[string]api.VariableSpec{
+		"copyrightYear": {
+			Description:  "The year to put in copyright notices.",
+			DefaultValue: "2021",
+		},
+		"license": {
+			Description:  "The license of the generated code, this generator prefers Apache.",
+			DefaultValue: "Apache-2.0",
+		},
+		"orgName": {
+			Description:  "The name of the organization, as it appears in copyright notices.",
+			DefaultValue: "Mundo Baton",
+		},
+	}
+	require.Equal(t, expected, actual.Variables)
+}
+
+func TestObtainGeneratorSpec_ShouldFailOnMissingSharedVariablesFile(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a valid generator name for a spec that includes a shared variables file that does not exist")
+	name := "missinginclude"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("an appropriate error is returned")
+	require.Equal(t, &api.GeneratorSpec{}, actual)
+	require.NotNil(t, err)
+	expectedErrPart := "error including shared variables in generator spec from file generator-missinginclude.yaml: error reading shared variables file variables-notthere.yaml: open ../resources/invalid-generator-specs/variables-notthere.yaml: "
+	require.Contains(t, err.Error(), expectedErrPart)
+}
+
+func TestObtainGeneratorSpec_ShouldFailOnUndefinedPatternRef(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a valid generator name for a spec with a variable that references a pattern that is not defined")
+	name := "undefinedpatternref"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("an appropriate error is returned")
+	require.Equal(t, &api.GeneratorSpec{}, actual)
+	require.NotNil(t, err)
+	require.Equal(t, "error resolving pattern references in generator spec from file generator-undefinedpatternref.yaml: variable hostName references undefined pattern 'dnsLabel', defined patterns are: [dnsName]", err.Error())
+}
+
+func TestObtainGeneratorSpec_ShouldFailOnNewerSpecVersion(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a valid generator name for a spec that requires a newer version of the library, and uses a field it does not know")
+	name := "futureversion"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("an error asking to upgrade is returned, rather than one about the unknown field")
+	require.Equal(t, &api.GeneratorSpec{}, actual)
+	require.NotNil(t, err)
+	require.Equal(t, "error parsing generator spec from file generator-futureversion.yaml: generator requires spec version 999999, but this version of the library supports up to version 1, please upgrade", err.Error())
+}
+
+func TestObtainGeneratorSpec_ShouldUseStringKeysInNestedMaps(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("a valid generator name for a spec with a nested map default, including a map with a non-string key")
+	name := "nestedmap"
+
+	docs.When("ObtainGeneratorSpec is invoked")
+	actual, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("all maps have string keys, so the default can be encoded as json")
+	require.Nil(t, err)
+	expected := map[string]interface{}{
+		"host": "localhost",
+		"credentials": map[string]interface{}{
+			"user":  "admin",
+			"roles": []interface{}{"read", map[string]interface{}{"1": "write"}},
+		},
+	}
+	require.Equal(t, expected, actual.Variables["database"].DefaultValue)
+	encoded, err := json.Marshal(actual.Variables["database"].DefaultValue)
+	require.Nil(t, err)
+	require.Equal(t, `{"credentials":{"roles":["read",{"1":"write"}],"user":"admin"},"host":"localhost"}`, string(encoded))
+}
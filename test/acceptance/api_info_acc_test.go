@@ -3,6 +3,7 @@ package acceptance
 import (
 	"context"
 	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/docs"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -16,7 +17,7 @@ func TestFindGeneratorNames_ShouldReturnCorrectList(t *testing.T) {
 	actual, err := generatorlib.FindGeneratorNames(context.TODO(), sourcedir)
 
 	docs.Then("the list of available generators is returned")
-	expected := []string{"docker", "emptydefaults", "items", "justcopy", "main", "templatevars"}
+	expected := []string{"docker", "emptydefaults", "entries", "items", "justcopy", "main", "templatevars"}
 	require.Nil(t, err)
 	require.Equal(t, expected, actual)
 }
@@ -48,3 +49,46 @@ func TestFindGeneratorNames_ShouldComplainNotDirectory(t *testing.T) {
 	expectedErrorMsg := "invalid generator directory: baseDir ../resources/valid-generator-simple/generator-docker.yaml must be a directory"
 	require.Equal(t, expectedErrorMsg, err.Error())
 }
+
+func TestFindGeneratorNamesWithOptions_ShouldUseCustomPrefixAndExtension(t *testing.T) {
+	docs.Given("a valid generator source directory that also contains a generator spec with a different naming convention")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.When("FindGeneratorNamesWithOptions is invoked with a custom prefix and extension")
+	actual, err := generatorlib.FindGeneratorNamesWithOptions(context.TODO(), sourcedir, api.DiscoveryOptions{Prefix: "blueprint.", Extension: ".yml"})
+
+	docs.Then("only the generators following that naming convention are returned")
+	require.Nil(t, err)
+	require.Equal(t, []string{"custom"}, actual)
+}
+
+func TestFindGeneratorNamesWithOptions_ShouldDefaultToStandardNaming(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.When("FindGeneratorNamesWithOptions is invoked with empty options")
+	actual, err := generatorlib.FindGeneratorNamesWithOptions(context.TODO(), sourcedir, api.DiscoveryOptions{})
+
+	docs.Then("the same list as for FindGeneratorNames is returned")
+	expected := []string{"docker", "emptydefaults", "entries", "items", "justcopy", "main", "templatevars"}
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestFindGeneratorNames_ShouldHonourGenignore(t *testing.T) {
+	docs.Given("a valid generator source directory with a .genignore file that excludes draft generator specs")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.When("FindGeneratorNames is invoked")
+	actual, err := generatorlib.FindGeneratorNames(context.TODO(), sourcedir)
+
+	docs.Then("the excluded generator is not listed")
+	require.Nil(t, err)
+	require.NotEmpty(t, actual)
+	require.NotContains(t, actual, "wip-draft")
+
+	docs.Then("but it can still be obtained by name")
+	spec, err := generatorlib.ObtainGeneratorSpec(context.TODO(), sourcedir, "wip-draft")
+	require.Nil(t, err)
+	require.Equal(t, "draft.txt", spec.Templates[0].RelativeTargetPath)
+}
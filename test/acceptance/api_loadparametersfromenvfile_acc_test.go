@@ -0,0 +1,66 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoadParametersFromEnvFile_ShouldParseQuotesAndComments(t *testing.T) {
+	docs.Given("an env file with comments, quoted values and a repeated key")
+	path := "../resources/env-files/valid.env"
+
+	docs.When("LoadParametersFromEnvFile is invoked")
+	actual, err := generatorlib.LoadParametersFromEnvFile(context.TODO(), path)
+
+	docs.Then("all values are read as strings, comments are dropped and the last value wins")
+	require.Nil(t, err)
+	expected := map[string]interface{}{
+		"serviceName":  "overridden",
+		"replicas":     "3",
+		"helloMessage": "Hello, \"World\"\nWelcome",
+		"singleQuoted": "no # comment or \\n escape here",
+		"unquoted":     "some value",
+		"hashInValue":  "a#b",
+		"empty":        "",
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestLoadParametersFromEnvFile_ShouldComplainUnterminatedQuote(t *testing.T) {
+	docs.Given("an env file with a double quoted value that is never closed")
+	path := "../resources/env-files/unterminated.env"
+
+	docs.When("LoadParametersFromEnvFile is invoked")
+	actual, err := generatorlib.LoadParametersFromEnvFile(context.TODO(), path)
+
+	docs.Then("the offending line is reported")
+	require.Nil(t, actual)
+	require.NotNil(t, err)
+	require.Equal(t, "invalid line 2 in env file ../resources/env-files/unterminated.env: missing closing quote \"", err.Error())
+}
+
+func TestLoadParametersFromEnvFile_ShouldComplainLineWithoutEquals(t *testing.T) {
+	docs.Given("an env file with a line that is not of the form KEY=value")
+	path := "../resources/env-files/noequals.env"
+
+	docs.When("LoadParametersFromEnvFile is invoked")
+	actual, err := generatorlib.LoadParametersFromEnvFile(context.TODO(), path)
+
+	docs.Then("the offending line is reported")
+	require.Nil(t, actual)
+	require.NotNil(t, err)
+	require.Equal(t, "invalid line 2 in env file ../resources/env-files/noequals.env: must be of the form KEY=value", err.Error())
+}
+
+func TestLoadParametersFromEnvFile_ShouldComplainMissingFile(t *testing.T) {
+	docs.When("LoadParametersFromEnvFile is invoked for a file that does not exist")
+	actual, err := generatorlib.LoadParametersFromEnvFile(context.TODO(), "../resources/env-files/notthere.env")
+
+	docs.Then("the read error is reported")
+	require.Nil(t, actual)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "error reading env file ../resources/env-files/notthere.env: ")
+}
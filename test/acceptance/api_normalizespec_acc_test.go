@@ -0,0 +1,106 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestNormalizeRenderSpec_ShouldTidyUpSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/normalize-render-spec-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a hand edited render spec with an extraneous key, odd ordering and a missing parameter")
+	originalContent := `parameters:
+    serviceName:   'my-service'
+    noLongerUsed: true
+    helloMessage: "custom message"
+generator: main
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte(originalContent)))
+
+	docs.When("NormalizeRenderSpec is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.NormalizeRenderSpec(context.TODO(), request, "main")
+
+	docs.Then("the render spec is rewritten canonically, with the default filled in and the extraneous key dropped with a warning")
+	expectedContent := `version: 1
+generator: main
+parameters:
+  helloMessage: custom message
+  serviceName: my-service
+  serviceUrl: github.com/mundobaton/temp
+`
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "generated-main.yaml",
+			},
+		},
+		Warnings: []string{"parameter 'noLongerUsed' is not allowed according to generator spec, dropping it"},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	actual, err := dir.ReadFile(context.TODO(), "generated-main.yaml")
+	require.Nil(t, err)
+	require.Equal(t, expectedContent, string(actual))
+}
+
+func TestNormalizeRenderSpec_ShouldComplainWrongGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/normalize-render-spec-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec for generator docker")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "spec.yaml", []byte("generator: docker\n")))
+
+	docs.When("NormalizeRenderSpec is invoked for generator main")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "spec.yaml",
+	}
+	actualResponse := generatorlib.NormalizeRenderSpec(context.TODO(), request, "main")
+
+	docs.Then("an appropriate error is returned and the file is left alone")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, "render spec file spec.yaml is for generator 'docker', not 'main'", actualResponse.Errors[0].Error())
+	actual, err := dir.ReadFile(context.TODO(), "spec.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "generator: docker\n", string(actual))
+}
+
+func TestNormalizeRenderSpec_ShouldComplainMissingSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory without a render spec")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/normalize-render-spec-3"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("NormalizeRenderSpec is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.NormalizeRenderSpec(context.TODO(), request, "docker")
+
+	docs.Then("an appropriate error is returned")
+	require.False(t, actualResponse.Success)
+	require.Contains(t, actualResponse.Errors[0].Error(), "error reading render spec file generated-docker.yaml in target directory ../output/normalize-render-spec-3: ")
+}
@@ -0,0 +1,79 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseParameterArgs_ShouldParseStrings(t *testing.T) {
+	docs.Given("some key=value arguments, one of them containing another equals sign")
+	args := []string{"serviceName=my-service", "helloMessage=a=b", "empty="}
+
+	docs.When("ParseParameterArgs is invoked")
+	actual, err := generatorlib.ParseParameterArgs(context.TODO(), args)
+
+	docs.Then("all values are strings")
+	require.Nil(t, err)
+	expected := map[string]interface{}{
+		"serviceName":  "my-service",
+		"helloMessage": "a=b",
+		"empty":        "",
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestParseParameterArgs_ShouldParseJsonValues(t *testing.T) {
+	docs.Given("some key:=jsonvalue arguments")
+	args := []string{"replicas:=3", "enabled:=true", "name:=\"quoted\"", "ports:=[80,443]", `db:={"host":"localhost","port":5432}`}
+
+	docs.When("ParseParameterArgs is invoked")
+	actual, err := generatorlib.ParseParameterArgs(context.TODO(), args)
+
+	docs.Then("the values have the types given by their json representation")
+	require.Nil(t, err)
+	expected := map[string]interface{}{
+		"replicas": float64(3),
+		"enabled":  true,
+		"name":     "quoted",
+		"ports":    []interface{}{float64(80), float64(443)},
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": float64(5432),
+		},
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestParseParameterArgs_ShouldLetLastValueWin(t *testing.T) {
+	docs.Given("an argument list that sets the same key twice")
+	args := []string{"serviceName=first", "serviceName=second"}
+
+	docs.When("ParseParameterArgs is invoked")
+	actual, err := generatorlib.ParseParameterArgs(context.TODO(), args)
+
+	docs.Then("the last value is used")
+	require.Nil(t, err)
+	require.Equal(t, map[string]interface{}{"serviceName": "second"}, actual)
+}
+
+func TestParseParameterArgs_ShouldComplainAboutInvalidArgs(t *testing.T) {
+	docs.Given("some invalid arguments")
+	cases := map[string]string{
+		"serviceName":     "invalid parameter argument 'serviceName': must be of the form key=value or key:=jsonvalue",
+		"=value":          "invalid parameter argument '=value': key must not be empty",
+		":=3":             "invalid parameter argument ':=3': key must not be empty",
+		"replicas:=three": "invalid parameter argument 'replicas:=three': value is not valid json: invalid character 'h' in literal true (expecting 'r')",
+	}
+	for arg, expectedMessage := range cases {
+		docs.When("ParseParameterArgs is invoked")
+		actual, err := generatorlib.ParseParameterArgs(context.TODO(), []string{arg})
+
+		docs.Then("the correct error is returned")
+		require.Nil(t, actual)
+		require.NotNil(t, err)
+		require.Equal(t, expectedMessage, err.Error())
+	}
+}
@@ -0,0 +1,156 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPlanRender_ShouldListTargetsWithoutWriting(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/plan-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator using with_items and a condition")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-items.yaml", []byte("generator: items\n")))
+
+	docs.When("PlanRender is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-items.yaml",
+	}
+	actualResponse := generatorlib.PlanRender(context.TODO(), request)
+
+	docs.Then("the files that would be rendered are listed, leaving out those whose condition is false")
+	expectedResponse := &api.PlanResponse{
+		Success: true,
+		PlannedFiles: []api.PlannedFile{
+			{RelativeSourcePath: "item.txt.tmpl", RelativeTargetPath: "first.txt"},
+			{RelativeSourcePath: "item.txt.tmpl", RelativeTargetPath: "second.txt"},
+			{RelativeSourcePath: "item.txt.tmpl", RelativeTargetPath: "third.txt"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+
+	docs.Then("no files are written")
+	files, err := ioutil.ReadDir(targetdirpath)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(files))
+}
+
+func TestPlanRender_ShouldReportSkippedTargets(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory containing a marker file")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/plan-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), ".initialized", []byte{}))
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-skipifexists.yaml", []byte("generator: skipifexists\n")))
+
+	docs.When("PlanRender is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-skipifexists.yaml",
+	}
+	actualResponse := generatorlib.PlanRender(context.TODO(), request)
+
+	docs.Then("the file that would be skipped is marked as such")
+	expectedResponse := &api.PlanResponse{
+		Success: true,
+		PlannedFiles: []api.PlannedFile{
+			{RelativeSourcePath: "service.txt.tmpl", RelativeTargetPath: "config/service.txt", Skipped: true},
+			{RelativeSourcePath: "service.txt.tmpl", RelativeTargetPath: "always.txt"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestPlanRender_ShouldComplainMissingRenderSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory without a render spec file")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/plan-3"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("PlanRender is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.PlanRender(context.TODO(), request)
+
+	docs.Then("an appropriate error is returned")
+	require.False(t, actualResponse.Success)
+	require.Empty(t, actualResponse.PlannedFiles)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Contains(t, actualResponse.Errors[0].Error(), "generated-main.yaml")
+}
+
+func TestPlanRenderWithValues_ShouldListResolvedTargets(t *testing.T) {
+	docs.Given("a valid generator source directory and an empty target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/plan-4"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("PlanRenderWithValues is invoked with parameters for a generator whose target paths use them")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.PlanRenderWithValues(context.TODO(), request, "planvalues", map[string]interface{}{
+		"serviceName": "billing",
+		"withDocs":    true,
+	})
+
+	docs.Then("the concrete target paths for these parameters are listed, including with_items expansion")
+	expectedResponse := &api.PlanResponse{
+		Success: true,
+		PlannedFiles: []api.PlannedFile{
+			{RelativeSourcePath: "main.txt.tmpl", RelativeTargetPath: "billing/main.go"},
+			{RelativeSourcePath: "main.txt.tmpl", RelativeTargetPath: "billing/config.go"},
+			{RelativeSourcePath: "main.txt.tmpl", RelativeTargetPath: "billing/docs.md"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+
+	docs.Then("no files are written")
+	files, err := ioutil.ReadDir(targetdirpath)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(files))
+}
+
+func TestPlanRenderWithValues_ShouldValidateParameters(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+
+	docs.When("PlanRenderWithValues is invoked with an invalid and an unknown parameter")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: "../output/plan-4",
+	}
+	invalidResponse := generatorlib.PlanRenderWithValues(context.TODO(), request, "planvalues", map[string]interface{}{
+		"serviceName": "Not Valid",
+	})
+	unknownResponse := generatorlib.PlanRenderWithValues(context.TODO(), request, "planvalues", map[string]interface{}{
+		"serviceName": "billing",
+		"unknown":     "value",
+	})
+
+	docs.Then("the parameters are rejected like for RenderWithValues")
+	require.Equal(t, &api.PlanResponse{Errors: []error{errors.New("value for parameter 'serviceName' does not match pattern ^[a-z-]+$")}}, invalidResponse)
+	require.Equal(t, &api.PlanResponse{Errors: []error{errors.New("parameter 'unknown' is not allowed according to generator spec")}}, unknownResponse)
+}
@@ -9,9 +9,15 @@ import (
 	"github.com/mundobaton/go-generator-lib/docs"
 	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"text/template"
+	"time"
 )
 
 func TestRender_ShouldWriteExpectedFilesForDefault(t *testing.T) {
@@ -231,6 +237,36 @@ something: weird
 	require.Equal(t, expectedErrorMsg, actualResponse.Errors[0].Error())
 }
 
+func TestRender_ShouldComplainIfRenderSpecVersionUnsupported(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-32"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file written by a future version of the library")
+	renderspec := `version: 99
+generator: main
+parameters:
+  serviceName: temp-service
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("an appropriate error is returned")
+	require.False(t, actualResponse.Success)
+	require.Empty(t, actualResponse.RenderedFiles)
+	expectedErrorMsg := "error parsing render spec file generated-main.yaml in target directory ../output/render-32: unsupported format version 99, this version of the library supports up to version 1, please upgrade"
+	require.Equal(t, expectedErrorMsg, actualResponse.Errors[0].Error())
+}
+
 func TestRender_ShouldComplainIfGenSpecNotFound(t *testing.T) {
 	docs.Given("a valid generator source directory and a valid target directory")
 	sourcedirpath := "../resources/valid-generator-simple"
@@ -742,3 +778,2299 @@ parameters:
 `
 	_testRender_emptyDefaultsErrorTestCase(t, 18, renderspec, "parameter 'missingDefault' is required but missing")
 }
+
+func TestRender_ShouldWriteExpectedFilesForEntries(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-19"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator entries, which uses with_entries on a map with three rendered entries")
+	renderspec := `generator: entries
+parameters: {}
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-entries.yaml", []byte(renderspec)))
+
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-entries.yaml",
+	}
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "upstreams/accounts.conf",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "upstreams/billing.conf",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "upstreams/payments.conf",
+			},
+		},
+	}
+
+	for run := 1; run <= 5; run++ {
+		docs.When("Render is invoked (repeatedly)")
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("the files are rendered in sorted key order every time")
+		require.Equal(t, expectedResponse, actualResponse)
+	}
+
+	docs.Then("the files have the expected content")
+	actual, err := dir.ReadFile(context.TODO(), "upstreams/billing.conf")
+	require.Nil(t, err)
+	expectedContent := `upstream svc-billing {
+    server billing.internal:9000;
+}
+`
+	require.Equal(t, expectedContent, toUnix(string(actual)))
+	_, err = dir.ReadFile(context.TODO(), "upstreams/skipped.conf")
+	require.NotNil(t, err)
+}
+
+func TestRender_ShouldReturnItemizedFilesInStableOrder(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-20"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator items, which uses with_items")
+	renderspec := `generator: items
+parameters: {}
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-items.yaml", []byte(renderspec)))
+
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-items.yaml",
+	}
+
+	docs.When("Render is invoked multiple times")
+	firstResponse := generatorlib.Render(context.TODO(), request)
+	for run := 2; run <= 10; run++ {
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("the rendered files are always reported in item order")
+		require.Equal(t, firstResponse, actualResponse)
+	}
+	require.Equal(t, 3, len(firstResponse.RenderedFiles))
+	require.Equal(t, "first.txt", firstResponse.RenderedFiles[0].RelativeFilePath)
+	require.Equal(t, "second.txt", firstResponse.RenderedFiles[1].RelativeFilePath)
+	require.Equal(t, "third.txt", firstResponse.RenderedFiles[2].RelativeFilePath)
+}
+
+func TestRender_ShouldConvertTemplateFunctionPanicsToFileErrors(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-panics"
+	targetdirpath := "../output/render-21"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator main")
+	renderspec := `generator: main
+parameters: {}
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte(renderspec)))
+
+	docs.Given("the generator uses a sprig function that panics, both in a template and a target path")
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the panics are reported as file errors and the other files are still rendered")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 3, len(actualResponse.RenderedFiles))
+	require.False(t, actualResponse.RenderedFiles[0].Success)
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(), "error evaluating template for target 'panic.txt': ")
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(), "Cannot find first on type string")
+	require.True(t, actualResponse.RenderedFiles[1].Success)
+	require.False(t, actualResponse.RenderedFiles[2].Success)
+	require.Contains(t, actualResponse.RenderedFiles[2].Errors[0].Error(), "error evaluating target path from '{{ last .message }}.txt': ")
+	require.Contains(t, actualResponse.RenderedFiles[2].Errors[0].Error(), "Cannot find last on type string")
+
+	actual, err := dir.ReadFile(context.TODO(), "ok.txt")
+	require.Nil(t, err)
+	require.Equal(t, "hello\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldNotRenderExcludedTemplates(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-22"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator excludes, which excludes some of its template sources")
+	renderspec := `generator: excludes
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-excludes.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-excludes.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the excluded templates never appear in the results")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "main.txt",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestRender_ShouldNotRenderBundlesWithExcludedFragments(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-94"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator bundleexcludes, which excludes one fragment of a bundled template")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-bundleexcludes.yaml", []byte("generator: bundleexcludes\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-bundleexcludes.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the bundled template is not rendered")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "main.txt",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	require.False(t, dir.Exists(context.TODO(), "migrations.sql"))
+}
+
+func TestRender_ShouldAcceptDeprecatedAlias(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-23"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("an old render spec file for generator aliases that still uses the old variable name")
+	renderspec := `generator: aliases
+parameters:
+  svcName: old-service
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-aliases.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-aliases.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the value given under the alias is used for the renamed variable, with a deprecation warning")
+	require.True(t, actualResponse.Success)
+	require.Empty(t, actualResponse.Errors)
+	require.Equal(t, []string{"parameter 'svcName' is a deprecated alias for 'serviceName', please rename it"}, actualResponse.Warnings)
+	actual, err := dir.ReadFile(context.TODO(), "service.txt")
+	require.Nil(t, err)
+	require.Equal(t, "service: old-service\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldPreferActualNameOverAlias(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-24"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for generator aliases that sets both the variable and an alias")
+	renderspec := `generator: aliases
+parameters:
+  service: Invalid Old Value
+  serviceName: new-service
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-aliases.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-aliases.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the value under the actual variable name is used, with a warning about the ignored alias")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, []string{"parameter 'service' is a deprecated alias for 'serviceName', ignoring it because 'serviceName' is also set"}, actualResponse.Warnings)
+	actual, err := dir.ReadFile(context.TODO(), "service.txt")
+	require.Nil(t, err)
+	require.Equal(t, "service: new-service\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldApplyFrontMatter(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-25"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator frontmatter, whose templates specify target and condition in their front matter")
+	renderspec := `generator: frontmatter
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-frontmatter.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-frontmatter.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the front matter determines target and condition, and is removed from the output")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "greetings/hello-world.txt",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "plain.yaml",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	actual1, err := dir.ReadFile(context.TODO(), "greetings/hello-world.txt")
+	require.Nil(t, err)
+	require.Equal(t, "Greeting: hello world\n", toUnix(string(actual1)))
+
+	docs.Then("templates that do not enable front matter are rendered unchanged, even if they start with ---")
+	actual2, err := dir.ReadFile(context.TODO(), "plain.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "---\nmessage: 'hello world'\n", toUnix(string(actual2)))
+}
+
+func TestRender_ShouldFailOnEmptyOutputIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-26"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator failonempty, whose template renders to whitespace only")
+	renderspec := `generator: failonempty
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-failonempty.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-failonempty.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the empty output is only reported as an error where fail_on_empty is set, and that file is not written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 3, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Equal(t, "error evaluating template for target 'empty-forbidden.txt': rendered output is empty, but fail_on_empty is set", actualResponse.RenderedFiles[1].Errors[0].Error())
+	require.True(t, actualResponse.RenderedFiles[2].Success)
+	_, err := dir.ReadFile(context.TODO(), "empty-allowed.txt")
+	require.Nil(t, err)
+	_, err = dir.ReadFile(context.TODO(), "empty-forbidden.txt")
+	require.NotNil(t, err)
+}
+
+func _testRender_skipIfTargetExistsTestCase(t *testing.T, testcase uint, markerPresent bool) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator skipifexists")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-skipifexists.yaml", []byte("generator: skipifexists\n")))
+	if markerPresent {
+		docs.Given("the marker file exists in the target directory")
+		require.Nil(t, dir.WriteFile(context.TODO(), ".initialized", []byte{}))
+	}
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-skipifexists.yaml",
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldSkipIfTargetExists(t *testing.T) {
+	actualResponse, dir := _testRender_skipIfTargetExistsTestCase(t, 27, true)
+
+	docs.Then("the template is skipped and reported as skipped")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				Skipped:          true,
+				SkipReason:       api.SkipReasonTargetExists,
+				RelativeFilePath: "config/service.txt",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "always.txt",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	_, err := dir.ReadFile(context.TODO(), "config/service.txt")
+	require.NotNil(t, err)
+}
+
+func TestRender_ShouldNotSkipIfTargetMissing(t *testing.T) {
+	actualResponse, dir := _testRender_skipIfTargetExistsTestCase(t, 28, false)
+
+	docs.Then("the template is rendered")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "config/service.txt",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "always.txt",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	_, err := dir.ReadFile(context.TODO(), "config/service.txt")
+	require.Nil(t, err)
+}
+
+func TestRender_ShouldNotAllowSkipIfTargetExistsOutsideTargetDirectory(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-101"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator skipifexists, whose marker file is above the target directory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-skipifexists.yaml", []byte("generator: skipifexists\nparameters:\n  markerFile: ../render-101.marker\n")))
+	require.Nil(t, ioutil.WriteFile("../output/render-101.marker", []byte{}, 0644))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-skipifexists.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the template fails instead of being skipped because of a file outside the target directory")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.False(t, actualResponse.RenderedFiles[0].Skipped)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles[0].Errors))
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(), "cannot check ../render-101.marker, it is outside the target directory")
+	require.False(t, dir.Exists(context.TODO(), "config/service.txt"))
+}
+
+func TestRender_ShouldHandleBOM(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-29"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator bom, whose template starts with a byte order mark")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-bom.yaml", []byte("generator: bom\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-bom.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the byte order mark is stripped from the output, unless write_bom is set")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "nobom.txt")
+	require.Nil(t, err)
+	require.Equal(t, "service: some-service\n", toUnix(string(actual)))
+	actual, err = dir.ReadFile(context.TODO(), "withbom.txt")
+	require.Nil(t, err)
+	require.Equal(t, "\xEF\xBB\xBFservice: some-service\n", toUnix(string(actual)))
+}
+
+func _testRender_slowTestCase(t *testing.T, testcase uint, ctx context.Context, timeout time.Duration) *api.Response {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator slow, which has a template that takes very long to render")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-slow.yaml", []byte("generator: slow\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-slow.yaml",
+		RenderTimeout:  timeout,
+	}
+	return generatorlib.Render(ctx, request)
+}
+
+func TestRender_ShouldAbortSlowTemplateAfterTimeout(t *testing.T) {
+	actualResponse := _testRender_slowTestCase(t, 30, context.TODO(), 50*time.Millisecond)
+
+	docs.Then("the slow file is reported as an error, while the other file is rendered")
+	expectedResponse := &api.Response{
+		Success: false,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "service.txt",
+			},
+			{
+				Success:          false,
+				RelativeFilePath: "slow.txt",
+				Errors:           []error{errors.New("error evaluating template for target 'slow.txt': aborted executing template slow.txt.tmpl: context deadline exceeded")},
+			},
+		},
+		Errors: []error{errors.New("an error occurred during rendering, see individual files")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestRender_ShouldAbortSlowTemplateWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+	defer cancel()
+	actualResponse := _testRender_slowTestCase(t, 31, ctx, 0)
+
+	docs.Then("the slow file is reported as an error")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.Equal(t, []error{errors.New("error evaluating template for target 'slow.txt': aborted executing template slow.txt.tmpl: context deadline exceeded")}, actualResponse.RenderedFiles[1].Errors)
+}
+
+func TestRender_ShouldAbortSlowTargetPathAfterTimeout(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-96"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator slowpath, which has a target path that takes very long to evaluate")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-slowpath.yaml", []byte("generator: slowpath\n")))
+
+	docs.When("Render is invoked with a render timeout")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-slowpath.yaml",
+		RenderTimeout:  50 * time.Millisecond,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the file is reported as an error, because evaluating its target path counts towards the timeout")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	slowPath := "{{ range until 100000 }}{{ range until 100000 }}{{ end }}{{ end }}slow.txt"
+	require.Equal(t, []error{fmt.Errorf("error evaluating target path from '%s': aborted executing template inline template for target %s_path: context deadline exceeded", slowPath, slowPath)}, actualResponse.RenderedFiles[0].Errors)
+}
+
+func _testRender_nullDefaultsTestCase(t *testing.T, testcase uint, renderspec string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for generator nulldefaults")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-nulldefaults.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-nulldefaults.yaml",
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldUseEmptyStringForEmptyDefaults(t *testing.T) {
+	actualResponse, dir := _testRender_nullDefaultsTestCase(t, 33, `generator: nulldefaults
+parameters:
+  nullDefault: also given
+  missingDefault: given
+`)
+
+	docs.Then("the variable with default_empty is the empty string")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "nulldefaults.txt")
+	require.Nil(t, err)
+	require.Equal(t, "empty: []\nnull: [also given]\nmissing: [given]\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldRequireVariablesWithoutDefault(t *testing.T) {
+	actualResponse, _ := _testRender_nullDefaultsTestCase(t, 34, `generator: nulldefaults
+`)
+
+	docs.Then("the first variable without a default is reported as missing")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("parameter 'missingDefault' is required but missing")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func _testRender_buildContextTestCase(t *testing.T, testcase uint, generatorName string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator whose template uses the build context")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-build.yaml", []byte("generator: "+generatorName+"\n")))
+
+	docs.When("Render is invoked with the build context included and a fixed timestamp")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		RenderSpecFile:      "generated-build.yaml",
+		IncludeBuildContext: true,
+		BuildTimestamp:      time.Date(2020, 2, 29, 13, 14, 15, 0, time.UTC),
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldProvideBuildContext(t *testing.T) {
+	actualResponse, dir := _testRender_buildContextTestCase(t, 35, "buildcontext")
+
+	docs.Then("the template can use the build context")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "build.txt")
+	require.Nil(t, err)
+	expected := fmt.Sprintf("built at 2020-02-29T13:14:15Z on %s/%s with %s\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	require.Equal(t, expected, toUnix(string(actual)))
+}
+
+func TestRender_ShouldComplainIfBuildContextClashesWithVariable(t *testing.T) {
+	actualResponse, _ := _testRender_buildContextTestCase(t, 36, "buildreserved")
+
+	docs.Then("an appropriate error is returned")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("variable name 'build' is reserved for the build context, cannot include it")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func _testRender_reproducibleTestCase(t *testing.T, testcase uint, withTimestamp string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for generator reproducible")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	renderspec := "generator: reproducible\nparameters:\n  withTimestamp: '" + withTimestamp + "'\n"
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-reproducible.yaml", []byte(renderspec)))
+
+	docs.Given("SOURCE_DATE_EPOCH is set")
+	require.Nil(t, os.Setenv("SOURCE_DATE_EPOCH", "1583000000"))
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+
+	docs.When("Render is invoked in reproducible build mode")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		RenderSpecFile:      "generated-reproducible.yaml",
+		IncludeBuildContext: true,
+		ReproducibleBuild:   true,
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldRenderReproducibly(t *testing.T) {
+	actualResponse, dir := _testRender_reproducibleTestCase(t, 37, "false")
+
+	docs.Then("the timestamp is taken from SOURCE_DATE_EPOCH and map keys and values are sorted")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "reproducible.txt")
+	require.Nil(t, err)
+	require.Equal(t, "built at 2020-02-29T18:13:20Z\nkeys: dns,http,https,ssh\nvalues: 53,80,443,22\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldRejectNondeterministicFunctionsInReproducibleMode(t *testing.T) {
+	actualResponse, _ := _testRender_reproducibleTestCase(t, 38, "true")
+
+	docs.Then("the file that uses the current time is reported as an error")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Contains(t, actualResponse.RenderedFiles[1].Errors[0].Error(), "function now is not available in reproducible build mode")
+}
+
+func TestRender_ShouldTransformValues(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-39"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for generator transform, whose variable declares a lowercasing transform")
+	renderspec := `generator: transform
+parameters:
+  serviceName: My Cool Service
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-transform.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-transform.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the value is validated as given, but templates see the transformed value")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "service.txt")
+	require.Nil(t, err)
+	require.Equal(t, "service: my-cool-service\n", toUnix(string(actual)))
+}
+
+func _testRender_composeTestCase(t *testing.T, testcase uint, generatorName string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator whose template renders other source files")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-"+generatorName+".yaml", []byte("generator: "+generatorName+"\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-" + generatorName + ".yaml",
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldComposeRenderedFragments(t *testing.T) {
+	actualResponse, dir := _testRender_composeTestCase(t, 40, "compose")
+
+	docs.Then("the fragments are rendered with the data they are given and included in the output")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "compose.txt")
+	require.Nil(t, err)
+	require.Equal(t, "# SOME-SERVICE\nbody of some-service\n(c) 2020\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldComplainAboutRecursiveRender(t *testing.T) {
+	actualResponse, dir := _testRender_composeTestCase(t, 41, "composecycle")
+
+	docs.Then("an appropriate error is returned for the file and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(),
+		"recursive render of fragments/cycle-a.txt.tmpl: fragments/cycle-a.txt.tmpl -> fragments/cycle-b.txt.tmpl -> fragments/cycle-a.txt.tmpl")
+	require.False(t, dir.Exists(context.TODO(), "cycle.txt"))
+}
+
+func TestRender_ShouldComplainAboutRenderOfExcludedSourceFile(t *testing.T) {
+	actualResponse, dir := _testRender_composeTestCase(t, 97, "composeexcluded")
+
+	docs.Then("an appropriate error is returned for the file and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(),
+		"cannot render fragments/header.txt.tmpl, it is excluded by the generator spec")
+	require.False(t, dir.Exists(context.TODO(), "composeexcluded.txt"))
+}
+
+func TestRender_ShouldStopRenderedFragmentsAfterTimeout(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-98"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator whose template renders a fragment that takes very long")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-composeslow.yaml", []byte("generator: composeslow\n")))
+
+	docs.Given("a context that carries a template function which counts how often the fragment calls it")
+	var calls int64
+	ctx := generatorlib.WithExtraFuncs(context.TODO(), template.FuncMap{
+		"count": func() string {
+			atomic.AddInt64(&calls, 1)
+			return ""
+		},
+	})
+
+	docs.When("Render is invoked with a render timeout")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-composeslow.yaml",
+		RenderTimeout:  50 * time.Millisecond,
+	}
+	actualResponse := generatorlib.Render(ctx, request)
+
+	docs.Then("the file is reported as an error")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(), "context deadline exceeded")
+
+	docs.Then("the fragment stops running, too")
+	time.Sleep(20 * time.Millisecond)
+	stopped := atomic.LoadInt64(&calls)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, stopped, atomic.LoadInt64(&calls))
+}
+
+func TestRender_ShouldProvideExtraFuncsFromContext(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-42"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator that uses a custom template function")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-extrafuncs.yaml", []byte("generator: extrafuncs\n")))
+
+	docs.Given("a context that carries the custom template function")
+	ctx := generatorlib.WithExtraFuncs(context.TODO(), template.FuncMap{
+		"shout": func(s string) string { return strings.ToUpper(s) + "!" },
+	})
+
+	docs.When("Render is invoked with that context")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-extrafuncs.yaml",
+	}
+	actualResponse := generatorlib.Render(ctx, request)
+
+	docs.Then("the custom function is available in target paths and templates, alongside the sprig functions")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "SOME-SERVICE!.txt")
+	require.Nil(t, err)
+	require.Equal(t, "SOME-SERVICE! says HELLO\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldOnlyRenderTemplatesWithRequestedTags(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-43"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator with tagged templates")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-tags.yaml", []byte("generator: tags\n")))
+
+	docs.When("Render is invoked for a single tag")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-tags.yaml",
+		RenderTags:     []string{"ci"},
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("only the templates with that tag are rendered")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{Success: true, RelativeFilePath: "ci/pipeline.txt"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	require.True(t, dir.Exists(context.TODO(), "ci/pipeline.txt"))
+	require.False(t, dir.Exists(context.TODO(), "service.txt"))
+	require.False(t, dir.Exists(context.TODO(), "untagged.txt"))
+}
+
+func TestRender_ShouldRenderAllTemplatesWithoutRequestedTags(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-44"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator with tagged templates")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-tags.yaml", []byte("generator: tags\n")))
+
+	docs.When("Render is invoked without any tags")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-tags.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("all templates are rendered, tagged or not")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, 3, len(actualResponse.RenderedFiles))
+}
+
+const nestedRenderSpecHead = `generator: nested
+parameters:
+  database:
+    url: postgres://localhost/db
+    credentials:
+      user: admin
+`
+
+func _testRender_nestedTestCase(t *testing.T, testcase uint, renderspec string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator with nested validation of structured variables")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-nested.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-nested.yaml",
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldAcceptValidNestedValues(t *testing.T) {
+	renderspec := nestedRenderSpecHead + `  upstreams:
+    - host: one.example.com
+      port: 8080
+    - host: two.example.com
+      port: 8081
+  hostnames:
+    - example.com
+`
+	actualResponse, dir := _testRender_nestedTestCase(t, 45, renderspec)
+
+	docs.Then("the values are rendered")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "nested.txt")
+	require.Nil(t, err)
+	expected := "upstream one.example.com:8080\nupstream two.example.com:8081\nhost example.com\ndatabase postgres://localhost/db as admin\n"
+	require.Equal(t, expected, toUnix(string(actual)))
+}
+
+func TestRender_ShouldComplainMissingKeyInListElement(t *testing.T) {
+	renderspec := nestedRenderSpecHead + `  upstreams:
+    - host: one.example.com
+      port: 8080
+    - host: two.example.com
+      port: 8081
+    - port: 8082
+`
+	actualResponse, _ := _testRender_nestedTestCase(t, 46, renderspec)
+
+	docs.Then("an error with the path of the missing value is returned")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("parameter 'upstreams[2].host' is required but missing")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestRender_ShouldComplainListElementNotMatchingItemPattern(t *testing.T) {
+	renderspec := nestedRenderSpecHead + `  upstreams: []
+  hostnames:
+    - example.com
+    - Not A Hostname
+`
+	actualResponse, _ := _testRender_nestedTestCase(t, 47, renderspec)
+
+	docs.Then("an error with the path of the invalid value is returned")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("value for parameter 'hostnames[1]' does not match item pattern ^[a-z.]+$")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestRender_ShouldComplainMissingNestedKey(t *testing.T) {
+	renderspec := `generator: nested
+parameters:
+  upstreams: []
+  database:
+    url: postgres://localhost/db
+    credentials: {}
+`
+	actualResponse, _ := _testRender_nestedTestCase(t, 48, renderspec)
+
+	docs.Then("an error with the path of the missing value is returned")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("parameter 'database.credentials.user' is required but missing")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func _testRender_bytesTestCase(t *testing.T, testcase uint, renderspec string) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator with a variable of type bytes")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-bytes.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		RenderSpecFile:      "generated-bytes.yaml",
+		IncludeResolvedSpec: true,
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldEmbedBinaryValue(t *testing.T) {
+	renderspec := `generator: bytes
+parameters:
+  icon: |
+    AAEC
+    //4KiVA=
+`
+	actualResponse, dir := _testRender_bytesTestCase(t, 49, renderspec)
+
+	docs.Then("the value is decoded and written as raw bytes, and the pattern is not checked")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "icon.bin")
+	require.Nil(t, err)
+	require.Equal(t, []byte{0x00, 0x01, 0x02, 0xff, 0xfe, 0x0a, 0x89, 0x50}, actual)
+	actual, err = dir.ReadFile(context.TODO(), "icon.txt")
+	require.Nil(t, err)
+	require.Equal(t, "size 8, base64 AAEC//4KiVA=\n", toUnix(string(actual)))
+
+	docs.Then("the resolved spec does not contain the value")
+	require.Equal(t, "***", actualResponse.ResolvedSpec.Parameters["icon"])
+}
+
+func TestRender_ShouldComplainInvalidBase64(t *testing.T) {
+	renderspec := `generator: bytes
+parameters:
+  icon: 'not base64!'
+`
+	actualResponse, _ := _testRender_bytesTestCase(t, 50, renderspec)
+
+	docs.Then("an appropriate error is returned, without the value")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("value for parameter 'icon' is not valid base64")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestRender_ShouldNameRenderSpecFileInTemplateError(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-51"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file with a value whose shape does not match what the template expects")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	renderspec := "generator: shape\nparameters:\n  upstreams: 'not a list'\n"
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-shape.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-shape.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the error for the file names the render spec file and the parameters in scope")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	actualErr := actualResponse.RenderedFiles[0].Errors[0].Error()
+	require.Contains(t, actualErr, "error evaluating template for target 'shape.txt': ")
+	require.Contains(t, actualErr, "can't evaluate field host in type uint8")
+	require.True(t, strings.HasSuffix(actualErr, " (parameters from render spec file generated-shape.yaml: upstreams)"), actualErr)
+}
+
+func _testRender_envDefaultTestCase(t *testing.T, testcase uint, safeDefaults bool) (*api.Response, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator whose default value reads an environment variable")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-envdefault.yaml", []byte("generator: envdefault\n")))
+	require.Nil(t, os.Setenv("GENERATOR_TEST_USER", "kate"))
+	defer os.Unsetenv("GENERATOR_TEST_USER")
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-envdefault.yaml",
+		SafeDefaults:   safeDefaults,
+	}
+	return generatorlib.Render(context.TODO(), request), dir
+}
+
+func TestRender_ShouldAllowEnvInDefaultsByDefault(t *testing.T) {
+	actualResponse, dir := _testRender_envDefaultTestCase(t, 52, false)
+
+	docs.Then("the default value is taken from the environment")
+	require.True(t, actualResponse.Success)
+	actual, err := dir.ReadFile(context.TODO(), "envdefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "user kate\n", toUnix(string(actual)))
+}
+
+func TestRender_ShouldRejectEnvInDefaultsInSafeDefaultsMode(t *testing.T) {
+	actualResponse, dir := _testRender_envDefaultTestCase(t, 53, true)
+
+	docs.Then("an appropriate error is returned and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Contains(t, actualResponse.Errors[0].Error(), "variable declaration user has invalid default (this is an error in the generator spec): ")
+	require.Contains(t, actualResponse.Errors[0].Error(), "function env is not available in default values in safe defaults mode")
+	require.False(t, dir.Exists(context.TODO(), "envdefault.txt"))
+}
+
+func TestRender_ShouldValidateOutputFormatIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-54"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator validateas, some of whose templates produce malformed output")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-validateas.yaml", []byte("generator: validateas\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-validateas.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("malformed output is reported as an error for its file, and that file is not written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 4, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Equal(t, "error evaluating template for target 'broken.json': rendered output is not valid json: invalid character 's' looking for beginning of value", actualResponse.RenderedFiles[1].Errors[0].Error())
+	require.True(t, actualResponse.RenderedFiles[2].Success)
+	require.False(t, actualResponse.RenderedFiles[3].Success)
+	require.Equal(t, "error evaluating template for target 'main.go': rendered output is not valid go: main.go:4:24: missing ',' before newline in argument list (and 1 more errors)", actualResponse.RenderedFiles[3].Errors[0].Error())
+
+	actual, err := dir.ReadFile(context.TODO(), "config.json")
+	require.Nil(t, err)
+	require.Equal(t, `{"name": "some-service", "port": 8080}`, string(actual))
+	_, err = dir.ReadFile(context.TODO(), "broken.json")
+	require.NotNil(t, err)
+	_, err = dir.ReadFile(context.TODO(), "main.go")
+	require.NotNil(t, err)
+}
+
+func TestRender_ShouldTranscodeOutputToRequestedEncoding(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-55"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator encoding, whose templates produce accented characters")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-encoding.yaml", []byte("generator: encoding\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-encoding.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the latin1 file is written transcoded, and the character that ascii cannot represent is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Equal(t, "error evaluating template for target 'greeting-ascii.txt': character 'ö' (U+00F6) in line 2 cannot be represented in encoding US-ASCII", actualResponse.RenderedFiles[1].Errors[0].Error())
+
+	actual, err := dir.ReadFile(context.TODO(), "greeting-latin1.txt")
+	require.Nil(t, err)
+	require.Equal(t, []byte("Gr\xfc\xdfe aus K\xf6ln\n"), actual)
+	_, err = dir.ReadFile(context.TODO(), "greeting-ascii.txt")
+	require.NotNil(t, err)
+}
+
+func TestRender_ShouldAbortOnTargetErrorsIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/invalid-generator-specs"
+	targetdirpath := "../output/render-56"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator badtarget, with one valid template, and errors in a target path and a condition")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-badtarget.yaml", []byte("generator: badtarget\n")))
+
+	docs.When("Render is invoked with FailOnTargetErrors set")
+	request := &api.Request{
+		SourceBaseDir:      sourcedirpath,
+		TargetBaseDir:      targetdirpath,
+		RenderSpecFile:     "generated-badtarget.yaml",
+		FailOnTargetErrors: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("all target errors are reported as top level errors, and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 0, len(actualResponse.RenderedFiles))
+	require.Equal(t, 2, len(actualResponse.Errors))
+	require.Equal(t, "error evaluating target path from '{{ .name .txt': template: inline template for target {{ .name .txt_path:1: unclosed action", actualResponse.Errors[0].Error())
+	require.Equal(t, "error evaluating condition from '{{ .name ': template: inline template for target conditional.txt_condition:1: unclosed action", actualResponse.Errors[1].Error())
+	_, err := dir.ReadFile(context.TODO(), "good.txt")
+	require.NotNil(t, err)
+
+	docs.When("Render is invoked without FailOnTargetErrors")
+	request.FailOnTargetErrors = false
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("only the files concerned fail, and the valid file is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 3, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success)
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.False(t, actualResponse.RenderedFiles[2].Success)
+	_, err = dir.ReadFile(context.TODO(), "good.txt")
+	require.Nil(t, err)
+}
+
+func TestRender_ShouldReportWriteProblemsUpFrontIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-57"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator preflight, and a file in the way of one of its directories")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-preflight.yaml", []byte("generator: preflight\n")))
+	require.Nil(t, dir.WriteFile(context.TODO(), "config", []byte("in the way")))
+
+	docs.When("Render is invoked with PreflightWriteCheck set")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		RenderSpecFile:      "generated-preflight.yaml",
+		PreflightWriteCheck: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the problem is reported as a top level error, and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 0, len(actualResponse.RenderedFiles))
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "cannot create path up to ../output/render-57/config, something is in the way", actualResponse.Errors[0].Error())
+	require.False(t, dir.Exists(context.TODO(), "first.txt"))
+}
+
+func TestRender_ShouldReportReadOnlyDirectoriesUpFrontIfRequested(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions do not restrict writes on windows or for root")
+	}
+
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-58"
+	_ = os.Chmod(targetdirpath+"/readonly", 0755)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator preflight, and a read-only directory one of its files goes into")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-preflight.yaml", []byte("generator: preflight\n")))
+	require.Nil(t, os.Mkdir(targetdirpath+"/readonly", 0555))
+	defer func() { _ = os.Chmod(targetdirpath+"/readonly", 0755) }()
+
+	docs.When("Render is invoked with PreflightWriteCheck set")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		RenderSpecFile:      "generated-preflight.yaml",
+		PreflightWriteCheck: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the problem is reported as a top level error, and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 0, len(actualResponse.RenderedFiles))
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Contains(t, actualResponse.Errors[0].Error(), "cannot write readonly/second.txt, directory ../output/render-58/readonly is not writable: ")
+	require.False(t, dir.Exists(context.TODO(), "first.txt"))
+}
+
+func TestRender_ShouldConcatenateTemplateFragments(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-59"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator bundle, which bundles three fragments into one file")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-bundle.yaml", []byte("generator: bundle\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-bundle.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("a single file is written, with the rendered fragments in order, separated by the separator")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, []api.FileResult{{Success: true, RelativeFilePath: "migrations.sql"}}, actualResponse.RenderedFiles)
+	actual, err := dir.ReadFile(context.TODO(), "migrations.sql")
+	require.Nil(t, err)
+	expected := "-- create items\nCREATE TABLE items (id INT);\n\n" +
+		"-- index items\nCREATE INDEX items_id ON items (id);\n\n" +
+		"-- seed items\nINSERT INTO items VALUES (1);"
+	require.Equal(t, expected, string(actual))
+}
+
+func TestRender_ShouldEnsureFinalNewlineIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-60"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator finalnewline, whose templates end in varying numbers of newlines")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-finalnewline.yaml", []byte("generator: finalnewline\n")))
+
+	docs.When("Render is invoked with EnsureFinalNewline set")
+	request := &api.Request{
+		SourceBaseDir:      sourcedirpath,
+		TargetBaseDir:      targetdirpath,
+		RenderSpecFile:     "generated-finalnewline.yaml",
+		EnsureFinalNewline: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("every rendered text file ends in exactly one newline, except where the template opts out or is just copied")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	expected := map[string]string{
+		"none.txt":   "no newline at the end\n",
+		"many.txt":   "several newlines at the end\n",
+		"one.txt":    "exactly one newline at the end\n",
+		"optout.txt": "written as rendered",
+		"copied.txt": "copied {{ unchanged }}",
+	}
+	for file, expectedContents := range expected {
+		actual, err := dir.ReadFile(context.TODO(), file)
+		require.Nil(t, err)
+		require.Equal(t, expectedContents, string(actual), file)
+	}
+}
+
+func TestRender_ShouldApplyOverrides(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-61"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a saved render spec file for generator overrides")
+	renderspec := `generator: overrides
+parameters:
+  serviceName: temp-service
+  database:
+    host: db.example.com
+    port: 6432
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-overrides.yaml", []byte(renderspec)))
+
+	docs.When("Render is invoked with an override for part of a structured parameter")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-overrides.yaml",
+		Overrides: map[string]interface{}{
+			"database": map[string]interface{}{"host": "localhost"},
+		},
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the override is merged over the saved parameters, and the render spec file is unchanged")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "overrides.txt")
+	require.Nil(t, err)
+	require.Equal(t, "temp-service uses localhost:6432", string(actual))
+	actualSpec, err := dir.ReadFile(context.TODO(), "generated-overrides.yaml")
+	require.Nil(t, err)
+	require.Equal(t, renderspec, string(actualSpec))
+
+	docs.When("Render is invoked with an override for a parameter the generator does not know")
+	request.Overrides = map[string]interface{}{"serviceNmae": "typo"}
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the override is rejected")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "parameter 'serviceNmae' is not allowed according to generator spec", actualResponse.Errors[0].Error())
+}
+
+func TestRender_ShouldResolveRenderPathsRelativeToTemplate(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-62"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator includes, whose template in a subdirectory renders fragments by relative path")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-includes.yaml", []byte("generator: includes\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-includes.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("relative paths are resolved against the directory of the including fragment, at every level")
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success, actualResponse.RenderedFiles[0].Errors)
+	actual, err := dir.ReadFile(context.TODO(), "service.txt")
+	require.Nil(t, err)
+	require.Equal(t, "part for some-service\nheader for SOME-SERVICE\n", string(actual))
+
+	docs.Then("a path outside the generator directory is reported as an error for its file")
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Contains(t, actualResponse.RenderedFiles[1].Errors[0].Error(), "cannot render ../outside.txt.tmpl, it is outside the generator directory")
+}
+
+func TestRender_ShouldPreserveExistingModeIfRequested(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-63"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator filemode, which has a template with mode 0755")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-filemode.yaml", []byte("generator: filemode\n")))
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-filemode.yaml",
+	}
+
+	docs.When("Render is invoked for the first time")
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("new files are created with the mode of their template")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	requireFileMode(t, targetdirpath+"/run.sh", 0755)
+
+	docs.Given("the user has changed the permissions of the files")
+	require.Nil(t, os.Chmod(targetdirpath+"/run.sh", 0700))
+	require.Nil(t, os.Chmod(targetdirpath+"/plain.txt", 0600))
+
+	docs.When("Render is invoked again with PreserveExistingMode set")
+	request.PreserveExistingMode = true
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the files are overwritten, but keep their permissions")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	requireFileMode(t, targetdirpath+"/run.sh", 0700)
+	requireFileMode(t, targetdirpath+"/plain.txt", 0600)
+
+	docs.When("Render is invoked again without PreserveExistingMode")
+	request.PreserveExistingMode = false
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the mode of the template is applied again, and files without a mode in their template are left alone")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	requireFileMode(t, targetdirpath+"/run.sh", 0755)
+	requireFileMode(t, targetdirpath+"/plain.txt", 0600)
+}
+
+func requireFileMode(t *testing.T, filePath string, expected os.FileMode) {
+	fileInfo, err := os.Stat(filePath)
+	require.Nil(t, err)
+	require.Equal(t, expected, fileInfo.Mode().Perm(), filePath)
+}
+
+func TestRender_ShouldLimitItems(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-64"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator manyitems, which has a template iterating over 10 items")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-manyitems.yaml", []byte("generator: manyitems\n")))
+
+	docs.When("Render is invoked with ItemLimit 3")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-manyitems.yaml",
+		ItemLimit:      3,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("only the first 3 items and the template without items are rendered")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	expected := []api.FileResult{
+		{Success: true, RelativeFilePath: "items/a.txt"},
+		{Success: true, RelativeFilePath: "items/b.txt"},
+		{Success: true, RelativeFilePath: "items/c.txt"},
+		{Success: true, RelativeFilePath: "single.txt"},
+	}
+	require.Equal(t, expected, actualResponse.RenderedFiles)
+	require.True(t, dir.Exists(context.TODO(), "items/c.txt"))
+	require.False(t, dir.Exists(context.TODO(), "items/d.txt"))
+}
+
+func TestRender_ShouldApplyItemOffset(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-65"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator manyitems, which has a template iterating over 10 items")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-manyitems.yaml", []byte("generator: manyitems\n")))
+
+	docs.When("Render is invoked with ItemOffset 8 and ItemLimit 5, which reaches beyond the last item")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-manyitems.yaml",
+		ItemOffset:     8,
+		ItemLimit:      5,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("only the remaining items are rendered")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	expected := []api.FileResult{
+		{Success: true, RelativeFilePath: "items/i.txt"},
+		{Success: true, RelativeFilePath: "items/j.txt"},
+		{Success: true, RelativeFilePath: "single.txt"},
+	}
+	require.Equal(t, expected, actualResponse.RenderedFiles)
+}
+
+func TestRender_ShouldApplyPostProcessors(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-66"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator postprocess, whose templates use built-in and custom post processors")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-postprocess.yaml", []byte("generator: postprocess\n")))
+
+	docs.Given("a context with a custom post processor registered")
+	ctx := generatorlib.WithPostProcessors(context.TODO(), map[string]api.PostProcessor{
+		"upper": func(output []byte) ([]byte, error) {
+			return []byte(strings.ToUpper(string(output))), nil
+		},
+	})
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-postprocess.yaml",
+	}
+	actualResponse := generatorlib.Render(ctx, request)
+
+	docs.Then("the output of each template has passed through its post processors in order")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	expected := map[string]string{
+		"main.go":   "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n",
+		"shout.txt": "HELLO",
+	}
+	for file, expectedContents := range expected {
+		actual, err := dir.ReadFile(context.TODO(), file)
+		require.Nil(t, err)
+		require.Equal(t, expectedContents, string(actual), file)
+	}
+}
+
+func TestRender_ShouldComplainUnknownPostProcessor(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-67"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator postprocessunknown, whose template uses a post processor that was not registered")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-postprocessunknown.yaml", []byte("generator: postprocessunknown\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-postprocessunknown.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the file is not written and its result reports the unknown post processor")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Equal(t, []error{errors.New("error evaluating template for target 'unknown.txt': unknown post processor 'prettier'")}, actualResponse.RenderedFiles[0].Errors)
+	require.False(t, dir.Exists(context.TODO(), "unknown.txt"))
+}
+
+func TestRender_ShouldRenderSelectedProfile(t *testing.T) {
+	for _, testCase := range []struct {
+		profile         string
+		targetdirpath   string
+		expectedReadme  string
+		expectedEnabled bool
+	}{
+		{"minimal", "../output/render-68", "# my-service (minimal profile), 1 replica(s)\n", false},
+		{"full", "../output/render-69", "# my-service (full profile), 3 replica(s)\n", true},
+	} {
+		docs.Given("a valid generator source directory and a valid target directory")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+
+		docs.Given("a valid render spec file for generator profiles, which offers the profiles minimal and full")
+		dir := targetdir.Instance(context.TODO(), testCase.targetdirpath)
+		require.Nil(t, dir.WriteFile(context.TODO(), "generated-profiles.yaml", []byte("generator: profiles\n")))
+
+		docs.When("Render is invoked with Profile set to " + testCase.profile)
+		request := &api.Request{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  testCase.targetdirpath,
+			RenderSpecFile: "generated-profiles.yaml",
+			Profile:        testCase.profile,
+		}
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("the templates see the profile and its defaults, and conditions can depend on it")
+		require.True(t, actualResponse.Success, actualResponse.Errors)
+		actual, err := dir.ReadFile(context.TODO(), "README.md")
+		require.Nil(t, err)
+		require.Equal(t, testCase.expectedReadme, string(actual))
+		require.Equal(t, testCase.expectedEnabled, dir.Exists(context.TODO(), "monitoring.yaml"))
+	}
+}
+
+func TestRender_ShouldComplainUnknownProfile(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-70"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator profiles")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-profiles.yaml", []byte("generator: profiles\n")))
+
+	docs.When("Render is invoked with a profile the generator does not offer")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-profiles.yaml",
+		Profile:        "huge",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the render run fails, listing the available profiles")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []error{errors.New("unknown profile 'huge', available profiles are: full, minimal")}, actualResponse.Errors)
+	require.False(t, dir.Exists(context.TODO(), "README.md"))
+}
+
+func TestRender_ShouldSkipGitignoredFilesIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory that is the root of a git repository")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-71"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	require.Nil(t, os.Mkdir(targetdirpath+"/.git", 0755))
+
+	docs.Given("gitignore files that ignore logs except keep.log, the build directory, and a file in a subdirectory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), ".gitignore", []byte("# build artifacts\n*.log\n!keep.log\nbuild/\n")))
+	require.Nil(t, dir.WriteFile(context.TODO(), "sub/.gitignore", []byte("secret.txt\n")))
+
+	docs.Given("a valid render spec file for generator gitignore")
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-gitignore.yaml", []byte("generator: gitignore\n")))
+
+	docs.When("Render is invoked with GitignoreAware set")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-gitignore.yaml",
+		GitignoreAware: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("ignored files are not written, and are reported as ignored by git")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	expected := []api.FileResult{
+		{Success: true, RelativeFilePath: "src/main.txt"},
+		{Success: true, Skipped: true, SkipReason: api.SkipReasonIgnoredByGit, RelativeFilePath: "debug.log"},
+		{Success: true, RelativeFilePath: "keep.log"},
+		{Success: true, Skipped: true, SkipReason: api.SkipReasonIgnoredByGit, RelativeFilePath: "build/out.txt"},
+		{Success: true, Skipped: true, SkipReason: api.SkipReasonIgnoredByGit, RelativeFilePath: "sub/secret.txt"},
+	}
+	require.Equal(t, expected, actualResponse.RenderedFiles)
+	require.True(t, dir.Exists(context.TODO(), "keep.log"))
+	require.False(t, dir.Exists(context.TODO(), "debug.log"))
+	require.False(t, dir.Exists(context.TODO(), "build"))
+	require.False(t, dir.Exists(context.TODO(), "sub/secret.txt"))
+}
+
+func TestRender_ShouldComplainMissingRequiredTargetFiles(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-72"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator requiresfiles, which requires go.mod and a templated main.go path")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-requiresfiles.yaml", []byte("generator: requiresfiles\n")))
+
+	docs.Given("only go.mod exists in the target directory")
+	require.Nil(t, dir.WriteFile(context.TODO(), "go.mod", []byte("module example.com/myservice\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-requiresfiles.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the render run fails before writing anything, naming the missing file")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []error{errors.New("this generator augments an existing project, but the target directory is missing required files: cmd/myservice/main.go")}, actualResponse.Errors)
+	require.Empty(t, actualResponse.RenderedFiles)
+	require.False(t, dir.Exists(context.TODO(), "internal"))
+
+	docs.When("the missing file is added and Render is invoked again")
+	require.Nil(t, dir.WriteFile(context.TODO(), "cmd/myservice/main.go", []byte("package main\n")))
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the render run succeeds")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.True(t, dir.Exists(context.TODO(), "internal/myservice/extension.go"))
+}
+
+func TestRender_ShouldNotAllowRequiredTargetFilesOutsideTargetDirectory(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-102"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator requiresfiles, whose templated main.go path points above the target directory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-requiresfiles.yaml", []byte("generator: requiresfiles\nparameters:\n  serviceName: ../../render-102\n")))
+	require.Nil(t, dir.WriteFile(context.TODO(), "go.mod", []byte("module example.com/myservice\n")))
+
+	docs.Given("the file that path happens to lead to exists")
+	require.Nil(t, dir.WriteFile(context.TODO(), "main.go", []byte("package main\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-requiresfiles.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the render run fails before writing anything, because the path leaves the target directory")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []error{errors.New("cannot require cmd/../../render-102/main.go, it is outside the target directory")}, actualResponse.Errors)
+	require.Empty(t, actualResponse.RenderedFiles)
+}
+
+func TestRender_ShouldNotLeakItemVariablesIntoLaterTemplates(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-103"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator itemscope, whose last template follows templates with with_items and with_entries")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-itemscope.yaml", []byte("generator: itemscope\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-itemscope.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the item variables are only set for the iterations of their own template")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, 4, len(actualResponse.RenderedFiles))
+	actual, err := dir.ReadFile(context.TODO(), "after.txt")
+	require.Nil(t, err)
+	require.Equal(t, "clean", string(actual))
+}
+
+func TestRender_ShouldCopyBinaryFileToComputedPath(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-73"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator binarycopy, which copies a binary file to a path depending on a variable")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-binarycopy.yaml", []byte("generator: binarycopy\nparameters:\n  theme: light\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-binarycopy.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the file is written to the evaluated target path")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, []api.FileResult{{Success: true, RelativeFilePath: "static/logo_light.png"}}, actualResponse.RenderedFiles)
+
+	docs.Then("its contents are byte for byte identical to the source, even though they are neither valid UTF-8 nor a valid template")
+	expected, err := ioutil.ReadFile(sourcedirpath + "/assets/logo.png")
+	require.Nil(t, err)
+	actual, err := dir.ReadFile(context.TODO(), "static/logo_light.png")
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestRender_ShouldEvaluateTargetExistsInConditions(t *testing.T) {
+	for _, testCase := range []struct {
+		targetdirpath  string
+		existingConfig bool
+		expectedConfig string
+		expectedNotice bool
+	}{
+		{"../output/render-74", false, "name: my-service\n", false},
+		{"../output/render-75", true, "name: customized\n", true},
+	} {
+		docs.Given("a valid generator source directory and a valid target directory")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+
+		docs.Given("a valid render spec file for generator targetexists, whose conditions check for config.yaml")
+		dir := targetdir.Instance(context.TODO(), testCase.targetdirpath)
+		require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetexists.yaml", []byte("generator: targetexists\n")))
+		if testCase.existingConfig {
+			require.Nil(t, dir.WriteFile(context.TODO(), "config.yaml", []byte("name: customized\n")))
+		}
+
+		docs.When("Render is invoked")
+		request := &api.Request{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  testCase.targetdirpath,
+			RenderSpecFile: "generated-targetexists.yaml",
+		}
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("config.yaml is only written if it was absent, and the notice only if it was present")
+		require.True(t, actualResponse.Success)
+		actualConfig, err := dir.ReadFile(context.TODO(), "config.yaml")
+		require.Nil(t, err)
+		require.Equal(t, testCase.expectedConfig, string(actualConfig))
+		require.Equal(t, testCase.expectedNotice, dir.Exists(context.TODO(), "config-notice.txt"))
+	}
+}
+
+func TestRender_ShouldNotAllowTargetExistsOutsideTargetDirectory(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-76"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator targetexistsoutside, whose condition checks a path above the target directory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetexistsoutside.yaml", []byte("generator: targetexistsoutside\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-targetexistsoutside.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the condition fails and nothing is written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Equal(t, 1, len(actualResponse.RenderedFiles[0].Errors))
+	require.Contains(t, actualResponse.RenderedFiles[0].Errors[0].Error(), "cannot check ../secret.txt, it is outside the target directory")
+	require.False(t, dir.Exists(context.TODO(), "outside.txt"))
+}
+
+func TestRender_ShouldReportConditionSkippedTemplatesIfRequested(t *testing.T) {
+	for _, testCase := range []struct {
+		targetdirpath          string
+		reportConditionSkipped bool
+		expectedRenderedFiles  []api.FileResult
+	}{
+		{"../output/render-77", false, []api.FileResult{
+			{Success: true, RelativeFilePath: "config.yaml"},
+		}},
+		{"../output/render-78", true, []api.FileResult{
+			{Success: true, RelativeFilePath: "config-notice.txt", Skipped: true, SkipReason: api.SkipReasonConditionFalse},
+			{Success: true, RelativeFilePath: "config.yaml"},
+		}},
+	} {
+		docs.Given("a valid generator source directory and a valid target directory")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+
+		docs.Given("a valid render spec file for generator targetexists, where the condition of config-notice.txt is false")
+		dir := targetdir.Instance(context.TODO(), testCase.targetdirpath)
+		require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetexists.yaml", []byte("generator: targetexists\n")))
+
+		docs.When("Render is invoked, with or without ReportConditionSkipped")
+		request := &api.Request{
+			SourceBaseDir:          sourcedirpath,
+			TargetBaseDir:          testCase.targetdirpath,
+			RenderSpecFile:         "generated-targetexists.yaml",
+			ReportConditionSkipped: testCase.reportConditionSkipped,
+		}
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("the skipped template is only reported if requested, and is not written either way")
+		require.True(t, actualResponse.Success)
+		require.Equal(t, testCase.expectedRenderedFiles, actualResponse.RenderedFiles)
+		require.False(t, dir.Exists(context.TODO(), "config-notice.txt"))
+	}
+}
+
+func TestRender_ShouldWarnAboutDeprecatedTemplates(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-79"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator deprecated, which has a deprecated template with two items")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-deprecated.yaml", []byte("generator: deprecated\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-deprecated.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("all files are still written, and each file of the deprecated template comes with a warning")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, 3, len(actualResponse.RenderedFiles))
+	for _, name := range []string{"config.yaml", "legacy-dev.properties", "legacy-prod.properties"} {
+		require.True(t, dir.Exists(context.TODO(), name), name)
+	}
+	require.Equal(t, []string{
+		"target 'legacy-dev.properties' is rendered from a deprecated template: please read config.yaml instead, the properties files will be removed in version 2",
+		"target 'legacy-prod.properties' is rendered from a deprecated template: please read config.yaml instead, the properties files will be removed in version 2",
+	}, actualResponse.Warnings)
+}
+
+func TestRender_ShouldResolveBaseDirsAgainstWorkingDir(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory, both relative to the test directory")
+	targetdirpath := "../output/render-80"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator targetexists")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetexists.yaml", []byte("generator: targetexists\n")))
+
+	docs.When("Render is invoked with the test directory as WorkingDir and base directories relative to it")
+	request := &api.Request{
+		WorkingDir:     "..",
+		SourceBaseDir:  "resources/valid-generator-structured",
+		TargetBaseDir:  "output/render-80/",
+		RenderSpecFile: "generated-targetexists.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the base directories are resolved against the working directory, not the current directory")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "config.yaml")
+	require.Nil(t, err)
+	require.Equal(t, "name: my-service\n", string(actual))
+}
+
+func TestRender_ShouldDeleteTargetFilesIfConditionIsTrue(t *testing.T) {
+	for _, testCase := range []struct {
+		targetdirpath       string
+		migrate             bool
+		existingLegacy      bool
+		expectedLegacyFile  api.FileResult
+		expectedLegacyAfter bool
+	}{
+		{"../output/render-81", true, true, api.FileResult{Success: true, RelativeFilePath: "legacy.properties", Deleted: true}, false},
+		{"../output/render-82", true, false, api.FileResult{Success: true, RelativeFilePath: "legacy.properties", Skipped: true, SkipReason: api.SkipReasonNothingToDelete}, false},
+		{"../output/render-83", false, true, api.FileResult{}, true},
+	} {
+		docs.Given("a valid generator source directory and a valid target directory")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+
+		docs.Given("a valid render spec file for generator delete, which deletes legacy.properties if migrate is set")
+		dir := targetdir.Instance(context.TODO(), testCase.targetdirpath)
+		require.Nil(t, dir.WriteFile(context.TODO(), "generated-delete.yaml", []byte(fmt.Sprintf("generator: delete\nparameters:\n  migrate: %t\n", testCase.migrate))))
+		if testCase.existingLegacy {
+			require.Nil(t, dir.WriteFile(context.TODO(), "legacy.properties", []byte("name=my-service\n")))
+		}
+
+		docs.When("Render is invoked")
+		request := &api.Request{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  testCase.targetdirpath,
+			RenderSpecFile: "generated-delete.yaml",
+		}
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("the legacy file is deleted only if the condition is true, and the deletion is reported")
+		require.True(t, actualResponse.Success, actualResponse.Errors)
+		require.True(t, dir.Exists(context.TODO(), "config.yaml"))
+		require.Equal(t, testCase.expectedLegacyAfter, dir.Exists(context.TODO(), "legacy.properties"))
+		if testCase.migrate {
+			require.Equal(t, 2, len(actualResponse.RenderedFiles))
+			require.Equal(t, testCase.expectedLegacyFile, actualResponse.RenderedFiles[1])
+		} else {
+			require.Equal(t, 1, len(actualResponse.RenderedFiles))
+		}
+	}
+}
+
+func TestRender_ShouldNotDeleteOutsideTargetDirectory(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory next to a file that must not be deleted")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-84/target"
+	require.Nil(t, os.RemoveAll("../output/render-84"))
+	require.Nil(t, os.MkdirAll(targetdirpath, 0755))
+	require.Nil(t, ioutil.WriteFile("../output/render-84/important.txt", []byte("keep me\n"), 0644))
+
+	docs.Given("a valid render spec file for generator deleteoutside, whose delete target points above the target directory")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-deleteoutside.yaml", []byte("generator: deleteoutside\n")))
+
+	docs.When("Render is invoked")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-deleteoutside.yaml",
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the deletion is refused and the file is kept")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []api.FileResult{{
+		RelativeFilePath: "../important.txt",
+		Errors:           []error{errors.New("cannot delete ../important.txt, it is outside the target directory")},
+	}}, actualResponse.RenderedFiles)
+	_, err := os.Stat("../output/render-84/important.txt")
+	require.Nil(t, err)
+}
+
+func TestRender_ShouldProvideTargetDirToTemplatesIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-85"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator targetdir, whose template and default value refer to .targetDir")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetdir.yaml", []byte("generator: targetdir\n")))
+
+	docs.When("Render is invoked with IncludeTargetDir set")
+	request := &api.Request{
+		SourceBaseDir:    sourcedirpath,
+		TargetBaseDir:    targetdirpath,
+		RenderSpecFile:   "generated-targetdir.yaml",
+		IncludeTargetDir: true,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the absolute path of the target directory is available to templates and default values")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	absolutePath, err := filepath.Abs(targetdirpath)
+	require.Nil(t, err)
+	actual, err := dir.ReadFile(context.TODO(), "go.mod")
+	require.Nil(t, err)
+	require.Equal(t, "module example.com/render-85\n\n// generated into "+filepath.ToSlash(absolutePath)+"\n", toUnix(string(actual)))
+}
+
+func _testRender_seededTestCase(t *testing.T, targetdirpath string, seed int64) string {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for generator seeded, which uses random functions")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-seeded.yaml", []byte("generator: seeded\n")))
+
+	docs.When("Render is invoked with a seed")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-seeded.yaml",
+		Seed:           seed,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "seeded.txt")
+	require.Nil(t, err)
+	return string(actual)
+}
+
+func TestRender_ShouldRenderRandomFunctionsDeterministicallyWithSeed(t *testing.T) {
+	first := _testRender_seededTestCase(t, "../output/render-86", 42)
+	second := _testRender_seededTestCase(t, "../output/render-87", 42)
+	other := _testRender_seededTestCase(t, "../output/render-88", 43)
+
+	docs.Then("the same seed produces identical output, and a different seed different output")
+	require.Equal(t, first, second)
+	require.NotEqual(t, first, other)
+	require.Regexp(t, "^password: [a-zA-Z0-9]{16}\nid: [0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}\nletters: [a-f]{6}\n$", first)
+}
+
+func TestRender_ShouldAdaptToDetectedProjectType(t *testing.T) {
+	for _, testCase := range []struct {
+		targetdirpath    string
+		withGoMod        bool
+		expectedMakefile string
+	}{
+		{"../output/render-89", true, "build:\n\tgo build ./...\n"},
+		{"../output/render-90", false, "build:\n\tmake all\n"},
+	} {
+		docs.Given("a valid generator source directory and a valid target directory")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+
+		docs.Given("a valid render spec file for generator detect, which detects go.mod in the target directory")
+		dir := targetdir.Instance(context.TODO(), testCase.targetdirpath)
+		require.Nil(t, dir.WriteFile(context.TODO(), "generated-detect.yaml", []byte("generator: detect\n")))
+		if testCase.withGoMod {
+			require.Nil(t, dir.WriteFile(context.TODO(), "go.mod", []byte("module example.com/detect\n")))
+		}
+
+		docs.When("Render is invoked")
+		request := &api.Request{
+			SourceBaseDir:  sourcedirpath,
+			TargetBaseDir:  testCase.targetdirpath,
+			RenderSpecFile: "generated-detect.yaml",
+		}
+		actualResponse := generatorlib.Render(context.TODO(), request)
+
+		docs.Then("conditions and default values see whether the marker file exists")
+		require.True(t, actualResponse.Success, actualResponse.Errors)
+		actual, err := dir.ReadFile(context.TODO(), "Makefile")
+		require.Nil(t, err)
+		require.Equal(t, testCase.expectedMakefile, string(actual))
+		require.Equal(t, testCase.withGoMod, dir.Exists(context.TODO(), ".golangci.yml"))
+	}
+}
+
+func TestRender_ShouldFailFilesExceedingMaxFileBytes(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-93"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator maxfilebytes, which has a template that expands a value many times")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-maxfilebytes.yaml", []byte("generator: maxfilebytes\n")))
+
+	docs.When("Render is invoked with MaxFileBytes set")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-maxfilebytes.yaml",
+		MaxFileBytes:   100,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the large file is reported as an error and not written, while the small file is rendered")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.True(t, actualResponse.RenderedFiles[0].Success, actualResponse.RenderedFiles[0].Errors)
+	require.Equal(t, "big.txt", actualResponse.RenderedFiles[1].RelativeFilePath)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles[1].Errors))
+	require.Contains(t, actualResponse.RenderedFiles[1].Errors[0].Error(), "rendered output exceeds limit of 100 bytes")
+	require.False(t, dir.Exists(context.TODO(), "big.txt"))
+
+	docs.When("Render is invoked without MaxFileBytes")
+	request.MaxFileBytes = 0
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the large file is written")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "big.txt")
+	require.Nil(t, err)
+	require.Equal(t, 21001, len(actual))
+}
+
+func TestRender_ShouldFailFilesWhoseRenderedFragmentExceedsMaxFileBytes(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-95"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator maxfilebytesnested, whose template renders a fragment that expands a value many times")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-maxfilebytesnested.yaml", []byte("generator: maxfilebytesnested\n")))
+
+	docs.When("Render is invoked with MaxFileBytes set")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-maxfilebytesnested.yaml",
+		MaxFileBytes:   100,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the limit already applies to the fragment, and the file is reported as an error and not written")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Equal(t, 1, len(actualResponse.RenderedFiles[0].Errors))
+	actualErr := actualResponse.RenderedFiles[0].Errors[0].Error()
+	require.Contains(t, actualErr, "error calling render: rendered output exceeds limit of 100 bytes")
+	require.False(t, dir.Exists(context.TODO(), "nested.txt"))
+}
+
+func TestRender_ShouldFailDefaultValuesExceedingMaxFileBytes(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-99"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid render spec file for generator maxfilebytesdefault, whose default value expands to a long text")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-maxfilebytesdefault.yaml", []byte("generator: maxfilebytesdefault\n")))
+
+	docs.When("Render is invoked with MaxFileBytes set")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-maxfilebytesdefault.yaml",
+		MaxFileBytes:   100,
+	}
+	actualResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the limit applies to the default value, too, and nothing is written")
+	expectedResponse := &api.Response{
+		Errors: []error{errors.New("variable declaration text has invalid default (this is an error in the generator spec): rendered output exceeds limit of 100 bytes")},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	require.False(t, dir.Exists(context.TODO(), "default.txt"))
+}
@@ -0,0 +1,77 @@
+package acceptance
+
+import (
+	"context"
+	"fmt"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func _testRenderExpression_mainTarget(t *testing.T, testcase uint) *api.Request {
+	docs.Given("a valid generator source directory and a target directory with a render spec for generator main")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := fmt.Sprintf("../output/render-expression-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	renderspec := `generator: main
+parameters:
+  serviceName: temp-service
+`
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte(renderspec)))
+	return &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+}
+
+func TestRenderExpression_ShouldEvaluateAgainstResolvedParameters(t *testing.T) {
+	request := _testRenderExpression_mainTarget(t, 1)
+
+	docs.When("RenderExpression is invoked with an expression using a given and a defaulted parameter")
+	actual, err := generatorlib.RenderExpression(context.TODO(), request, "main", "{{ .serviceName | upper }} says {{ .helloMessage }}")
+
+	docs.Then("the expression is evaluated")
+	require.Nil(t, err)
+	require.Equal(t, "TEMP-SERVICE says hello world", actual)
+}
+
+func TestRenderExpression_ShouldReportTemplateErrors(t *testing.T) {
+	request := _testRenderExpression_mainTarget(t, 2)
+
+	docs.When("RenderExpression is invoked with an invalid expression")
+	actual, err := generatorlib.RenderExpression(context.TODO(), request, "main", "{{ .serviceName | nosuchfunction }}")
+
+	docs.Then("an appropriate error is returned")
+	require.Equal(t, "", actual)
+	require.NotNil(t, err)
+	require.Equal(t, `error evaluating expression: template: __expression:1: function "nosuchfunction" not defined`, err.Error())
+}
+
+func TestRenderExpression_ShouldHonourSafeFunctions(t *testing.T) {
+	request := _testRenderExpression_mainTarget(t, 3)
+
+	docs.Given("a request in safe functions mode")
+	request.SafeFunctions = true
+
+	docs.When("RenderExpression is invoked with an expression that reads the environment")
+	actual, err := generatorlib.RenderExpression(context.TODO(), request, "main", `{{ env "HOME" }}`)
+
+	docs.Then("an appropriate error is returned")
+	require.Equal(t, "", actual)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "function env is not available in safe functions mode")
+
+	docs.When("RenderExpression is invoked with an expression that only uses safe functions")
+	actual, err = generatorlib.RenderExpression(context.TODO(), request, "main", "{{ .serviceName | upper }}")
+
+	docs.Then("the expression is evaluated")
+	require.Nil(t, err)
+	require.Equal(t, "TEMP-SERVICE", actual)
+}
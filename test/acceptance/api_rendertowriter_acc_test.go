@@ -0,0 +1,64 @@
+package acceptance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func _testRenderToWriter_target(t *testing.T, testcase uint) (*api.Request, string) {
+	docs.Given("a valid generator source directory and a target directory with a render spec for generator targetexists")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := fmt.Sprintf("../output/render-to-writer-%d", testcase)
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-targetexists.yaml", []byte("generator: targetexists\nparameters:\n  serviceName: piped-service\n")))
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-targetexists.yaml",
+	}
+	return request, targetdirpath
+}
+
+func TestRenderToWriter_ShouldWriteSingleFile(t *testing.T) {
+	request, targetdirpath := _testRenderToWriter_target(t, 1)
+
+	docs.When("RenderToWriter is invoked for one target path")
+	var buf bytes.Buffer
+	actualResponse := generatorlib.RenderToWriter(context.TODO(), request, "./config.yaml", &buf)
+
+	docs.Then("the content of that file is written to the writer, and nothing is written to the target directory")
+	expectedResponse := &api.Response{
+		Success:       true,
+		RenderedFiles: []api.FileResult{{Success: true, RelativeFilePath: "config.yaml"}},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	require.Equal(t, "name: piped-service\n", buf.String())
+	files, err := ioutil.ReadDir(targetdirpath)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(files))
+}
+
+func TestRenderToWriter_ShouldFailForUnknownTarget(t *testing.T) {
+	request, _ := _testRenderToWriter_target(t, 2)
+
+	docs.When("RenderToWriter is invoked for a target path whose condition is false")
+	var buf bytes.Buffer
+	actualResponse := generatorlib.RenderToWriter(context.TODO(), request, "config-notice.txt", &buf)
+
+	docs.Then("an error is reported and nothing is written to the writer")
+	require.Equal(t, &api.Response{Errors: []error{errors.New("the generator does not render a file with target path 'config-notice.txt'")}}, actualResponse)
+	require.Equal(t, 0, buf.Len())
+}
@@ -0,0 +1,903 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderWithValues_ShouldWriteExpectedFiles(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-values-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with valid parameters for generator main")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	parameters := map[string]interface{}{
+		"serviceName": "temp-service",
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "main", parameters)
+
+	docs.Then("the return value is as expected and the correct files are written, but no render spec")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{
+				Success:          true,
+				RelativeFilePath: "sub/sub.go.txt",
+			},
+			{
+				Success:          true,
+				RelativeFilePath: "main.go.txt",
+			},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual, err := dir.ReadFile(context.TODO(), "main.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual), `fmt.Println("temp-service started")`)
+	_, err = dir.ReadFile(context.TODO(), "generated-main.yaml")
+	require.NotNil(t, err)
+}
+
+func TestRenderWithValues_ShouldIncludeResolvedSpecIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-values-3"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with some parameters for generator main, requesting the resolved spec")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeResolvedSpec: true,
+	}
+	parameters := map[string]interface{}{
+		"serviceName": "temp-service",
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "main", parameters)
+
+	docs.Then("the response contains the render spec with all defaults filled in")
+	require.True(t, actualResponse.Success)
+	expectedSpec := &api.RenderSpec{
+		Version:       1,
+		GeneratorName: "main",
+		Parameters: map[string]interface{}{
+			"serviceName":  "temp-service",
+			"serviceUrl":   "github.com/mundobaton/temp",
+			"helloMessage": "hello world",
+		},
+	}
+	require.Equal(t, expectedSpec, actualResponse.ResolvedSpec)
+}
+
+func TestRenderWithValues_ShouldComplainUnknownParameter(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-values-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with an unexpected additional parameter (not in the spec)")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	parameters := map[string]interface{}{
+		"serviceName": "temp-service",
+		"unexpected":  "value",
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "main", parameters)
+
+	docs.Then("the response reports an appropriate error")
+	require.False(t, actualResponse.Success)
+	require.Empty(t, actualResponse.RenderedFiles)
+	require.Equal(t, "parameter 'unexpected' is not allowed according to generator spec", actualResponse.Errors[0].Error())
+}
+
+type mainGeneratorParameters struct {
+	ServiceName  string `yaml:"serviceName"`
+	HelloMessage string `json:"helloMessage,omitempty"`
+	Ignored      string `yaml:"-"`
+	unexported   string
+}
+
+func TestRenderWithStruct_ShouldWriteExpectedFiles(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-struct-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithStruct is invoked with a struct pointer containing tagged, ignored and unexported fields")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	parameters := &mainGeneratorParameters{
+		ServiceName:  "struct-service",
+		HelloMessage: "hello struct",
+		Ignored:      "not a parameter",
+		unexported:   "not a parameter either",
+	}
+	actualResponse := generatorlib.RenderWithStruct(context.TODO(), request, "main", parameters)
+
+	docs.Then("the tagged fields are used as parameters and the correct files are written")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual1, err := dir.ReadFile(context.TODO(), "sub/sub.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual1), `fmt.Println("HELLO STRUCT")`)
+	actual2, err := dir.ReadFile(context.TODO(), "main.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual2), `fmt.Println("struct-service started")`)
+}
+
+func TestRenderWithStruct_ShouldValidateAgainstSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-struct-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithStruct is invoked with a value that does not match the pattern")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	parameters := mainGeneratorParameters{
+		ServiceName:  "Invalid Name",
+		HelloMessage: "hello",
+	}
+	actualResponse := generatorlib.RenderWithStruct(context.TODO(), request, "main", parameters)
+
+	docs.Then("the response reports an appropriate validation error")
+	require.False(t, actualResponse.Success)
+	require.Empty(t, actualResponse.RenderedFiles)
+	require.Equal(t, "value for parameter 'serviceName' does not match pattern ^[a-z-]+$", actualResponse.Errors[0].Error())
+}
+
+type mainGeneratorOptionalParameters struct {
+	ServiceName  string  `yaml:"serviceName"`
+	ServiceUrl   *string `yaml:"serviceUrl"`
+	HelloMessage string  `json:"helloMessage,omitempty"`
+}
+
+func TestRenderWithStruct_ShouldApplyDefaultsForUnsetFields(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/render-with-struct-3"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithStruct is invoked with a nil pointer field and an empty omitempty field")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	parameters := mainGeneratorOptionalParameters{
+		ServiceName: "struct-service",
+	}
+	actualResponse := generatorlib.RenderWithStruct(context.TODO(), request, "main", parameters)
+
+	docs.Then("both fields fall back to their defaults from the generator spec")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual1, err := dir.ReadFile(context.TODO(), "sub/sub.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual1), `fmt.Println("HELLO WORLD")`)
+	actual2, err := dir.ReadFile(context.TODO(), "main.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual2), `"github.com/mundobaton/temp/sub"`)
+
+	docs.When("RenderWithStruct is invoked with the pointer field set")
+	serviceUrl := "github.com/mundobaton/other"
+	parameters.ServiceUrl = &serviceUrl
+	actualResponse = generatorlib.RenderWithStruct(context.TODO(), request, "main", parameters)
+
+	docs.Then("the value it points to is used")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual2, err = dir.ReadFile(context.TODO(), "main.go.txt")
+	require.Nil(t, err)
+	require.Contains(t, string(actual2), `"github.com/mundobaton/other/sub"`)
+}
+
+func TestRenderWithStruct_ShouldComplainNotAStruct(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+
+	docs.When("RenderWithStruct is invoked with something that is not a struct")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: "../output",
+	}
+	actualResponse := generatorlib.RenderWithStruct(context.TODO(), request, "main", "serviceName=oops")
+
+	docs.Then("the response reports an appropriate error")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, "parameters must be a struct or a pointer to a struct, got string", actualResponse.Errors[0].Error())
+}
+
+func TestRenderWithValues_ShouldNameParametersInTemplateError(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-4"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a value whose shape does not match what the template expects")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "shape", map[string]interface{}{"upstreams": "not a list"})
+
+	docs.Then("the error for the file names the parameters in scope, but not their values")
+	require.False(t, actualResponse.Success)
+	actualErr := actualResponse.RenderedFiles[0].Errors[0].Error()
+	require.True(t, strings.HasSuffix(actualErr, "can't evaluate field host in type uint8 (parameters: upstreams)"), actualErr)
+}
+
+func TestRenderWithValues_ShouldIgnoreVariablesOfDisabledGroup(t *testing.T) {
+	docs.Given("a generator with a group of variables that only applies if a toggle is on")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-5"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with the toggle off, an invalid value for one variable of the group and none for another")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeResolvedSpec: true,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "groupcondition", map[string]interface{}{
+		"tlsCert": "not-a-path",
+	})
+
+	docs.Then("the variables of the group are neither required nor validated, and set to nil")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, map[string]interface{}{
+		"enableTls": false,
+		"tlsCert":   nil,
+		"tlsPort":   nil,
+	}, actualResponse.ResolvedSpec.Parameters)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "groupcondition.txt")
+	require.Nil(t, err)
+	require.Equal(t, "plain\n", string(actual))
+}
+
+func TestRenderWithValues_ShouldRequireVariablesOfEnabledGroup(t *testing.T) {
+	docs.Given("a generator with a group of variables that only applies if a toggle is on")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-6"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with the toggle on, but without a required variable of the group")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "groupcondition", map[string]interface{}{
+		"enableTls": true,
+	})
+
+	docs.Then("the missing variable is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "parameter 'tlsCert' is required but missing", actualResponse.Errors[0].Error())
+
+	docs.When("RenderWithValues is invoked with the toggle on and an invalid value for a variable of the group")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "groupcondition", map[string]interface{}{
+		"enableTls": true,
+		"tlsCert":   "not-a-path",
+	})
+
+	docs.Then("the value is validated")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "value for parameter 'tlsCert' does not match pattern ^/", actualResponse.Errors[0].Error())
+
+	docs.When("RenderWithValues is invoked with the toggle on and valid values")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "groupcondition", map[string]interface{}{
+		"enableTls": true,
+		"tlsCert":   "/etc/tls/cert.pem",
+	})
+
+	docs.Then("the variables of the group are available, including defaults")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "groupcondition.txt")
+	require.Nil(t, err)
+	require.Equal(t, "tls /etc/tls/cert.pem on 8443\n", string(actual))
+}
+
+func TestRenderWithValues_ShouldDetectCaseCollisionsIfRequested(t *testing.T) {
+	docs.Given("a generator with two target paths that only differ in case")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-7"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with case collision detection")
+	request := &api.Request{
+		SourceBaseDir:        sourcedirpath,
+		TargetBaseDir:        targetdirpath,
+		DetectCaseCollisions: true,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "casecollision", map[string]interface{}{})
+
+	docs.Then("the first file is written, and the second one is reported as an error")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 2, len(actualResponse.RenderedFiles))
+	require.Equal(t, api.FileResult{Success: true, RelativeFilePath: "Readme.md"}, actualResponse.RenderedFiles[0])
+	require.False(t, actualResponse.RenderedFiles[1].Success)
+	require.Equal(t, "README.md", actualResponse.RenderedFiles[1].RelativeFilePath)
+	require.Equal(t, "target path 'README.md' differs from 'Readme.md' only in case, so it would overwrite it on case-insensitive file systems",
+		actualResponse.RenderedFiles[1].Errors[0].Error())
+	_, err := os.Stat(targetdirpath + "/README.md")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestRenderWithValues_ShouldLowercaseTargetPathsIfRequested(t *testing.T) {
+	docs.Given("a generator with two target paths that only differ in case")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-8"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with lower case target paths")
+	request := &api.Request{
+		SourceBaseDir:        sourcedirpath,
+		TargetBaseDir:        targetdirpath,
+		LowercaseTargetPaths: true,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "casecollision", map[string]interface{}{})
+
+	docs.Then("both templates are written to the same lower case path on every file system")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{Success: true, RelativeFilePath: "readme.md"},
+			{Success: true, RelativeFilePath: "readme.md"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "readme.md")
+	require.Nil(t, err)
+	require.Equal(t, "# Generated\n", string(actual))
+}
+
+func TestRenderWithValues_ShouldRenderInlineTemplates(t *testing.T) {
+	docs.Given("a generator with a template given inline in the generator spec")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-9"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "inline", map[string]interface{}{})
+
+	docs.Then("the inline template is rendered like one read from a file")
+	expectedResponse := &api.Response{
+		Success: true,
+		RenderedFiles: []api.FileResult{
+			{Success: true, RelativeFilePath: ".initialized"},
+			{Success: true, RelativeFilePath: "README.md"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), ".initialized")
+	require.Nil(t, err)
+	require.Equal(t, "initialized by platform-team", string(actual))
+}
+
+func TestRenderWithValues_ShouldRejectTemplateWithSourceAndInlineContent(t *testing.T) {
+	docs.Given("a generator with a template that sets both a source file and inline content")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-10"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "inlineinvalid", map[string]interface{}{})
+
+	docs.Then("an error is reported for the template")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.RenderedFiles))
+	require.Equal(t, "template for target .initialized must set exactly one of source, sources and content (this is an error in the generator spec)",
+		actualResponse.RenderedFiles[0].Errors[0].Error())
+}
+
+func TestRenderWithValues_ShouldProvideGeneratorContextToDefaults(t *testing.T) {
+	docs.Given("a generator with defaults that refer to the generator name and the build context")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-11"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked without values, including the build context")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeBuildContext: true,
+		BuildTimestamp:      time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "namedefault", map[string]interface{}{})
+
+	docs.Then("the defaults are evaluated with the generator name and the build context")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual, err := dir.ReadFile(context.TODO(), "namedefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "namedefault-service generated at 2021-03-04T05:06:07Z\n", string(actual))
+
+	docs.When("RenderWithValues is invoked in safe defaults mode")
+	request.SafeDefaults = true
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "namedefault", map[string]interface{}{})
+
+	docs.Then("the generator name is still available to defaults, but the host dependent build context is not")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err = dir.ReadFile(context.TODO(), "namedefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "namedefault-service generated at \n", string(actual))
+}
+
+func TestRenderWithValues_ShouldUseSharedVariables(t *testing.T) {
+	docs.Given("a generator that includes shared variables files")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-12"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a value for one of the shared variables")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "shared", map[string]interface{}{
+		"copyrightYear": "2022",
+	})
+
+	docs.Then("the templates see the shared variables like the generator's own")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "shared.txt")
+	require.Nil(t, err)
+	require.Equal(t, "(c) 2022 Mundo Baton, licensed under Apache-2.0\n", string(actual))
+}
+
+func TestRenderWithValues_ShouldDescribeOffendingValueInTemplateError(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-13"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a long string where the template expects a list")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "rangeshape", map[string]interface{}{
+		"upstreams": "first.example.com, second.example.com, third.example.com",
+		"password":  "secret",
+	})
+
+	docs.Then("the error names the type of the offending value and a truncated form of it")
+	require.False(t, actualResponse.Success)
+	actualErr := actualResponse.RenderedFiles[0].Errors[0].Error()
+	require.True(t, strings.HasSuffix(actualErr, `(parameters: password, upstreams; upstreams is string "first.example.com, second.example.com, t...")`), actualErr)
+
+	docs.When("RenderWithValues is invoked with a sensitive value that does not have the expected shape")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "rangeshape", map[string]interface{}{
+		"password": "hunter2",
+	})
+
+	docs.Then("the error names the type of the offending value, but not the value")
+	require.False(t, actualResponse.Success)
+	actualErr = actualResponse.RenderedFiles[0].Errors[0].Error()
+	require.True(t, strings.HasSuffix(actualErr, `range can't iterate over *** (parameters: password, upstreams; password is string, value not shown)`), actualErr)
+	require.NotContains(t, actualErr, "hunter2")
+}
+
+func TestRenderWithValues_ShouldApplyConditionalDefaults(t *testing.T) {
+	docs.Given("a generator with defaults that depend on other parameters")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-14"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+
+	docs.When("RenderWithValues is invoked with TLS enabled")
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "conditionaldefault", map[string]interface{}{
+		"enableTls": true,
+	})
+
+	docs.Then("the conditional defaults apply, including one that depends on another conditional default")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "conditionaldefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "listen 443, health 444\n", string(actual))
+
+	docs.When("RenderWithValues is invoked without TLS")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "conditionaldefault", map[string]interface{}{})
+
+	docs.Then("the regular defaults apply")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err = dir.ReadFile(context.TODO(), "conditionaldefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "listen 80, health 81\n", string(actual))
+
+	docs.When("RenderWithValues is invoked with TLS enabled, but an explicit port")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "conditionaldefault", map[string]interface{}{
+		"enableTls": true,
+		"port":      8443,
+	})
+
+	docs.Then("the explicit value wins, and defaults depending on it follow it")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err = dir.ReadFile(context.TODO(), "conditionaldefault.txt")
+	require.Nil(t, err)
+	require.Equal(t, "listen 8443, health 81\n", string(actual))
+}
+
+func TestRenderWithValues_ShouldRejectCyclicConditionalDefaults(t *testing.T) {
+	docs.Given("a generator with conditional defaults that depend on each other")
+	sourcedirpath := "../resources/invalid-generator-specs"
+	targetdirpath := "../output/render-with-values-15"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "conditionaldefaultcycle", map[string]interface{}{})
+
+	docs.Then("the cycle is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "variable declaration first has cyclic conditional defaults: first -> second -> first (this is an error in the generator spec)", actualResponse.Errors[0].Error())
+}
+
+func TestRenderWithValues_ShouldCoerceStringsIfRequested(t *testing.T) {
+	docs.Given("a generator with variables of type list and map")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-16"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		CoerceStrings:       true,
+		IncludeResolvedSpec: true,
+	}
+
+	docs.When("RenderWithValues is invoked with CoerceStrings set, and string values as from the command line")
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "coerce", map[string]interface{}{
+		"tags":   `["a","b"]`,
+		"labels": `{"region": "eu"}`,
+	})
+
+	docs.Then("the strings are parsed into structured values")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "coerce.txt")
+	require.Nil(t, err)
+	require.Equal(t, "tag a\ntag b\nlabel region=eu\n", string(actual))
+	require.Equal(t, map[string]interface{}{"region": "eu"}, actualResponse.ResolvedSpec.Parameters["labels"])
+
+	docs.When("RenderWithValues is invoked with a string that does not parse")
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "coerce", map[string]interface{}{
+		"tags": `["a",`,
+	})
+
+	docs.Then("an error naming the variable is returned")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Contains(t, actualResponse.Errors[0].Error(), "value for parameter 'tags' cannot be parsed as a list: ")
+}
+
+func TestRenderWithValues_ShouldRequireStructureForTypedVariables(t *testing.T) {
+	docs.Given("a generator with variables of type list and map")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-17"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked without CoerceStrings, and a string value for a list")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "coerce", map[string]interface{}{
+		"tags": `["a","b"]`,
+	})
+
+	docs.Then("the value is rejected")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "value for parameter 'tags' must be a list", actualResponse.Errors[0].Error())
+}
+
+func TestRenderWithValues_ShouldCallParameterHook(t *testing.T) {
+	docs.Given("a generator whose template uses a value that the embedding application provides")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-18"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a parameter hook that adds the value")
+	var seen interface{}
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+		ParameterHook: func(parameters map[string]interface{}) (map[string]interface{}, error) {
+			seen = parameters["serviceName"]
+			parameters["registeredBy"] = "my-app"
+			return parameters, nil
+		},
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "hook", map[string]interface{}{})
+
+	docs.Then("the hook sees the resolved parameters, and the templates see its changes")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, "some-service", seen)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "hook.txt")
+	require.Nil(t, err)
+	require.Equal(t, "some-service registered by my-app", string(actual))
+
+	docs.When("RenderWithValues is invoked with a parameter hook that fails")
+	request.ParameterHook = func(parameters map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errors.New("registry unavailable")
+	}
+	actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "hook", map[string]interface{}{})
+
+	docs.Then("the render run fails")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+	require.Equal(t, "parameter hook failed: registry unavailable", actualResponse.Errors[0].Error())
+}
+
+func TestRenderWithValues_ShouldRedactSensitiveValuesInResolvedSpec(t *testing.T) {
+	docs.Given("a generator with a sensitive variable")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-24"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a value for it, requesting the resolved spec")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeResolvedSpec: true,
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "rangeshape", map[string]interface{}{
+		"password": []interface{}{"hunter2"},
+	})
+
+	docs.Then("the resolved spec contains the other values, but not the sensitive one")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	require.Equal(t, map[string]interface{}{
+		"upstreams": []interface{}{"localhost"},
+		"password":  "***",
+	}, actualResponse.ResolvedSpec.Parameters)
+
+	docs.Then("templates still see the actual value")
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "rangeshape.txt")
+	require.Nil(t, err)
+	require.Equal(t, "server localhost;\npassword hunter2\n", toUnix(string(actual)))
+}
+
+func TestRenderWithValues_ShouldNotLetParameterHookChangeResolvedSpec(t *testing.T) {
+	docs.Given("a generator with a map variable")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-25"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("RenderWithValues is invoked with a parameter hook that changes a value inside the map, requesting the resolved spec")
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeResolvedSpec: true,
+		ParameterHook: func(parameters map[string]interface{}) (map[string]interface{}, error) {
+			parameters["labels"].(map[string]interface{})["region"] = "us"
+			parameters["tags"].([]interface{})[0] = "changed"
+			return parameters, nil
+		},
+	}
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "coerce", map[string]interface{}{
+		"tags":   []interface{}{"a"},
+		"labels": map[string]interface{}{"region": "eu"},
+	})
+
+	docs.Then("templates see the changes, but the resolved spec contains the values before the hook")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "coerce.txt")
+	require.Nil(t, err)
+	require.Equal(t, "tag changed\nlabel region=us\n", toUnix(string(actual)))
+	require.Equal(t, map[string]interface{}{
+		"tags":   []interface{}{"a"},
+		"labels": map[string]interface{}{"region": "eu"},
+	}, actualResponse.ResolvedSpec.Parameters)
+}
+
+func TestRenderWithValues_ShouldValidateWithSharedPatternDefinition(t *testing.T) {
+	docs.Given("a generator whose two variables reference the same named pattern")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-19"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+
+	docs.When("RenderWithValues is invoked with values that match the pattern")
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "patterndefs", map[string]interface{}{
+		"serviceName": "my-service",
+		"namespace":   "apps",
+	})
+
+	docs.Then("rendering succeeds")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "patterndefs.txt")
+	require.Nil(t, err)
+	require.Equal(t, "my-service.apps.svc\n", string(actual))
+
+	for _, parameters := range []map[string]interface{}{
+		{"serviceName": "My_Service"},
+		{"serviceName": "my-service", "namespace": "-apps"},
+	} {
+		docs.When("RenderWithValues is invoked with a value for either variable that does not match the pattern")
+		actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "patterndefs", parameters)
+
+		docs.Then("validation fails with the referenced pattern")
+		require.False(t, actualResponse.Success)
+		require.Equal(t, 1, len(actualResponse.Errors))
+		require.Contains(t, actualResponse.Errors[0].Error(), "does not match pattern ^[a-z0-9]([a-z0-9-]*[a-z0-9])?$")
+	}
+}
+
+func TestRenderWithValues_ShouldParseDurations(t *testing.T) {
+	docs.Given("a generator with a variable of type duration")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-20"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	request := &api.Request{
+		SourceBaseDir:       sourcedirpath,
+		TargetBaseDir:       targetdirpath,
+		IncludeResolvedSpec: true,
+	}
+
+	docs.When("RenderWithValues is invoked with a valid duration")
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "duration", map[string]interface{}{
+		"timeout": "1h30m",
+	})
+
+	docs.Then("templates see the parsed duration, and the resolved spec contains it in canonical form")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "duration.txt")
+	require.Nil(t, err)
+	require.Equal(t, "timeout: 1h30m0s (5400 seconds)\n", string(actual))
+	require.Equal(t, "1h30m0s", actualResponse.ResolvedSpec.Parameters["timeout"])
+
+	for _, testCase := range []struct {
+		value         interface{}
+		expectedError string
+	}{
+		{"nonsense", "value for parameter 'timeout' must be a duration such as 30s or 1h30m: time: invalid duration \"nonsense\""},
+		{90, "value for parameter 'timeout' must be a duration such as 30s or 1h30m, but is int"},
+		{"3h", "value for parameter 'timeout' must be at most 2h0m0s, but is 3h0m0s"},
+	} {
+		docs.When("RenderWithValues is invoked with an invalid or out of range duration")
+		actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "duration", map[string]interface{}{
+			"timeout": testCase.value,
+		})
+
+		docs.Then("validation fails with an error naming the variable")
+		require.False(t, actualResponse.Success)
+		require.Equal(t, []error{errors.New(testCase.expectedError)}, actualResponse.Errors)
+	}
+}
+
+func TestRenderWithValues_ShouldValidateWithMultiplePatterns(t *testing.T) {
+	docs.Given("a generator with a variable that must match all of several patterns, and one that must match any of them")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/render-with-values-21"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+
+	docs.When("RenderWithValues is invoked with values that satisfy the patterns")
+	actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "patterns", map[string]interface{}{
+		"password": "s3cretpass",
+		"region":   "us-east-1",
+	})
+
+	docs.Then("rendering succeeds")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "patterns.txt")
+	require.Nil(t, err)
+	require.Equal(t, "us-east-1:s3cretpass\n", string(actual))
+
+	for _, testCase := range []struct {
+		parameters    map[string]interface{}
+		expectedError string
+	}{
+		{map[string]interface{}{"password": "secretpass"}, "value for parameter 'password' does not match pattern [0-9]"},
+		{map[string]interface{}{"password": "s3cret"}, "value for parameter 'password' does not match pattern ^.{8,}$"},
+		{map[string]interface{}{"password": "12 34"}, "value for parameter 'password' does not match patterns '^\\S+$', '[a-zA-Z]', '^.{8,}$'"},
+		{map[string]interface{}{"password": "s3cretpass", "region": "ap-south-1"}, "value for parameter 'region' does not match any of the patterns '^eu-', '^us-'"},
+	} {
+		docs.When("RenderWithValues is invoked with a value that violates the patterns")
+		actualResponse = generatorlib.RenderWithValues(context.TODO(), request, "patterns", testCase.parameters)
+
+		docs.Then("validation fails, naming the patterns that are not satisfied")
+		require.False(t, actualResponse.Success)
+		require.Equal(t, []error{errors.New(testCase.expectedError)}, actualResponse.Errors)
+	}
+}
+
+func TestRenderWithValues_ShouldTellProvidedFromDefaultedValues(t *testing.T) {
+	for _, testCase := range []struct {
+		targetdirpath string
+		parameters    map[string]interface{}
+		expected      string
+	}{
+		{"../output/render-with-values-22", map[string]interface{}{}, "port: 8080 # auto-defaulted\n"},
+		{"../output/render-with-values-23", map[string]interface{}{"port": 8080}, "port: 8080\n"},
+	} {
+		docs.Given("a generator whose template branches on whether the port was provided")
+		sourcedirpath := "../resources/valid-generator-structured"
+		require.Nil(t, os.RemoveAll(testCase.targetdirpath))
+		require.Nil(t, os.Mkdir(testCase.targetdirpath, 0755))
+		request := &api.Request{
+			SourceBaseDir:   sourcedirpath,
+			TargetBaseDir:   testCase.targetdirpath,
+			IncludeProvided: true,
+		}
+
+		docs.When("RenderWithValues is invoked with IncludeProvided, and with or without the port")
+		actualResponse := generatorlib.RenderWithValues(context.TODO(), request, "provided", testCase.parameters)
+
+		docs.Then("the template sees in .provided whether the value was set explicitly, even if it equals the default")
+		require.True(t, actualResponse.Success, actualResponse.Errors)
+		actual, err := targetdir.Instance(context.TODO(), testCase.targetdirpath).ReadFile(context.TODO(), "provided.txt")
+		require.Nil(t, err)
+		require.Equal(t, testCase.expected, string(actual))
+	}
+}
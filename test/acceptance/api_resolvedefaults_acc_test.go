@@ -0,0 +1,123 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestResolveDefaults_ShouldReportSourceOfEachValue(t *testing.T) {
+	docs.Given("a generator with literal, template and conditional defaults, and a variable without a default")
+	request := &api.Request{
+		SourceBaseDir: "../resources/valid-generator-structured",
+	}
+
+	docs.When("ResolveDefaults is invoked with one explicit value")
+	actualResponse := generatorlib.ResolveDefaults(context.TODO(), request, "resolvedefaults", map[string]interface{}{
+		"serviceName": "temp-service",
+	})
+
+	docs.Then("each variable is reported with its value and where that value came from")
+	expectedResponse := &api.DefaultResolutionResponse{
+		Success: true,
+		Parameters: map[string]api.ResolvedParameter{
+			"serviceName": {Value: "temp-service", Source: api.ParameterSourceExplicit},
+			"owner":       {Value: nil, Source: api.ParameterSourceNil},
+			"namespace":   {Value: "resolvedefaults-apps", Source: api.ParameterSourceDefaultTemplate},
+			"replicas":    {Value: 2, Source: api.ParameterSourceDefaultLiteral},
+			"enableTls":   {Value: true, Source: api.ParameterSourceDefaultLiteral},
+			"port":        {Value: 443, Source: api.ParameterSourceComputed},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestResolveDefaults_ShouldPreferExplicitValues(t *testing.T) {
+	docs.Given("a generator with a conditional default")
+	request := &api.Request{
+		SourceBaseDir: "../resources/valid-generator-structured",
+	}
+
+	docs.When("ResolveDefaults is invoked with a value that switches the condition off")
+	actualResponse := generatorlib.ResolveDefaults(context.TODO(), request, "resolvedefaults", map[string]interface{}{
+		"enableTls": false,
+	})
+
+	docs.Then("the explicit value is reported, and the regular default applies to the dependent variable")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, api.ResolvedParameter{Value: false, Source: api.ParameterSourceExplicit}, actualResponse.Parameters["enableTls"])
+	require.Equal(t, api.ResolvedParameter{Value: 80, Source: api.ParameterSourceDefaultLiteral}, actualResponse.Parameters["port"])
+}
+
+func TestResolveDefaults_ShouldReportMissingGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	request := &api.Request{
+		SourceBaseDir: "../resources/valid-generator-structured",
+	}
+
+	docs.When("ResolveDefaults is invoked for a generator that does not exist")
+	actualResponse := generatorlib.ResolveDefaults(context.TODO(), request, "doesnotexist", map[string]interface{}{})
+
+	docs.Then("an error is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+}
+
+func TestCompareRenderSpecToDefaults_ShouldClassifyEachParameter(t *testing.T) {
+	docs.Given("a render spec with default, customized and no longer valid parameters for a generator with defaults")
+	targetdirpath := "../output/compare-defaults-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-resolvedefaults.yaml", []byte(
+		"generator: resolvedefaults\nparameters:\n  serviceName: temp-service\n  namespace: resolvedefaults-apps\n"+
+			"  replicas: 2\n  enableTls: false\n  port: 80\n  removedSetting: value\n")))
+
+	docs.When("CompareRenderSpecToDefaults is invoked")
+	request := &api.Request{
+		SourceBaseDir:  "../resources/valid-generator-structured",
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-resolvedefaults.yaml",
+	}
+	actualResponse := generatorlib.CompareRenderSpecToDefaults(context.TODO(), request)
+
+	docs.Then("each parameter is classified, and defaults are resolved given the other parameters")
+	expectedResponse := &api.DefaultComparisonResponse{
+		Success: true,
+		Parameters: map[string]api.ComparedParameter{
+			"serviceName":    {Value: "temp-service", Default: nil, Status: api.ParameterStatusCustomized},
+			"namespace":      {Value: "resolvedefaults-apps", Default: "resolvedefaults-apps", Status: api.ParameterStatusMatchesDefault},
+			"replicas":       {Value: 2, Default: 2, Status: api.ParameterStatusMatchesDefault},
+			"enableTls":      {Value: false, Default: true, Status: api.ParameterStatusCustomized},
+			"port":           {Value: 80, Default: 80, Status: api.ParameterStatusMatchesDefault},
+			"removedSetting": {Value: "value", Status: api.ParameterStatusNoLongerValid, Reason: "the generator no longer has this variable"},
+		},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestCompareRenderSpecToDefaults_ShouldReportInvalidValues(t *testing.T) {
+	docs.Given("a render spec with a value that no longer passes validation")
+	targetdirpath := "../output/compare-defaults-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\nparameters:\n  serviceName: Temp_Service\n")))
+
+	docs.When("CompareRenderSpecToDefaults is invoked")
+	request := &api.Request{
+		SourceBaseDir: "../resources/valid-generator-simple",
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.CompareRenderSpecToDefaults(context.TODO(), request)
+
+	docs.Then("the invalid value is reported with the validation error as the reason")
+	require.True(t, actualResponse.Success)
+	require.Equal(t, api.ParameterStatusNoLongerValid, actualResponse.Parameters["serviceName"].Status)
+	require.NotEqual(t, "", actualResponse.Parameters["serviceName"].Reason)
+}
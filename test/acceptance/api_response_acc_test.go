@@ -0,0 +1,66 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestResponseAsError_ShouldBeNilWithoutErrors(t *testing.T) {
+	docs.Given("a successful response")
+	response := &api.Response{Success: true, RenderedFiles: []api.FileResult{{Success: true, RelativeFilePath: "main.txt"}}}
+
+	docs.When("AsError and Err are invoked")
+	docs.Then("both return nil")
+	require.Nil(t, response.AsError())
+	require.Nil(t, response.Err())
+}
+
+func TestResponseAsError_ShouldReturnSingleErrorAsIs(t *testing.T) {
+	docs.Given("a response for a render spec file that does not exist")
+	request := &api.Request{
+		SourceBaseDir:  "../resources/valid-generator-simple",
+		TargetBaseDir:  "../resources/valid-generator-simple",
+		RenderSpecFile: "does-not-exist.yaml",
+	}
+	response := generatorlib.Render(context.TODO(), request)
+
+	docs.When("AsError and Err are invoked")
+	docs.Then("both return the one error")
+	require.Equal(t, 1, len(response.Errors))
+	require.Equal(t, response.Errors[0], response.AsError())
+	require.Equal(t, response.Errors[0], response.Err())
+}
+
+func TestResponseAsError_ShouldCombineMultipleErrors(t *testing.T) {
+	docs.Given("a response with a top level error and errors for two files")
+	first := errors.New("first problem")
+	second := errors.New("second problem")
+	third := errors.New("third problem")
+	response := &api.Response{
+		Errors: []error{first},
+		RenderedFiles: []api.FileResult{
+			{Success: true, RelativeFilePath: "fine.txt"},
+			{RelativeFilePath: "a.txt", Errors: []error{second}},
+			{RelativeFilePath: "b.txt", Errors: []error{third}},
+		},
+	}
+
+	docs.When("AsError is invoked")
+	err := response.AsError()
+
+	docs.Then("all errors are combined, file errors prefixed with the path, and each can still be found")
+	require.Equal(t, "first problem\na.txt: second problem\nb.txt: third problem", err.Error())
+	multi, ok := err.(*api.MultiError)
+	require.True(t, ok)
+	require.Equal(t, 3, len(multi.Errors))
+	require.True(t, errors.Is(multi.Errors[2], third))
+
+	docs.When("Err is invoked")
+	docs.Then("the first error is returned")
+	require.Equal(t, first, response.Err())
+}
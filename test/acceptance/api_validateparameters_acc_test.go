@@ -0,0 +1,172 @@
+package acceptance
+
+import (
+	"context"
+	"errors"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestValidateParameters_ShouldAcceptValidParameters(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+
+	docs.When("ValidateParameters is invoked with valid values for all required parameters")
+	actualResponse := generatorlib.ValidateParameters(context.TODO(), sourcedirpath, "main", map[string]interface{}{
+		"serviceName": "temp-service",
+	}, api.ParameterValidationOptions{})
+
+	docs.Then("validation succeeds")
+	expectedResponse := &api.ParameterValidationResponse{
+		Success:           true,
+		InvalidParameters: []api.InvalidParameter{},
+		MissingParameters: []string{},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestValidateParameters_ShouldReportInvalidAndMissingParameters(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+
+	docs.When("ValidateParameters is invoked with an invalid value, an unknown parameter, and a required parameter missing")
+	actualResponse := generatorlib.ValidateParameters(context.TODO(), sourcedirpath, "planvalues", map[string]interface{}{
+		"withDocs": true,
+		"unknown":  "value",
+	}, api.ParameterValidationOptions{})
+
+	docs.Then("validation fails, listing every problem")
+	expectedResponse := &api.ParameterValidationResponse{
+		InvalidParameters: []api.InvalidParameter{
+			{Name: "unknown", Error: errors.New("parameter 'unknown' is not allowed according to generator spec")},
+		},
+		MissingParameters: []string{"serviceName"},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestValidateParameters_ShouldTolerateMissingParametersIfIncompleteAllowed(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+
+	docs.When("ValidateParameters is invoked allowing incomplete parameters, without the required parameter")
+	options := api.ParameterValidationOptions{AllowIncomplete: true}
+	actualResponse := generatorlib.ValidateParameters(context.TODO(), sourcedirpath, "main", map[string]interface{}{
+		"helloMessage": "hi",
+	}, options)
+
+	docs.Then("validation succeeds, but still lists the missing parameter")
+	expectedResponse := &api.ParameterValidationResponse{
+		Success:           true,
+		InvalidParameters: []api.InvalidParameter{},
+		MissingParameters: []string{"serviceName"},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+
+	docs.When("ValidateParameters is invoked allowing incomplete parameters, with an invalid value")
+	actualResponse = generatorlib.ValidateParameters(context.TODO(), sourcedirpath, "main", map[string]interface{}{
+		"serviceName": "Not Valid",
+	}, options)
+
+	docs.Then("validation fails for the invalid value")
+	expectedResponse = &api.ParameterValidationResponse{
+		InvalidParameters: []api.InvalidParameter{
+			{Name: "serviceName", Error: errors.New("value for parameter 'serviceName' does not match pattern ^[a-z-]+$")},
+		},
+		MissingParameters: []string{},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestValidateParameters_ShouldFailOnMissingGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+
+	docs.When("ValidateParameters is invoked for a generator that does not exist")
+	actualResponse := generatorlib.ValidateParameters(context.TODO(), sourcedirpath, "notthere", map[string]interface{}{}, api.ParameterValidationOptions{})
+
+	docs.Then("an error is returned")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+}
+
+func TestValidateRenderSpec_ShouldAcceptValidRenderSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory with a valid render spec file")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/validate-render-spec-1"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\nparameters:\n  serviceName: temp-service\n")))
+
+	docs.When("ValidateRenderSpec is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.ValidateRenderSpec(context.TODO(), request)
+
+	docs.Then("validation succeeds and nothing is rendered")
+	expectedResponse := &api.ParameterValidationResponse{
+		Success:           true,
+		InvalidParameters: []api.InvalidParameter{},
+		MissingParameters: []string{},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+	files, err := ioutil.ReadDir(targetdirpath)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(files))
+}
+
+func TestValidateRenderSpec_ShouldReportAllProblems(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory with a render spec file with several problems")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/validate-render-spec-2"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\nparameters:\n  serviceUrl: 42\n  extra: value\n")))
+
+	docs.When("ValidateRenderSpec is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.ValidateRenderSpec(context.TODO(), request)
+
+	docs.Then("the extraneous and the missing parameter are reported")
+	expectedResponse := &api.ParameterValidationResponse{
+		InvalidParameters: []api.InvalidParameter{
+			{Name: "extra", Error: errors.New("parameter 'extra' is not allowed according to generator spec")},
+		},
+		MissingParameters: []string{"serviceName"},
+	}
+	require.Equal(t, expectedResponse, actualResponse)
+}
+
+func TestValidateRenderSpec_ShouldFailOnUnknownGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory and a target directory with a render spec file for an unknown generator")
+	sourcedirpath := "../resources/valid-generator-simple"
+	targetdirpath := "../output/validate-render-spec-3"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: notthere\n")))
+
+	docs.When("ValidateRenderSpec is invoked")
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	actualResponse := generatorlib.ValidateRenderSpec(context.TODO(), request)
+
+	docs.Then("an error is returned")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, 1, len(actualResponse.Errors))
+}
@@ -0,0 +1,153 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidateGeneratorSpec_ShouldAcceptValidSpec(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.Given("a valid generator name whose defaults match their patterns")
+	name := "templatevars"
+
+	docs.When("ValidateGeneratorSpec is invoked")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("no errors are reported")
+	require.Empty(t, actual)
+}
+
+func TestValidateGeneratorSpec_ShouldComplainDefaultNotMatchingPattern(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec has defaults that do not match their own patterns")
+	name := "defaultpattern"
+
+	docs.When("ValidateGeneratorSpec is invoked")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("an error is reported for each offending default, in variable name order")
+	require.Equal(t, 2, len(actual))
+	require.Equal(t, "variable declaration serviceName has default value 'Not A Valid Name' that does not match its own pattern ^[a-z-]+$ (this is an error in the generator spec)", actual[0].Error())
+	require.Equal(t, "variable declaration templatedName has default value 'SHOUTING' that does not match its own pattern ^[a-z]+$ (this is an error in the generator spec)", actual[1].Error())
+}
+
+func TestValidateGeneratorSpec_ShouldComplainInvalidTemplatedDefault(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec has a syntax error in a templated default")
+	name := "templatevars"
+
+	docs.When("ValidateGeneratorSpec is invoked")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("the broken default template is reported")
+	require.Equal(t, 1, len(actual))
+	require.Contains(t, actual[0].Error(), "variable declaration helloMessage has invalid default (this is an error in the generator spec): template: __defaultvalue_helloMessage:1:")
+}
+
+func TestValidateGeneratorSpec_ShouldComplainUndefinedFunctions(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec uses misspelled functions in a template and in a condition")
+	name := "undefinedfunction"
+
+	docs.When("ValidateGeneratorSpec is invoked")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("all undefined functions are reported at once")
+	require.Equal(t, 2, len(actual))
+	require.Equal(t, "failed to parse template inline template for target greeting.txt: template: inline template for target greeting.txt:1: function \"upperr\" not defined", actual[0].Error())
+	require.Equal(t, "failed to parse condition of template inline template for target {{ .serviceName | lower }}.txt: template: inline template for target {{ .serviceName | lower }}.txt_condition:1: function \"nott\" not defined", actual[1].Error())
+}
+
+func TestValidateGeneratorSpec_ShouldAcceptFunctionsAvailableDuringRendering(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.Given("generator names whose templates use sprig functions, render, and targetExists")
+	for _, name := range []string{"main", "compose", "targetexists"} {
+		docs.When("ValidateGeneratorSpec is invoked")
+		actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+		docs.Then("no errors are reported")
+		require.Empty(t, actual, name)
+	}
+}
+
+func TestValidateGeneratorSpec_ShouldComplainMissingSpec(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-simple"
+
+	docs.When("ValidateGeneratorSpec is invoked for a generator name for which no spec exists")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, "notthere")
+
+	docs.Then("the read error is reported")
+	require.Equal(t, 1, len(actual))
+	require.Contains(t, actual[0].Error(), "error reading generator spec file generator-notthere.yaml: ")
+}
+
+func TestValidateGeneratorSpecWithOptions_ShouldWarnAboutSameTarget(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec iterates over items without referring to the item in some target paths")
+	name := "sametarget"
+
+	docs.When("ValidateGeneratorSpecWithOptions is invoked without strict mode")
+	actual := generatorlib.ValidateGeneratorSpecWithOptions(context.TODO(), sourcedir, name, api.SpecValidationOptions{})
+
+	docs.Then("it is successful")
+	require.True(t, actual.Success)
+	require.Empty(t, actual.Errors)
+
+	docs.Then("a warning is given for each template whose iterations all write the same file")
+	require.Equal(t, []string{
+		"template item.txt.tmpl iterates over with_items, but its target path 'items.txt' does not refer to the item, so all iterations write the same file",
+		"template item.txt.tmpl iterates over with_entries, but its target path 'entries.txt' does not refer to the item, so all iterations write the same file",
+	}, actual.Warnings)
+
+	docs.Then("ValidateGeneratorSpec is unaffected")
+	require.Empty(t, generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name))
+}
+
+func TestValidateGeneratorSpecWithOptions_ShouldComplainAboutSameTargetInStrictMode(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec iterates over items without referring to the item in some target paths")
+	name := "sametarget"
+
+	docs.When("ValidateGeneratorSpecWithOptions is invoked in strict mode")
+	actual := generatorlib.ValidateGeneratorSpecWithOptions(context.TODO(), sourcedir, name, api.SpecValidationOptions{Strict: true})
+
+	docs.Then("it fails with an error for each offending template and no warnings")
+	require.False(t, actual.Success)
+	require.Empty(t, actual.Warnings)
+	require.Equal(t, 2, len(actual.Errors))
+	require.Equal(t, "template item.txt.tmpl iterates over with_items, but its target path 'items.txt' does not refer to the item, so all iterations write the same file", actual.Errors[0].Error())
+}
+
+func TestValidateGeneratorSpec_ShouldComplainItemsAndEntries(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/invalid-generator-specs"
+
+	docs.Given("a generator name whose spec has a template with both with_items and with_entries")
+	name := "itemsandentries"
+
+	docs.When("ValidateGeneratorSpec is invoked")
+	actual := generatorlib.ValidateGeneratorSpec(context.TODO(), sourcedir, name)
+
+	docs.Then("the template is reported")
+	require.Equal(t, 1, len(actual))
+	require.Equal(t, "template inline template for target both-{{ .item }}{{ .itemKey }}.txt must not specify both with_items and with_entries", actual[0].Error())
+}
@@ -1,12 +1,15 @@
 package acceptance
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	generatorlib "github.com/mundobaton/go-generator-lib"
 	"github.com/mundobaton/go-generator-lib/api"
 	"github.com/mundobaton/go-generator-lib/docs"
 	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
 	"os"
 	"path"
 	"testing"
@@ -31,11 +34,12 @@ func TestWriteRenderSpecWithDefaults_ShouldCreateMainSpec(t *testing.T) {
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-main.yaml"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello world
   serviceName: ""
-  serviceUrl: github.com/StephanHCB/temp
+  serviceUrl: github.com/mundobaton/temp
 `
 	expectedResponse := &api.Response{
 		Success: true,
@@ -80,7 +84,8 @@ parameters:
 	actualResponse := generatorlib.WriteRenderSpecWithDefaults(context.TODO(), request, name)
 
 	docs.Then("the render spec file is silently overwritten and the return value is as expected")
-	expectedContent := `generator: docker
+	expectedContent := `version: 1
+generator: docker
 parameters:
   serviceName: ""
 `
@@ -167,11 +172,12 @@ func TestWriteRenderSpecWithDefaults_ShouldCreateParsedDefaultsSpec(t *testing.T
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-templatevars.yaml"
-	expectedContent := `generator: templatevars
+	expectedContent := `version: 1
+generator: templatevars
 parameters:
   helloMessage: heya
   serviceName: ""
-  serviceUrl: github.com/StephanHCB/temp
+  serviceUrl: github.com/mundobaton/temp
 `
 	expectedResponse := &api.Response{
 		Success: true,
@@ -233,7 +239,8 @@ func TestWriteRenderSpecWithDefaults_ShouldCreateEmptyStringBothCases(t *testing
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-emptydefaults.yaml"
-	expectedContent := `generator: emptydefaults
+	expectedContent := `version: 1
+generator: emptydefaults
 parameters:
   emptyStringDefault: ""
   missingDefault: ""
@@ -253,3 +260,178 @@ parameters:
 	require.Equal(t, expectedContent, string(actual))
 	require.Equal(t, expectedResponse, actualResponse)
 }
+
+func TestWriteRenderSpecWithDefaults_ShouldCreateGroupedSpec(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/write-render-spec-8"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid generator name for a spec that assigns some variables to groups")
+	name := "groups"
+
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	docs.When("WriteRenderSpecWithDefaults is invoked")
+	actualResponse := generatorlib.WriteRenderSpecWithDefaults(context.TODO(), request, name)
+
+	docs.Then("the spec file is written with one commented section per group, ungrouped variables last")
+	expectedFilename := "generated-groups.yaml"
+	expectedContent := `version: 1
+generator: groups
+parameters:
+  # --- Database ---
+  databaseUrl: postgres://localhost/db
+  # --- Structures ---
+  structureList:
+  - one
+  - two
+  - three:
+    - sub 1
+    - sub 2
+  structureMap:
+    commonName: European wildcat
+    species: felis silvestris
+  # --- other parameters ---
+  helloMessage: hello world
+`
+	require.True(t, actualResponse.Success)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual, err := dir.ReadFile(context.TODO(), expectedFilename)
+	require.Nil(t, err)
+	require.Equal(t, expectedContent, string(actual))
+
+	docs.Then("the written spec can be used for rendering")
+	renderResponse := generatorlib.Render(context.TODO(), &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: expectedFilename,
+	})
+	require.True(t, renderResponse.Success)
+}
+
+func TestWriteRenderSpecWithDefaultsTo_ShouldWriteSpecToWriter(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/write-render-spec-9"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid generator name for a spec that assigns some variables to groups")
+	name := "groups"
+
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	docs.When("WriteRenderSpecWithDefaultsTo is invoked with a buffer")
+	var buf bytes.Buffer
+	actualResponse := generatorlib.WriteRenderSpecWithDefaultsTo(context.TODO(), request, name, &buf)
+
+	docs.Then("the spec is written to the buffer in the same format as a file, including group sections")
+	expectedContent := `version: 1
+generator: groups
+parameters:
+  # --- Database ---
+  databaseUrl: postgres://localhost/db
+  # --- Structures ---
+  structureList:
+  - one
+  - two
+  - three:
+    - sub 1
+    - sub 2
+  structureMap:
+    commonName: European wildcat
+    species: felis silvestris
+  # --- other parameters ---
+  helloMessage: hello world
+`
+	require.Equal(t, &api.Response{Success: true, RenderedFiles: []api.FileResult{}}, actualResponse)
+	require.Equal(t, expectedContent, buf.String())
+
+	docs.Then("no file is written")
+	files, err := ioutil.ReadDir(targetdirpath)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(files))
+}
+
+func TestWriteRenderSpecWithDefaults_ShouldWritePlaceholders(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/write-render-spec-10"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a valid generator name for a spec with required variables, one of them declaring its own placeholder")
+	name := "placeholder"
+
+	docs.When("WriteRenderSpecWithDefaults is invoked with MissingValuePlaceholder set")
+	request := &api.Request{
+		SourceBaseDir:           sourcedirpath,
+		TargetBaseDir:           targetdirpath,
+		RenderSpecFile:          "generated-placeholder.yaml",
+		MissingValuePlaceholder: "CHANGEME",
+	}
+	actualResponse := generatorlib.WriteRenderSpecWithDefaults(context.TODO(), request, name)
+
+	docs.Then("required variables are set to their own placeholder, or the one from the request")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := ioutil.ReadFile(path.Join(targetdirpath, "generated-placeholder.yaml"))
+	require.Nil(t, err)
+	expectedContent := `version: 1
+generator: placeholder
+parameters:
+  owner: PUT-YOUR-TEAM-HERE
+  region: eu
+  serviceName: CHANGEME
+`
+	require.Equal(t, expectedContent, string(actual))
+
+	docs.When("Render is invoked without editing the render spec")
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the variable left at its own placeholder is reported first, even though it has no pattern")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []error{errors.New("value for parameter 'owner' is still the placeholder 'PUT-YOUR-TEAM-HERE', please set it")}, actualResponse.Errors)
+
+	docs.When("Render is invoked with only owner set")
+	request.Overrides = map[string]interface{}{"owner": "platform-team"}
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the placeholder that does not match its pattern is reported")
+	require.False(t, actualResponse.Success)
+	require.Equal(t, []error{errors.New("value for parameter 'serviceName' does not match pattern ^[a-z-]+$")}, actualResponse.Errors)
+}
+
+func _testWriteRenderSpecWithDefaults_seededTestCase(t *testing.T, targetdirpath string) string {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.When("WriteRenderSpecWithDefaults is invoked with a seed for a generator whose defaults call random functions")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-seededdefaults.yaml",
+		Seed:           42,
+	}
+	actualResponse := generatorlib.WriteRenderSpecWithDefaults(context.TODO(), request, "seededdefaults")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := ioutil.ReadFile(path.Join(targetdirpath, "generated-seededdefaults.yaml"))
+	require.Nil(t, err)
+	return string(actual)
+}
+
+func TestWriteRenderSpecWithDefaults_ShouldRenderRandomDefaultsDeterministicallyWithSeed(t *testing.T) {
+	first := _testWriteRenderSpecWithDefaults_seededTestCase(t, "../output/write-render-spec-11")
+
+	docs.Then("the same seed produces identical render specs every time")
+	for n := 0; n < 5; n++ {
+		require.Equal(t, first, _testWriteRenderSpecWithDefaults_seededTestCase(t, "../output/write-render-spec-12"))
+	}
+}
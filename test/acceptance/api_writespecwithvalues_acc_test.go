@@ -1,6 +1,7 @@
 package acceptance
 
 import (
+	"bytes"
 	"context"
 	generatorlib "github.com/mundobaton/go-generator-lib"
 	"github.com/mundobaton/go-generator-lib/api"
@@ -39,7 +40,8 @@ func TestWriteRenderSpecWithValues_ShouldCreateMainSpec(t *testing.T) {
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-main.yaml"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello nice world
   serviceName: something-valid
@@ -86,7 +88,8 @@ func TestWriteRenderSpecWithValues_ShouldCreateNonstandardSpec(t *testing.T) {
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generator-values.dat"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello nice world
   serviceName: something-valid
@@ -132,7 +135,8 @@ func TestWriteRenderSpecWithValues_ShouldCreateDefaultSpec(t *testing.T) {
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-main.yaml"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello nice world
   serviceName: something-valid
@@ -185,7 +189,8 @@ parameters:
 	actualResponse := generatorlib.WriteRenderSpecWithValues(context.TODO(), request, name, parameters)
 
 	docs.Then("the render spec file is silently overwritten and the return value is as expected")
-	expectedContent := `generator: docker
+	expectedContent := `version: 1
+generator: docker
 parameters:
   serviceName: docker-is-great
 `
@@ -225,7 +230,8 @@ func TestWriteRenderSpecWithValues_ShouldCreateMainSpec_StructuredDefaults(t *te
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-main.yaml"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello world
   structureList:
@@ -279,7 +285,8 @@ func TestWriteRenderSpecWithValues_ShouldCreateMainSpec_StructuredValues(t *test
 
 	docs.Then("the correct spec file is written and the return value is as expected")
 	expectedFilename := "generated-main.yaml"
-	expectedContent := `generator: main
+	expectedContent := `version: 1
+generator: main
 parameters:
   helloMessage: hello world
   structureList:
@@ -307,6 +314,36 @@ parameters:
 	require.Equal(t, expectedResponse, actualResponse)
 }
 
+func TestWriteRenderSpecWithValuesTo_ShouldWriteSpecToWriter(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+
+	docs.Given("a valid generator name")
+	name := "main"
+
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: "../output/write-render-spec-values-13",
+	}
+	docs.When("WriteRenderSpecWithValuesTo is invoked with valid parameters and a buffer")
+	parameters := map[string]interface{}{
+		"serviceName": "something-valid",
+	}
+	var buf bytes.Buffer
+	actualResponse := generatorlib.WriteRenderSpecWithValuesTo(context.TODO(), request, name, parameters, &buf)
+
+	docs.Then("the spec is written to the buffer, with defaults filled in")
+	expectedContent := `version: 1
+generator: main
+parameters:
+  helloMessage: hello world
+  serviceName: something-valid
+  serviceUrl: github.com/mundobaton/temp
+`
+	require.Equal(t, &api.Response{Success: true, RenderedFiles: []api.FileResult{}}, actualResponse)
+	require.Equal(t, expectedContent, buf.String())
+}
+
 // --- error cases
 
 func TestWriteRenderSpecWithValues_ShouldComplainMissingSpec(t *testing.T) {
@@ -412,3 +449,89 @@ func TestWriteRenderSpecWithValues_ShouldComplainUnknownParameter(t *testing.T)
 	require.False(t, actualResponse.Success)
 	require.Equal(t, expectedErrorMessage, actualResponse.Errors[0].Error())
 }
+
+func TestWriteRenderSpecWithValues_ShouldRenameAliases(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/write-render-spec-values-12"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a generator name whose spec declares an alias for a renamed variable")
+	name := "aliases"
+
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	docs.When("WriteRenderSpecWithValues is invoked with the value given under the alias")
+	parameters := map[string]interface{}{
+		"svcName": "old-service",
+	}
+	actualResponse := generatorlib.WriteRenderSpecWithValues(context.TODO(), request, name, parameters)
+
+	docs.Then("the spec file is written using the actual variable name, with a deprecation warning")
+	expectedContent := `version: 1
+generator: aliases
+parameters:
+  serviceName: old-service
+`
+	require.True(t, actualResponse.Success)
+	require.Equal(t, []string{"parameter 'svcName' is a deprecated alias for 'serviceName', please rename it"}, actualResponse.Warnings)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual, err := dir.ReadFile(context.TODO(), "generated-aliases.yaml")
+	require.Nil(t, err)
+	require.Equal(t, expectedContent, string(actual))
+}
+
+func TestWriteRenderSpecWithValues_ShouldOmitDefaultsIfRequested(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+	targetdirpath := "../output/write-render-spec-values-14"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a generator with literal, template and conditional defaults")
+	name := "resolvedefaults"
+
+	docs.When("WriteRenderSpecWithValues is invoked with OmitDefaults and some values that equal their default")
+	request := &api.Request{
+		SourceBaseDir:  sourcedirpath,
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-resolvedefaults.yaml",
+		OmitDefaults:   true,
+	}
+	parameters := map[string]interface{}{
+		"serviceName": "my-service",
+		"owner":       "team-a",
+		"namespace":   "resolvedefaults-apps",
+		"replicas":    3,
+		"enableTls":   false,
+		"port":        80,
+	}
+	actualResponse := generatorlib.WriteRenderSpecWithValues(context.TODO(), request, name, parameters)
+
+	docs.Then("only the values that differ from their defaults are written")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	actual, err := dir.ReadFile(context.TODO(), "generated-resolvedefaults.yaml")
+	require.Nil(t, err)
+	expectedContent := `version: 1
+generator: resolvedefaults
+parameters:
+  enableTls: false
+  owner: team-a
+  replicas: 3
+  serviceName: my-service
+`
+	require.Equal(t, expectedContent, toUnix(string(actual)))
+
+	docs.When("Render is invoked with the written render spec")
+	renderResponse := generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the omitted values are resolved from their defaults again")
+	require.True(t, renderResponse.Success, renderResponse.Errors)
+	rendered, err := dir.ReadFile(context.TODO(), "resolvedefaults.txt")
+	require.Nil(t, err)
+	require.Equal(t, "my-service in resolvedefaults-apps on 80", string(rendered))
+}
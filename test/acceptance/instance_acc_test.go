@@ -0,0 +1,120 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/stretchr/testify/require"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func _testNew_extraFuncsTestCase(t *testing.T, targetdirpath string) (*api.Request, *targetdir.TargetDirectory) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("a render spec file for a generator that uses a custom template function")
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-extrafuncs.yaml", []byte("generator: extrafuncs\n")))
+	return &api.Request{
+		SourceBaseDir:  "../resources/valid-generator-structured",
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-extrafuncs.yaml",
+	}, dir
+}
+
+func TestNew_ShouldApplyInstanceOptionsToEveryCall(t *testing.T) {
+	request, dir := _testNew_extraFuncsTestCase(t, "../output/render-91")
+
+	docs.Given("an instance configured with the custom template function")
+	generator := generatorlib.New(generatorlib.ExtraFuncs(template.FuncMap{
+		"shout": func(s string) string { return strings.ToUpper(s) + "!" },
+	}), generatorlib.WithoutLogging())
+
+	docs.When("Render is invoked on the instance")
+	actualResponse := generator.Render(context.TODO(), request)
+
+	docs.Then("the custom function is available without putting it into the context")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "SOME-SERVICE!.txt")
+	require.Nil(t, err)
+	require.Equal(t, "SOME-SERVICE! says HELLO\n", toUnix(string(actual)))
+
+	docs.When("Render is invoked through the package level functions")
+	actualResponse = generatorlib.Render(context.TODO(), request)
+
+	docs.Then("the default instance is not affected by the configuration")
+	require.False(t, actualResponse.Success)
+}
+
+func TestNew_ShouldPreferFunctionsFromContext(t *testing.T) {
+	request, dir := _testNew_extraFuncsTestCase(t, "../output/render-92")
+
+	docs.Given("an instance configured with the custom template function")
+	generator := generatorlib.New(generatorlib.ExtraFuncs(template.FuncMap{
+		"shout": func(s string) string { return strings.ToUpper(s) + "!" },
+	}))
+
+	docs.When("Render is invoked on the instance with a context that carries a function of the same name")
+	ctx := generatorlib.WithExtraFuncs(context.TODO(), template.FuncMap{
+		"shout": func(s string) string { return s + "?" },
+	})
+	actualResponse := generator.Render(ctx, request)
+
+	docs.Then("the function from the context is used")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := dir.ReadFile(context.TODO(), "some-service?.txt")
+	require.Nil(t, err)
+	require.Equal(t, "some-service? says HELLO\n", toUnix(string(actual)))
+}
+
+func TestNew_ShouldApplyDiscoveryOptionsToRender(t *testing.T) {
+	docs.Given("a valid generator source directory and a valid target directory")
+	targetdirpath := "../output/render-100"
+	require.Nil(t, os.RemoveAll(targetdirpath))
+	require.Nil(t, os.Mkdir(targetdirpath, 0755))
+
+	docs.Given("an instance configured for a generator spec with a different naming convention")
+	generator := generatorlib.New(generatorlib.Discovery(api.DiscoveryOptions{Prefix: "blueprint.", Extension: ".yml"}), generatorlib.WithoutLogging())
+	request := &api.Request{
+		SourceBaseDir:  "../resources/valid-generator-structured",
+		TargetBaseDir:  targetdirpath,
+		RenderSpecFile: "generated-custom.yaml",
+	}
+
+	docs.When("WriteRenderSpecWithDefaults and Render are invoked on the instance")
+	actualResponse := generator.WriteRenderSpecWithDefaults(context.TODO(), request, "custom")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actualResponse = generator.Render(context.TODO(), request)
+
+	docs.Then("the generator spec is found by both")
+	require.True(t, actualResponse.Success, actualResponse.Errors)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "custom.txt")
+	require.Nil(t, err)
+	require.Equal(t, "hello custom world", string(actual))
+
+	docs.When("ExportParameterSchema is invoked with the discovery options in the context instead")
+	ctx := generatorlib.WithDiscoveryOptions(context.TODO(), api.DiscoveryOptions{Prefix: "blueprint.", Extension: ".yml"})
+	schema, err := generatorlib.ExportParameterSchema(ctx, request.SourceBaseDir, "custom")
+
+	docs.Then("the generator spec is found as well")
+	require.Nil(t, err)
+	require.Contains(t, string(schema), "helloMessage")
+}
+
+func TestFindGeneratorNamesWithOptions_ShouldAllowNoPrefix(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedir := "../resources/valid-generator-structured"
+
+	docs.When("FindGeneratorNamesWithOptions is invoked without a prefix")
+	actual, err := generatorlib.FindGeneratorNamesWithOptions(context.TODO(), sourcedir, api.DiscoveryOptions{NoPrefix: true, Extension: ".yml"})
+
+	docs.Then("the whole file name without the extension is the generator name")
+	require.Nil(t, err)
+	require.Equal(t, []string{"blueprint.custom"}, actual)
+}
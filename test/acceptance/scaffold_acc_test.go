@@ -0,0 +1,82 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/mundobaton/go-generator-lib/scaffold"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestNewGeneratorFrom_ShouldWriteUsableSkeleton(t *testing.T) {
+	docs.Given("a valid generator source directory and an empty directory for the new generator")
+	sourcedirpath := "../resources/valid-generator-structured"
+	newsourcedirpath := "../output/scaffold-1-source"
+	targetdirpath := "../output/scaffold-1"
+	for _, d := range []string{newsourcedirpath, targetdirpath} {
+		require.Nil(t, os.RemoveAll(d))
+		require.Nil(t, os.Mkdir(d, 0755))
+	}
+
+	docs.When("NewGeneratorFrom is invoked")
+	err := scaffold.NewGeneratorFrom(context.TODO(), sourcedirpath, "labels", newsourcedirpath, "service")
+
+	docs.Then("a generator spec with the same variables and a sample template are written")
+	require.Nil(t, err)
+	newDir := targetdir.Instance(context.TODO(), newsourcedirpath)
+	actualSpec, err := newDir.ReadFile(context.TODO(), "generator-service.yaml")
+	require.Nil(t, err)
+	expectedSpec := `templates:
+- source: service/sample.txt.tmpl
+  target: sample.txt
+variables:
+  helloMessage:
+    description: A message to be inserted in the code.
+    default: hello world
+  serviceName:
+    label: Service name
+    description: The name of the service to be rendered, lowercase letters and dashes
+      only.
+    pattern: ^[a-z-]+$
+`
+	require.Equal(t, expectedSpec, toUnix(string(actualSpec)))
+
+	docs.Then("the new generator can be rendered")
+	request := &api.Request{
+		SourceBaseDir: newsourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	response := generatorlib.RenderWithValues(context.TODO(), request, "service", map[string]interface{}{"serviceName": "my-service"})
+	require.True(t, response.Success)
+	actual, err := targetdir.Instance(context.TODO(), targetdirpath).ReadFile(context.TODO(), "sample.txt")
+	require.Nil(t, err)
+	require.Equal(t, "helloMessage: hello world\nserviceName: my-service\n", toUnix(string(actual)))
+}
+
+func TestNewGeneratorFrom_ShouldNotOverwriteExistingGenerator(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+
+	docs.When("NewGeneratorFrom is invoked with the name of a generator that already exists in the same directory")
+	err := scaffold.NewGeneratorFrom(context.TODO(), sourcedirpath, "labels", sourcedirpath, "main")
+
+	docs.Then("an appropriate error is returned")
+	require.NotNil(t, err)
+	require.Equal(t, "generator spec file generator-main.yaml already exists, will not overwrite it", err.Error())
+}
+
+func TestNewGeneratorFrom_ShouldComplainInvalidName(t *testing.T) {
+	docs.Given("a valid generator source directory")
+	sourcedirpath := "../resources/valid-generator-structured"
+
+	docs.When("NewGeneratorFrom is invoked with a name containing a slash")
+	err := scaffold.NewGeneratorFrom(context.TODO(), sourcedirpath, "labels", "../output", "sub/dir")
+
+	docs.Then("an appropriate error is returned")
+	require.NotNil(t, err)
+	require.Equal(t, "invalid generator name 'sub/dir', must be nonempty and must not contain slashes", err.Error())
+}
@@ -0,0 +1,50 @@
+package acceptance
+
+import (
+	"context"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/testsupport"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAssertMatchesGolden_ShouldPassForMatchingTree(t *testing.T) {
+	docs.Given("a valid generator and a golden directory matching its output")
+	sourcedirpath := "../resources/valid-generator-simple"
+	goldendirpath := "../resources/golden/items"
+
+	docs.When("AssertMatchesGolden is invoked")
+	docs.Then("the test passes")
+	testsupport.AssertMatchesGolden(t, sourcedirpath, "items", map[string]interface{}{}, goldendirpath)
+}
+
+func TestCompareWithGolden_ShouldReportDifferences(t *testing.T) {
+	docs.Given("a valid generator and an outdated golden directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	goldendirpath := "../resources/golden/items-outdated"
+
+	docs.When("CompareWithGolden is invoked")
+	actual, err := testsupport.CompareWithGolden(context.TODO(), sourcedirpath, "items", map[string]interface{}{}, goldendirpath)
+
+	docs.Then("each missing, unexpected and differing file is reported in file name order")
+	require.Nil(t, err)
+	expected := []string{
+		"fourth.txt: missing, expected by golden directory but not rendered",
+		"second.txt: differs in line 1: expected 'Hello John!' but got 'Hi John!'",
+		"third.txt: unexpected, rendered but not in golden directory",
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestCompareWithGolden_ShouldComplainInvalidParameters(t *testing.T) {
+	docs.Given("a valid generator and a golden directory")
+	sourcedirpath := "../resources/valid-generator-simple"
+	goldendirpath := "../resources/golden/items"
+
+	docs.When("CompareWithGolden is invoked with a parameter the generator does not know")
+	_, err := testsupport.CompareWithGolden(context.TODO(), sourcedirpath, "items", map[string]interface{}{"unknown": "x"}, goldendirpath)
+
+	docs.Then("an appropriate error is returned")
+	require.NotNil(t, err)
+	require.Equal(t, "parameter 'unknown' is not allowed according to generator spec", err.Error())
+}
@@ -0,0 +1,138 @@
+package acceptance
+
+import (
+	"context"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/docs"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/mundobaton/go-generator-lib/watch"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch_ShouldRenderAgainOnTemplateChange(t *testing.T) {
+	docs.Given("a generator source directory that can be modified and a valid target directory")
+	sourcedirpath := "../output/watch-1-source"
+	targetdirpath := "../output/watch-1"
+	for _, d := range []string{sourcedirpath, targetdirpath} {
+		require.Nil(t, os.RemoveAll(d))
+		require.Nil(t, os.Mkdir(d, 0755))
+	}
+	genspec := `templates:
+  - source: 'hello.txt.tmpl'
+    target: 'hello.txt'
+variables:
+  name:
+    default: 'world'
+`
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "generator-main.yaml"), []byte(genspec), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "hello.txt.tmpl"), []byte("hello {{ .name }}\n"), 0644))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\n")))
+
+	docs.When("Watch is invoked")
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	responses := watch.WatchWithOptions(ctx, generatorlib.Instance, request, watch.Options{
+		Debounce: 20 * time.Millisecond,
+	})
+
+	docs.Then("the target is rendered right away")
+	response := receiveWatchResponse(t, responses)
+	require.True(t, response.Success)
+	actual, err := dir.ReadFile(context.TODO(), "hello.txt")
+	require.Nil(t, err)
+	require.Equal(t, "hello world\n", toUnix(string(actual)))
+
+	docs.When("the template is changed")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "hello.txt.tmpl"), []byte("goodbye {{ .name }}\n"), 0644))
+
+	docs.Then("the target is rendered again")
+	response = receiveWatchResponse(t, responses)
+	require.True(t, response.Success)
+	actual, err = dir.ReadFile(context.TODO(), "hello.txt")
+	require.Nil(t, err)
+	require.Equal(t, "goodbye world\n", toUnix(string(actual)))
+
+	docs.When("the context is cancelled")
+	cancel()
+
+	docs.Then("the channel is closed")
+	select {
+	case _, ok := <-responses:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel was not closed after context was cancelled")
+	}
+}
+
+func TestWatch_ShouldIgnoreChangesInTargetDirInsideSourceDir(t *testing.T) {
+	docs.Given("a generator source directory that contains the target directory")
+	sourcedirpath := "../output/watch-2-source"
+	targetdirpath := "../output/watch-2-source/target"
+	require.Nil(t, os.RemoveAll(sourcedirpath))
+	require.Nil(t, os.MkdirAll(targetdirpath, 0755))
+	genspec := `templates:
+  - source: 'hello.txt.tmpl'
+    target: 'hello.txt'
+variables:
+  name:
+    default: 'world'
+`
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "generator-main.yaml"), []byte(genspec), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "hello.txt.tmpl"), []byte("hello {{ .name }}\n"), 0644))
+	dir := targetdir.Instance(context.TODO(), targetdirpath)
+	require.Nil(t, dir.WriteFile(context.TODO(), "generated-main.yaml", []byte("generator: main\n")))
+
+	docs.When("Watch is invoked")
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	request := &api.Request{
+		SourceBaseDir: sourcedirpath,
+		TargetBaseDir: targetdirpath,
+	}
+	responses := watch.WatchWithOptions(ctx, generatorlib.Instance, request, watch.Options{
+		Debounce: 20 * time.Millisecond,
+	})
+
+	docs.Then("the target is rendered right away")
+	response := receiveWatchResponse(t, responses)
+	require.True(t, response.Success)
+
+	docs.Then("writing the rendered file does not cause another render run")
+	select {
+	case response := <-responses:
+		t.Fatalf("unexpected render run: %v", response)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	docs.When("the template is changed")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(sourcedirpath, "hello.txt.tmpl"), []byte("goodbye {{ .name }}\n"), 0644))
+
+	docs.Then("the target is rendered again")
+	response = receiveWatchResponse(t, responses)
+	require.True(t, response.Success)
+	actual, err := dir.ReadFile(context.TODO(), "hello.txt")
+	require.Nil(t, err)
+	require.Equal(t, "goodbye world\n", toUnix(string(actual)))
+}
+
+func receiveWatchResponse(t *testing.T, responses <-chan *api.Response) *api.Response {
+	select {
+	case response, ok := <-responses:
+		require.True(t, ok)
+		return response
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response received in time")
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	generatorlib "github.com/mundobaton/go-generator-lib"
+	"github.com/mundobaton/go-generator-lib/api"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// helpers for generator authors who want to check that their generator produces an expected tree of files
+
+// AssertMatchesGolden renders a generator with the given parameters into a temporary directory and
+// compares the result against goldenDir, reporting each difference as a test error.
+func AssertMatchesGolden(t testing.TB, sourceBaseDir string, generatorName string, parameters map[string]interface{}, goldenDir string) {
+	t.Helper()
+	differences, err := CompareWithGolden(context.TODO(), sourceBaseDir, generatorName, parameters, goldenDir)
+	if err != nil {
+		t.Fatalf("failed to compare generator %s with golden directory %s: %s", generatorName, goldenDir, err.Error())
+	}
+	for _, d := range differences {
+		t.Error(d)
+	}
+}
+
+// CompareWithGolden renders a generator with the given parameters into a temporary directory and
+// compares the result against goldenDir.
+//
+// Returns a human readable description of each difference found (missing, unexpected, or differing files),
+// in sorted file name order, or an empty list if the rendered tree matches. The render spec file
+// written during the process is not compared. Line endings are normalized before comparing.
+func CompareWithGolden(ctx context.Context, sourceBaseDir string, generatorName string, parameters map[string]interface{}, goldenDir string) ([]string, error) {
+	targetDir, err := ioutil.TempDir("", "generator-golden-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary target directory: %s", err.Error())
+	}
+	defer os.RemoveAll(targetDir)
+
+	renderSpecFile := "generated-" + generatorName + ".yaml"
+	request := &api.Request{
+		SourceBaseDir:  sourceBaseDir,
+		TargetBaseDir:  targetDir,
+		RenderSpecFile: renderSpecFile,
+	}
+
+	response := generatorlib.WriteRenderSpecWithValues(ctx, request, generatorName, parameters)
+	if !response.Success {
+		return nil, firstError(response)
+	}
+
+	response = generatorlib.Render(ctx, request)
+	if !response.Success {
+		for _, f := range response.RenderedFiles {
+			if len(f.Errors) > 0 {
+				return nil, fmt.Errorf("error rendering %s: %s", f.RelativeFilePath, f.Errors[0].Error())
+			}
+		}
+		return nil, firstError(response)
+	}
+
+	actualFiles, err := readTree(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	delete(actualFiles, renderSpecFile)
+
+	expectedFiles, err := readTree(goldenDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareTrees(expectedFiles, actualFiles), nil
+}
+
+func firstError(response *api.Response) error {
+	if len(response.Errors) > 0 {
+		return response.Errors[0]
+	}
+	return fmt.Errorf("unknown error")
+}
+
+func readTree(baseDir string) (map[string]string, error) {
+	result := map[string]string{}
+	err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relativePath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		result[filepath.ToSlash(relativePath)] = strings.ReplaceAll(string(contents), "\r", "")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory tree %s: %s", baseDir, err.Error())
+	}
+	return result, nil
+}
+
+func compareTrees(expectedFiles map[string]string, actualFiles map[string]string) []string {
+	allNames := map[string]bool{}
+	for k := range expectedFiles {
+		allNames[k] = true
+	}
+	for k := range actualFiles {
+		allNames[k] = true
+	}
+	names := make([]string, 0, len(allNames))
+	for k := range allNames {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	differences := []string{}
+	for _, name := range names {
+		expected, inGolden := expectedFiles[name]
+		actual, rendered := actualFiles[name]
+		if !rendered {
+			differences = append(differences, fmt.Sprintf("%s: missing, expected by golden directory but not rendered", name))
+		} else if !inGolden {
+			differences = append(differences, fmt.Sprintf("%s: unexpected, rendered but not in golden directory", name))
+		} else if expected != actual {
+			differences = append(differences, fmt.Sprintf("%s: %s", name, describeFirstDifference(expected, actual)))
+		}
+	}
+	return differences
+}
+
+func describeFirstDifference(expected string, actual string) string {
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+	for n := 0; n < len(expectedLines) || n < len(actualLines); n++ {
+		expectedLine := "<end of file>"
+		if n < len(expectedLines) {
+			expectedLine = expectedLines[n]
+		}
+		actualLine := "<end of file>"
+		if n < len(actualLines) {
+			actualLine = actualLines[n]
+		}
+		if expectedLine != actualLine {
+			return fmt.Sprintf("differs in line %d: expected '%s' but got '%s'", n+1, expectedLine, actualLine)
+		}
+	}
+	// unreachable as long as the contents differ
+	return "differs"
+}
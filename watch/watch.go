@@ -0,0 +1,174 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mundobaton/go-generator-lib/api"
+	"github.com/mundobaton/go-generator-lib/internal/repository/targetdir"
+	"github.com/mundobaton/go-generator-lib/internal/repository/workingdir"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// live reload for generator development, kept in its own package so only users who need it depend on fsnotify
+
+// Options control how changes are handled.
+type Options struct {
+	// after a change is detected, wait until no further changes happen for this long before rendering,
+	// so saving several files in quick succession only results in one render run
+	Debounce time.Duration
+}
+
+// DefaultOptions are used by Watch.
+var DefaultOptions = Options{
+	Debounce: 200 * time.Millisecond,
+}
+
+// Watch renders the request once using generator, and then again whenever a file in the generator source directory
+// or the render spec file changes, sending each Response on the returned channel. Pass generatorlib.Instance
+// unless you have created your own instance.
+//
+// Changes below the target directory are ignored, even if it is inside the generator source directory,
+// so rendering does not trigger itself.
+//
+// Watching stops and the channel is closed when the context is cancelled. If the files cannot be watched,
+// a Response with the error is sent and the channel is closed.
+//
+// Changes are reported by the file system notifications of the operating system, see fsnotify.
+func Watch(ctx context.Context, generator api.Api, request *api.Request) <-chan *api.Response {
+	return WatchWithOptions(ctx, generator, request, DefaultOptions)
+}
+
+// WatchWithOptions is like Watch, but with explicit Options.
+func WatchWithOptions(ctx context.Context, generator api.Api, request *api.Request, options Options) <-chan *api.Response {
+	responses := make(chan *api.Response)
+	go func() {
+		defer close(responses)
+
+		sourceBaseDir := workingdir.Join(request.WorkingDir, request.SourceBaseDir)
+		targetBaseDir := workingdir.Join(request.WorkingDir, request.TargetBaseDir)
+		renderSpecPath := filepath.Join(targetBaseDir, targetdir.Instance(ctx, targetBaseDir).RenderSpecFilenameOrDefault(ctx, request.RenderSpecFile))
+
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			send(ctx, responses, errorResponse(err))
+			return
+		}
+		defer watcher.Close()
+		if err := addRecursive(watcher, sourceBaseDir, targetBaseDir); err != nil {
+			send(ctx, responses, errorResponse(err))
+			return
+		}
+		// editors often replace a file instead of writing it, which ends a watch on the file itself
+		if err := watcher.Add(filepath.Dir(renderSpecPath)); err != nil {
+			send(ctx, responses, errorResponse(err))
+			return
+		}
+
+		if !send(ctx, responses, generator.Render(ctx, request)) {
+			return
+		}
+
+		debounce := time.NewTimer(options.Debounce)
+		stopTimer(debounce)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !isRelevant(event, sourceBaseDir, targetBaseDir, renderSpecPath) {
+					continue
+				}
+				if event.Op&fsnotify.Create != 0 {
+					// new subdirectories are not watched automatically
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						_ = addRecursive(watcher, event.Name, targetBaseDir)
+					}
+				}
+				stopTimer(debounce)
+				debounce.Reset(options.Debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if !send(ctx, responses, errorResponse(err)) {
+					return
+				}
+			case <-debounce.C:
+				if !send(ctx, responses, generator.Render(ctx, request)) {
+					return
+				}
+			}
+		}
+	}()
+	return responses
+}
+
+// addRecursive watches dir and all directories below it, because fsnotify only reports changes
+// to the direct contents of a watched directory. The target directory is left out.
+func addRecursive(watcher *fsnotify.Watcher, dir string, targetBaseDir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if isBelow(path, targetBaseDir) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// isRelevant filters out events for rendered files, both in the directory of the render spec file
+// and in a target directory inside the generator source directory
+func isRelevant(event fsnotify.Event, sourceBaseDir string, targetBaseDir string, renderSpecPath string) bool {
+	if filepath.Clean(event.Name) == filepath.Clean(renderSpecPath) {
+		return true
+	}
+	return isBelow(event.Name, sourceBaseDir) && !isBelow(event.Name, targetBaseDir)
+}
+
+// isBelow is true if path is dir or inside it
+func isBelow(path string, dir string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	relative, err := filepath.Rel(absDir, absPath)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// stopTimer stops t and drains its channel, so it can be reset
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
+
+func errorResponse(err error) *api.Response {
+	return &api.Response{Errors: []error{fmt.Errorf("error watching files: %s", err)}}
+}
+
+func send(ctx context.Context, responses chan<- *api.Response, response *api.Response) bool {
+	select {
+	case responses <- response:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}